@@ -0,0 +1,106 @@
+package nanovgo
+
+// ClipPath is the clip region made by Context.Clip(). The region is the intersection of the filled paths of
+// the clip path and all its parents. It is passed to the renderer as a part of Scissor and is immutable.
+//
+// The paths are in the window coordinates. Raster renderers use Paths() (flattened triangle fans
+// without anti-aliasing fringes) and vector renderers use Commands().
+type ClipPath struct {
+	parent   *ClipPath
+	paths    []RenderPath
+	bounds   [4]float32
+	commands []PathCommand
+	fillRule FillRule
+}
+
+// Parent returns the clip path that this clip path is intersected with. It returns nil for the outermost clip path.
+func (p *ClipPath) Parent() *ClipPath {
+	return p.parent
+}
+
+// Paths returns the triangle fans of the clip path. They should be filled with FillRule() like RenderFillRule().
+func (p *ClipPath) Paths() []RenderPath {
+	return p.paths
+}
+
+// Bounds returns the bounding box of the clip path (minX, minY, maxX, maxY).
+func (p *ClipPath) Bounds() [4]float32 {
+	return p.bounds
+}
+
+// Commands returns the clip path as path commands for VectorRenderer.
+func (p *ClipPath) Commands() []PathCommand {
+	return p.commands
+}
+
+// FillRule returns the fill rule of the clip path.
+func (p *ClipPath) FillRule() FillRule {
+	return p.fillRule
+}
+
+// Clip intersects the current clip region with the current path filled by the current fill rule.
+// Drawing outside of the clip region is discarded. The clip region is saved and restored by Save() and Restore()
+// like the scissor rectangle, and it is applied in addition to the scissor rectangle. The edges of the clip
+// region are not anti-aliased on raster renderers.
+func (c *Context) Clip() {
+	state := c.getState()
+	clip := &ClipPath{
+		parent:   state.scissor.clip,
+		commands: c.vectorPath(),
+		fillRule: state.fillRule,
+	}
+	c.flattenPaths()
+	c.cache.expandFill(0.0, Miter, 2.4, c.fringeWidth)
+	clip.paths = make([]RenderPath, len(c.cache.paths))
+	for i := range c.cache.paths {
+		path := c.cache.paths[i]
+		path.fills = append([]Vertex(nil), path.fills...)
+		path.strokes = nil
+		clip.paths[i] = path
+	}
+	if len(clip.paths) > 0 {
+		clip.bounds = c.cache.bounds
+	}
+	state.scissor.clip = clip
+}
+
+// transform returns the copy of the clip path and its parents transformed by t.
+func (p *ClipPath) transform(t TransformMatrix) *ClipPath {
+	if p == nil {
+		return nil
+	}
+	clip := &ClipPath{
+		parent:   p.parent.transform(t),
+		paths:    make([]RenderPath, len(p.paths)),
+		bounds:   [4]float32{1e6, 1e6, -1e6, -1e6},
+		commands: make([]PathCommand, len(p.commands)),
+		fillRule: p.fillRule,
+	}
+	for i := range p.paths {
+		path := p.paths[i]
+		path.fills = make([]Vertex, len(p.paths[i].fills))
+		for j, vertex := range p.paths[i].fills {
+			x, y := t.TransformPoint(vertex.x, vertex.y)
+			path.fills[j].set(x, y, vertex.u, vertex.v)
+			clip.bounds = [4]float32{minF(clip.bounds[0], x), minF(clip.bounds[1], y), maxF(clip.bounds[2], x), maxF(clip.bounds[3], y)}
+		}
+		clip.paths[i] = path
+	}
+	if clip.bounds[0] > clip.bounds[2] {
+		clip.bounds = [4]float32{}
+	}
+	for i, command := range p.commands {
+		points := 0
+		switch command.Type {
+		case PathMoveTo, PathLineTo:
+			points = 1
+		case PathBezierTo:
+			points = 3
+		}
+		for j := 0; j < points*2; j += 2 {
+			command.Points[j], command.Points[j+1] = t.TransformPoint(command.Points[j], command.Points[j+1])
+		}
+		clip.commands[i] = command
+	}
+	return clip
+}
@@ -10,6 +10,10 @@ const (
 	StencilStrokes CreateFlags = 1 << 1
 	// Debug shows OpenGL errors to console
 	Debug CreateFlags = 1 << 2
+	// GL3 makes GL backend use OpenGL 3.2 core profile with vertex array object and uniform buffer.
+	// It needs the build tag gl3 to call OpenGL 3.x API via github.com/go-gl/gl/v3.2-core/gl.
+	// Without the tag, creating the context with GL3 fails.
+	GL3 CreateFlags = 1 << 3
 )
 
 const (
@@ -19,6 +23,16 @@ const (
 	PI float32 = 3.14159265358979323846264338327
 )
 
+// TextureType is pixel format of the texture passed to Renderer.RenderCreateTexture()
+type TextureType int
+
+const (
+	// TextureAlpha is one byte per pixel texture used for font atlas
+	TextureAlpha TextureType = 1
+	// TextureRGBA is four bytes per pixel texture (non-premultiplied RGBA)
+	TextureRGBA TextureType = 2
+)
+
 // Direction is used with Context.Arc
 type Direction int
 
@@ -90,3 +104,40 @@ const (
 	// Hole keeps internal hole
 	Hole Winding = 2
 )
+
+// FillRule is used for deciding which area of the path is inside, see Context.SetFillRule().
+type FillRule int
+
+const (
+	// NonZero (default) fills the area where the sum of the sub-path directions around it is not zero.
+	// The directions are decided by the sub-path windings, see Context.PathWinding().
+	NonZero FillRule = iota
+	// EvenOdd fills the area surrounded by odd number of sub-paths. Sub-path directions are ignored.
+	EvenOdd
+)
+
+// StrokeAlign is the position of the stroke against closed paths, see Context.SetStrokeAlign().
+type StrokeAlign int
+
+const (
+	// Center (default) centers the stroke on the path.
+	Center StrokeAlign = iota
+	// Inside draws the stroke inside of closed paths.
+	Inside
+	// Outside draws the stroke outside of closed paths.
+	Outside
+)
+
+// PathOp is a boolean operation between two paths, see Path.Combine().
+type PathOp int
+
+const (
+	// PathUnion fills the area inside of either path.
+	PathUnion PathOp = iota
+	// PathIntersect fills the area inside of both paths.
+	PathIntersect
+	// PathDifference fills the area inside of the first path and outside of the second path.
+	PathDifference
+	// PathXor fills the area inside of only one of the paths.
+	PathXor
+)
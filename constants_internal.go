@@ -10,6 +10,7 @@ const (
 	nvgInitPathsSize    = 16
 	nvgInitVertsSize    = 256
 	nvgMaxStates        = 32
+	nvgMaxLineDash      = 16
 )
 
 type nvgCommands int
@@ -31,13 +32,6 @@ const (
 	nvgPrINNERBEVEL nvgPointFlags = 0x08
 )
 
-type nvgTextureType int
-
-const (
-	nvgTextureALPHA nvgTextureType = 1
-	nvgTextureRGBA  nvgTextureType = 2
-)
-
 type nvgCodePointSize int
 
 const (
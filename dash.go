@@ -0,0 +1,133 @@
+package nanovgo
+
+import (
+	"math"
+)
+
+// normalizeDash copies the pattern into dash as described in Context.SetLineDash() and returns the count.
+// It returns 0 for solid lines.
+func normalizeDash(dash *[nvgMaxLineDash]float32, pattern []float32) int {
+	var total float32
+	for _, value := range pattern {
+		if value < 0 {
+			return 0
+		}
+		total += value
+	}
+	if total <= 0 {
+		return 0
+	}
+	count := len(pattern)
+	if count%2 == 1 {
+		count *= 2
+	}
+	if count > nvgMaxLineDash {
+		count = nvgMaxLineDash
+	}
+	for i := 0; i < count; i++ {
+		dash[i] = pattern[i%len(pattern)]
+	}
+	return count
+}
+
+// dashPaths splits the flattened paths into the dashes. Each dash becomes an open path, so it gets the caps
+// by expandStroke(). The pattern and the offset are scaled into the window coordinates by scale.
+// src is a work buffer to keep the original paths.
+func (c *nvgPathCache) dashPaths(src *nvgPathCache, pattern []float32, offset, scale float32, lineCap LineCap, distTol float32) {
+	src.points = append(src.points[:0], c.points...)
+	src.paths = append(src.paths[:0], c.paths...)
+	c.clearPathCache()
+
+	var total float32
+	for _, length := range pattern {
+		total += length * scale
+	}
+	offset *= scale
+
+	for i := range src.paths {
+		path := &src.paths[i]
+		points := src.points[path.first : path.first+path.count]
+		if len(points) < 2 {
+			continue
+		}
+		// Every sub-path starts from the beginning of the pattern.
+		index := 0
+		pos := offset - total*float32(math.Floor(float64(offset/total)))
+		for {
+			// A zero length entry is a dot only at the exact position.
+			length := pattern[index] * scale
+			if pos < length || (length == 0 && pos == 0) {
+				break
+			}
+			pos -= length
+			index = (index + 1) % len(pattern)
+		}
+		remain := pattern[index]*scale - pos
+		on := index%2 == 0
+		if on {
+			c.addDash(path.closed)
+			c.addPoint(points[0].x, points[0].y, nvgPtCORNER, distTol)
+		}
+		segments := len(points) - 1
+		if path.closed {
+			segments++
+		}
+		var dx, dy float32
+		for j := 0; j < segments; j++ {
+			p0 := &points[j]
+			p1 := &points[(j+1)%len(points)]
+			var length float32
+			length, dx, dy = normalize(p1.x-p0.x, p1.y-p0.y)
+			var t float32
+			for length-t > remain {
+				t += remain
+				x := p0.x + dx*t
+				y := p0.y + dy*t
+				if on {
+					c.addPoint(x, y, nvgPtCORNER, distTol)
+					c.finishDash(dx, dy, lineCap)
+				} else {
+					c.addDash(path.closed)
+					c.addPoint(x, y, nvgPtCORNER, distTol)
+				}
+				on = !on
+				index = (index + 1) % len(pattern)
+				remain = pattern[index] * scale
+			}
+			remain -= length - t
+			if on {
+				c.addPoint(p1.x, p1.y, p1.flags, distTol)
+			}
+		}
+		if on {
+			c.finishDash(dx, dy, lineCap)
+		}
+	}
+	// Dashes keep the directions of the original paths to be aligned to the same side.
+	c.calculateSegments(distTol, false)
+}
+
+// addDash adds new path for a dash. Dashes of the closed paths are stroked with the stroke align.
+func (c *nvgPathCache) addDash(aligned bool) {
+	c.addPath()
+	c.lastPath().aligned = aligned
+}
+
+// finishDash removes the last dash if it is too short to have the direction. Zero length dashes are drawn as dots
+// with round or square caps, so they get a tiny segment along the path.
+func (c *nvgPathCache) finishDash(dx, dy float32, lineCap LineCap) {
+	path := c.lastPath()
+	if path == nil || path.count >= 2 {
+		return
+	}
+	if lineCap == Butt || path.count == 0 {
+		c.points = c.points[:path.first]
+		c.paths = c.paths[:len(c.paths)-1]
+		return
+	}
+	last := c.points[len(c.points)-1]
+	last.x += dx * 0.01
+	last.y += dy * 0.01
+	c.points = append(c.points, last)
+	path.count++
+}
@@ -0,0 +1,109 @@
+package nanovgo
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSetLineDash(t *testing.T) {
+	c := &Context{}
+	c.Save()
+	c.Reset()
+
+	c.SetLineDash([]float32{1, 2, 3})
+	if dash := c.LineDash(); len(dash) != 6 || dash[3] != 1 || dash[5] != 3 {
+		t.Errorf("odd pattern should be repeated, but %v", dash)
+	}
+	c.SetLineDashOffset(2)
+	c.Save()
+	c.SetLineDash(nil)
+	c.SetLineDashOffset(0)
+	if c.LineDash() != nil {
+		t.Error("nil pattern should reset dash")
+	}
+	c.Restore()
+	if len(c.LineDash()) != 6 || c.LineDashOffset() != 2 {
+		t.Error("Restore() should restore dash pattern and offset")
+	}
+	for _, pattern := range [][]float32{{0, 0}, {4, -1}} {
+		c.SetLineDash(pattern)
+		if c.LineDash() != nil {
+			t.Errorf("invalid pattern %v should draw solid lines", pattern)
+		}
+	}
+}
+
+func TestDashedStroke(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+
+	c.BeginFrame(40, 20, 1.0)
+	c.BeginPath()
+	c.MoveTo(0, 5.5)
+	c.LineTo(40, 5.5)
+	c.SetStrokeColor(RGBA(0, 0, 0, 255))
+	c.SetStrokeWidth(3)
+	c.SetLineDash([]float32{4, 4})
+	c.SetLineDashOffset(2)
+	c.Stroke()
+
+	// dots along the scaled path with round caps
+	c.Scale(2, 2)
+	c.BeginPath()
+	c.MoveTo(0, 7.5)
+	c.LineTo(20, 7.5)
+	c.SetLineCap(Round)
+	c.SetLineDash([]float32{0, 5})
+	c.SetLineDashOffset(0)
+	c.Stroke()
+	c.EndFrame()
+
+	for x, on := range map[int]bool{1: true, 4: false, 8: true, 12: false, 16: true} {
+		if p := img.RGBAAt(x, 5); (p.A > 200) != on {
+			t.Errorf("dash at x=%d should be %v, but %v", x, on, p)
+		}
+	}
+	for x, on := range map[int]bool{10: true, 15: false, 20: true, 25: false, 30: true} {
+		if p := img.RGBAAt(x, 15); (p.A > 128) != on {
+			t.Errorf("dot at x=%d should be %v, but %v", x, on, p)
+		}
+	}
+}
+
+func TestDashPathsZeroLength(t *testing.T) {
+	for _, testCase := range []struct {
+		pattern []float32
+		offset  float32
+		dots    []float32
+	}{
+		{[]float32{0, 5}, 0, []float32{0, 5, 10, 15}},
+		{[]float32{0, 5}, 3, []float32{2, 7, 12, 17}},
+		{[]float32{0, 5}, 5, []float32{0, 5, 10, 15}},
+		{[]float32{2, 3, 0, 5}, 4, []float32{1, 11}},
+	} {
+		var c, src nvgPathCache
+		c.flattenPaths([]float32{float32(nvgMOVETO), 0, 0, float32(nvgLINETO), 20, 0}, 0.25, 0.01, NonZero)
+		c.dashPaths(&src, testCase.pattern, testCase.offset, 1.0, Round, 0.01)
+		var dots []float32
+		for _, path := range c.paths {
+			p := c.points[path.first]
+			if p.x < 0 || p.x > 20 {
+				t.Errorf("pattern %v offset %v: dash should be on the path, but starts at %v", testCase.pattern, testCase.offset, p.x)
+			}
+			if length := c.points[path.first+path.count-1].x - p.x; length < 0.02 {
+				dots = append(dots, p.x)
+			}
+		}
+		if len(dots) != len(testCase.dots) {
+			t.Errorf("pattern %v offset %v: dots should be at %v, but %v", testCase.pattern, testCase.offset, testCase.dots, dots)
+			continue
+		}
+		for i, x := range testCase.dots {
+			if absF(dots[i]-x) > 0.01 {
+				t.Errorf("pattern %v offset %v: dots should be at %v, but %v", testCase.pattern, testCase.offset, testCase.dots, dots)
+				break
+			}
+		}
+	}
+}
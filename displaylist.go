@@ -0,0 +1,200 @@
+package nanovgo
+
+type displayOp int
+
+const (
+	displayFill displayOp = iota
+	displayStroke
+	displayText
+	displayStrokeWidths
+)
+
+type displayItem struct {
+	op         displayOp
+	state      int // index of DisplayList.states
+	start, end int // range of DisplayList.commands
+	x, y       float32
+	runes      []rune
+	fontName   string
+	widths     []float32               // widths of StrokeVertexWidths()
+	widthFunc  func(t float32) float32 // width function of StrokeVariable()
+}
+
+// DisplayList is a recorded sequence of drawing calls made by Context.BeginRecording() and Context.EndRecording().
+//
+// It keeps the render state and the path (already transformed into the window coordinates) of each Fill(), Stroke(),
+// StrokeVariable(), StrokeVertexWidths() and Text() call, so it can be drawn again without running the drawing code.
+// Images are referred by their handles, so the image handles in the display list should be valid in the context
+// to replay.
+type DisplayList struct {
+	states   []nvgState
+	commands []float32
+	items    []displayItem
+}
+
+// BeginRecording starts recording the drawing calls. The calls are still drawn while recording.
+func (c *Context) BeginRecording() {
+	c.recording = &DisplayList{}
+}
+
+// EndRecording stops recording and returns the recorded display list. It returns nil if the context is not recording.
+func (c *Context) EndRecording() *DisplayList {
+	list := c.recording
+	c.recording = nil
+	return list
+}
+
+// Replay draws the recorded calls into the context. The current render state and path of the context are not changed.
+func (d *DisplayList) Replay(c *Context) {
+	d.replay(c, nil)
+}
+
+// ReplayWithTransform draws the recorded calls transformed by t in the window coordinates.
+func (d *DisplayList) ReplayWithTransform(c *Context, t TransformMatrix) {
+	d.replay(c, &t)
+}
+
+// Len returns the number of the recorded drawing calls.
+func (d *DisplayList) Len() int {
+	return len(d.items)
+}
+
+func (d *DisplayList) pushState(state *nvgState) int {
+	last := len(d.states) - 1
+	if last < 0 || d.states[last] != *state {
+		d.states = append(d.states, *state)
+		last++
+	}
+	return last
+}
+
+func (d *DisplayList) recordPath(op displayOp, c *Context, commands []float32) {
+	start := len(d.commands)
+	d.commands = append(d.commands, commands...)
+	d.items = append(d.items, displayItem{
+		op:    op,
+		state: d.pushState(c.getState()),
+		start: start,
+		end:   len(d.commands),
+	})
+}
+
+func (d *DisplayList) recordText(c *Context, x, y float32, runes []rune, fontName string) {
+	d.items = append(d.items, displayItem{
+		op:       displayText,
+		state:    d.pushState(c.getState()),
+		x:        x,
+		y:        y,
+		runes:    append([]rune(nil), runes...),
+		fontName: fontName,
+	})
+}
+
+// recordGlyph records a glyph of TextOnPath() as a text with the transform of the glyph.
+func (d *DisplayList) recordGlyph(c *Context, xform TransformMatrix, x float32, r rune, fontName string) {
+	state := *c.getState()
+	state.xform = xform
+	state.textAlign = AlignLeft | state.textAlign&(AlignTop|AlignMiddle|AlignBottom|AlignBaseline)
+	d.items = append(d.items, displayItem{
+		op:       displayText,
+		state:    d.pushState(&state),
+		x:        x,
+		runes:    []rune{r},
+		fontName: fontName,
+	})
+}
+
+// recordStrokeWidths records StrokeVariable() with the width function or StrokeVertexWidths() with the widths.
+func (d *DisplayList) recordStrokeWidths(c *Context, widths []float32, width func(t float32) float32) {
+	d.recordPath(displayStrokeWidths, c, c.commands)
+	item := &d.items[len(d.items)-1]
+	item.widths = append([]float32(nil), widths...)
+	item.widthFunc = width
+}
+
+func (d *DisplayList) replay(c *Context, t *TransformMatrix) {
+	savedState := *c.getState()
+	savedCommands := c.commands
+	savedX, savedY := c.commandX, c.commandY
+	defer func() {
+		*c.getState() = savedState
+		c.commands = savedCommands
+		c.commandX, c.commandY = savedX, savedY
+		c.cache.clearPathCache()
+	}()
+
+	var buffer []float32
+	// Clip paths shared by the states are transformed once to keep them shared.
+	clips := make(map[*ClipPath]*ClipPath)
+	for i := range d.items {
+		item := &d.items[i]
+		state := c.getState()
+		*state = d.states[item.state]
+		if t != nil {
+			state.transform(*t)
+			if clip := state.scissor.clip; clip != nil {
+				if clips[clip] == nil {
+					clips[clip] = clip.transform(*t)
+				}
+				state.scissor.clip = clips[clip]
+			}
+		}
+		switch item.op {
+		case displayFill, displayStroke, displayStrokeWidths:
+			commands := d.commands[item.start:item.end:item.end]
+			if t != nil {
+				buffer = append(buffer[:0], commands...)
+				transformCommands(buffer, *t)
+				commands = buffer
+			}
+			c.commands = commands
+			c.cache.clearPathCache()
+			switch item.op {
+			case displayFill:
+				c.Fill()
+			case displayStroke:
+				c.Stroke()
+			default:
+				if item.widthFunc != nil {
+					c.StrokeVariable(item.widthFunc)
+				} else {
+					c.StrokeVertexWidths(item.widths)
+				}
+			}
+		case displayText:
+			// Font handles may be different in another context. Find the font by name.
+			if font := c.FindFont(item.fontName); font >= 0 {
+				state.fontID = font
+			}
+			c.TextRune(item.x, item.y, item.runes)
+		}
+	}
+}
+
+// transform applies t after the transforms of the state.
+func (s *nvgState) transform(t TransformMatrix) {
+	s.xform = s.xform.Multiply(t)
+	s.fill.xform = s.fill.xform.Multiply(t)
+	s.stroke.xform = s.stroke.xform.Multiply(t)
+	s.scissor.xform = s.scissor.xform.Multiply(t)
+}
+
+func transformCommands(commands []float32, t TransformMatrix) {
+	i := 0
+	for i < len(commands) {
+		switch nvgCommands(commands[i]) {
+		case nvgMOVETO, nvgLINETO:
+			commands[i+1], commands[i+2] = t.TransformPoint(commands[i+1], commands[i+2])
+			i += 3
+		case nvgBEZIERTO:
+			commands[i+1], commands[i+2] = t.TransformPoint(commands[i+1], commands[i+2])
+			commands[i+3], commands[i+4] = t.TransformPoint(commands[i+3], commands[i+4])
+			commands[i+5], commands[i+6] = t.TransformPoint(commands[i+5], commands[i+6])
+			i += 7
+		case nvgWINDING:
+			i += 2
+		default:
+			i++
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package nanovgo
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func drawDisplayListSample(c *Context) {
+	c.Save()
+	c.Translate(4, 4)
+	c.BeginPath()
+	c.Rect(0, 0, 8, 8)
+	c.SetFillPaint(LinearGradient(0, 0, 8, 0, RGBA(255, 0, 0, 255), RGBA(0, 0, 255, 255)))
+	c.Fill()
+	c.BeginPath()
+	c.Circle(12, 12, 3)
+	c.SetStrokeColor(RGBA(0, 255, 0, 255))
+	c.SetStrokeWidth(2)
+	c.Stroke()
+	c.Restore()
+}
+
+func TestDisplayListReplay(t *testing.T) {
+	direct := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	c1, _ := NewSoftwareContext(direct, AntiAlias)
+	defer c1.Delete()
+	c1.BeginFrame(32, 32, 1.0)
+	c1.BeginRecording()
+	drawDisplayListSample(c1)
+	list := c1.EndRecording()
+	c1.EndFrame()
+
+	if list.Len() != 2 {
+		t.Fatalf("display list should have 2 calls, but %d", list.Len())
+	}
+	if c1.EndRecording() != nil {
+		t.Error("EndRecording() should return nil when the context is not recording")
+	}
+
+	replayed := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	c2, _ := NewSoftwareContext(replayed, AntiAlias)
+	defer c2.Delete()
+	c2.BeginFrame(32, 32, 1.0)
+	c2.SetFillColor(RGBA(1, 2, 3, 4))
+	c2.BeginPath()
+	c2.MoveTo(1, 1)
+	list.Replay(c2)
+	if c2.getState().fill.innerColor != RGBA(1, 2, 3, 4) || len(c2.commands) != 3 {
+		t.Error("Replay() should keep the render state and the path of the context")
+	}
+	c2.EndFrame()
+
+	if !bytes.Equal(direct.Pix, replayed.Pix) {
+		t.Error("replayed image should be same as the original drawing")
+	}
+}
+
+func TestDisplayListReplayWithTransform(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+	c.BeginFrame(64, 64, 1.0)
+	c.BeginRecording()
+	c.BeginPath()
+	c.Rect(0, 0, 8, 8)
+	c.SetFillColor(RGBA(255, 0, 0, 255))
+	c.Fill()
+	list := c.EndRecording()
+	list.ReplayWithTransform(c, TranslateMatrix(32, 32).PreMultiply(ScaleMatrix(2, 2)))
+	c.EndFrame()
+
+	if p := img.RGBAAt(4, 4); p.R != 255 || p.A != 255 {
+		t.Errorf("recorded rect should be drawn, but %v", p)
+	}
+	if p := img.RGBAAt(46, 46); p.R != 255 || p.A != 255 {
+		t.Errorf("replayed rect should be scaled and moved, but %v", p)
+	}
+	if p := img.RGBAAt(50, 50); p.A != 0 {
+		t.Errorf("pixel outside of the replayed rect should be transparent, but %v", p)
+	}
+}
+
+func TestDisplayListTextOnPath(t *testing.T) {
+	drawText := func(c *Context) {
+		c.SetFontFace("sans")
+		c.SetFontSize(20)
+		c.SetFillColor(RGBA(0, 0, 0, 255))
+		c.SetTextAlign(AlignCenter | AlignMiddle)
+		c.BeginPath()
+		c.MoveTo(10, 60)
+		c.QuadTo(50, 0, 90, 60)
+		c.TextOnPath(55, "Hello")
+	}
+
+	direct := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	c1 := newTextTestContext(t, direct)
+	defer c1.Delete()
+	c1.BeginFrame(100, 100, 1.0)
+	c1.BeginRecording()
+	drawText(c1)
+	list := c1.EndRecording()
+	glyphs := textCalls(c1, 0)
+	c1.EndFrame()
+
+	if list.Len() != 5 {
+		t.Fatalf("each glyph should be recorded, but %d calls", list.Len())
+	}
+
+	replayed := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	c2 := newTextTestContext(t, replayed)
+	defer c2.Delete()
+	c2.BeginFrame(100, 100, 1.0)
+	list.Replay(c2)
+	replayedGlyphs := textCalls(c2, 0)
+	c2.EndFrame()
+
+	var vertexes, replayedVertexes []Vertex
+	for _, call := range glyphs {
+		vertexes = append(vertexes, call.triangles...)
+	}
+	for _, call := range replayedGlyphs {
+		replayedVertexes = append(replayedVertexes, call.triangles...)
+	}
+	if len(vertexes) != 20 || len(replayedVertexes) != len(vertexes) {
+		t.Fatalf("5 glyph quads should be replayed, but %d and %d vertexes", len(vertexes), len(replayedVertexes))
+	}
+	for i, v := range replayedVertexes {
+		x0, y0 := v.Pos()
+		x1, y1 := vertexes[i].Pos()
+		if absF(x0-x1) > 0.01 || absF(y0-y1) > 0.01 {
+			t.Errorf("replayed glyph vertex %d should be %v, but %v", i, vertexes[i], v)
+		}
+	}
+	if !bytes.Equal(direct.Pix, replayed.Pix) {
+		t.Error("replayed text should be same as the original drawing")
+	}
+}
@@ -1,9 +1,11 @@
 package nanovgo
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"github.com/goxjs/gl"
+	"math"
 	"strings"
 )
 
@@ -22,7 +24,7 @@ func NewContext(flags CreateFlags) (*Context, error) {
 			flags: flags,
 		},
 	}
-	return createInternal(params)
+	return createInternal(params, flags)
 }
 
 type glShader struct {
@@ -92,6 +94,14 @@ func (s *glShader) getUniforms() {
 
 const (
 	glnvgGLUniformArraySize = 11
+	glnvgFragBinding        = 0
+)
+
+// OpenGL 3.x enums which github.com/goxjs/gl doesn't have.
+const (
+	glRed                          gl.Enum = 0x1903
+	glUniformBuffer                gl.Enum = 0x8A11
+	glUniformBufferOffsetAlignment gl.Enum = 0x8A34
 )
 
 const (
@@ -100,21 +110,38 @@ const (
 )
 
 type glContext struct {
-	shader       glShader
-	view         [2]float32
-	textures     []*glTexture
-	textureID    int
-	vertexBuffer gl.Buffer
-	flags        CreateFlags
-	calls        []glCall
-	paths        []glPath
-	vertexes     []float32
-	uniforms     []glFragUniforms
+	shader        glShader
+	view          [2]float32
+	textures      []*glTexture
+	textureID     int
+	vertexBuffer  gl.Buffer
+	flags         CreateFlags
+	maxTexSize    int
+	calls         []glCall
+	batchedCalls  []glCall
+	batchVertexes []float32
+	paths         []glPath
+	vertexes      []float32
+	uniforms      []glFragUniforms
+
+	// GL3 only
+	vertexArray  uint32
+	fragBuffer   gl.Buffer
+	fragSize     int
+	uniformBytes []byte
+
+	// statistics of the last flush
+	flushedVertexBytes   int
+	flushedUniformBlocks int
+	flushedDrawCalls     int
+	drawCalls            int // draw calls issued by the current flush
 
 	stencilMask     uint32
 	stencilFunc     gl.Enum
 	stencilFuncRef  int
 	stencilFuncMask uint32
+
+	clipPath *ClipPath // clip path of the last recorded call
 }
 
 func (c *glContext) findTexture(id int) *glTexture {
@@ -144,6 +171,12 @@ func (c *glContext) bindTexture(tex *gl.Texture) {
 	}
 }
 
+// drawArrays issues the draw call and counts it for the statistics.
+func (c *glContext) drawArrays(mode gl.Enum, first, count int) {
+	gl.DrawArrays(mode, first, count)
+	c.drawCalls++
+}
+
 func (c *glContext) setStencilMask(mask uint32) {
 	if c.stencilMask != mask {
 		c.stencilMask = mask
@@ -155,7 +188,7 @@ func (c *glContext) setStencilFunc(fun gl.Enum, ref int, mask uint32) {
 	if c.stencilFunc != fun || c.stencilFuncRef != ref || c.stencilFuncMask != mask {
 		c.stencilFunc = fun
 		c.stencilFuncRef = ref
-		c.stencilMask = mask
+		c.stencilFuncMask = mask
 		gl.StencilFunc(fun, ref, mask)
 	}
 }
@@ -166,7 +199,7 @@ func (c *glContext) checkError(str string) {
 	}
 	err := gl.GetError()
 	if err != gl.NO_ERROR {
-		dumpLog("Error %08x after %s\n", err, str)
+		dumpLog(fmt.Sprintf("Error %08x after %s", err, str))
 	}
 }
 
@@ -205,60 +238,57 @@ func (c *glContext) allocTexture() *glTexture {
 	return tex
 }
 
-func (c *glContext) convertPaint(frag *glFragUniforms, paint *Paint, scissor *nvgScissor, width, fringe, strokeThr float32) error {
-	frag.setInnerColor(paint.innerColor.PreMultiply())
-	frag.setOuterColor(paint.outerColor.PreMultiply())
-
-	if scissor.extent[0] < -0.5 || scissor.extent[1] < -0.5 {
-		frag.clearScissorMat()
-		frag.setScissorExt(1.0, 1.0)
-		frag.setScissorScale(1.0, 1.0)
-	} else {
-		xform := &scissor.xform
-		frag.setScissorMat(xform.Inverse().ToMat3x4())
-		frag.setScissorExt(scissor.extent[0], scissor.extent[1])
-		scaleX := sqrtF(xform[0]*xform[0]+xform[2]*xform[2]) / fringe
-		scaleY := sqrtF(xform[1]*xform[1]+xform[3]*xform[3]) / fringe
-		frag.setScissorScale(scaleX, scaleY)
-	}
-	frag.setExtent(paint.extent)
-	frag.setStrokeMult((width*0.5 + fringe*0.5) / fringe)
-	frag.setStrokeThr(strokeThr)
-
+func (c *glContext) convertPaint(frag *glFragUniforms, paint *Paint, scissor *Scissor, width, fringe, strokeThr float32) error {
+	var texType TextureType
+	var texFlags ImageFlags
 	if paint.image != 0 {
 		tex := c.findTexture(paint.image)
 		if tex == nil {
 			return errors.New("invalid texture in GLParams.convertPaint")
 		}
-		if tex.flags&ImageFlippy != 0 {
-			frag.setPaintMat(ScaleMatrix(1.0, -1.0).Multiply(paint.xform).Inverse().ToMat3x4())
-		} else {
-			frag.setPaintMat(paint.xform.Inverse().ToMat3x4())
-		}
-		frag.setType(nsvgShaderFILLIMG)
+		texType = tex.texType
+		texFlags = tex.flags
+	}
+	frag.convertPaint(paint, scissor, width, fringe, strokeThr, texType, texFlags)
+	return nil
+}
 
-		if tex.texType == nvgTextureRGBA {
-			if tex.flags&ImagePreMultiplied != 0 {
-				frag.setTexType(0)
-			} else {
-				frag.setTexType(1)
-			}
-		} else {
-			frag.setTexType(2)
-		}
-	} else {
-		frag.setType(nsvgShaderFILLGRAD)
-		frag.setRadius(paint.radius)
-		frag.setFeather(paint.feather)
-		frag.setPaintMat(paint.xform.Inverse().ToMat3x4())
+// alphaFormat returns the pixel format of TextureAlpha. GL3 core profile doesn't have LUMINANCE.
+func (c *glContext) alphaFormat() gl.Enum {
+	if c.flags&GL3 != 0 {
+		return glRed
 	}
+	return gl.LUMINANCE
+}
 
-	return nil
+// uniformBufferData packs the uniforms in std140 layout of the frag uniform block. Each block starts at
+// the multiple of fragSize.
+func (c *glContext) uniformBufferData() []byte {
+	size := len(c.uniforms) * c.fragSize
+	if cap(c.uniformBytes) < size {
+		c.uniformBytes = make([]byte, size)
+	}
+	b := c.uniformBytes[:size]
+	for i := range c.uniforms {
+		frag := &c.uniforms[i]
+		block := b[i*c.fragSize:]
+		for j := 0; j < 42; j++ {
+			binary.LittleEndian.PutUint32(block[j*4:], math.Float32bits(frag[j]))
+		}
+		// texType and type are int in the uniform block.
+		binary.LittleEndian.PutUint32(block[42*4:], uint32(int32(frag[42])))
+		binary.LittleEndian.PutUint32(block[43*4:], uint32(int32(frag[43])))
+	}
+	return b
 }
 
 func (c *glContext) setUniforms(uniformOffset, image int) {
-	frag := c.uniforms[uniformOffset]
-	gl.Uniform4fv(c.shader.locations[glnvgLocFRAG], frag[:])
+	if c.flags&GL3 != 0 {
+		gl3BindBufferRange(glUniformBuffer, glnvgFragBinding, c.fragBuffer, uniformOffset*c.fragSize, len(glFragUniforms{})*4)
+	} else {
+		frag := c.uniforms[uniformOffset]
+		gl.Uniform4fv(c.shader.locations[glnvgLocFRAG], frag[:])
+	}
 
 	if image != 0 {
 		c.bindTexture(&c.findTexture(image).tex)
@@ -271,23 +301,35 @@ func (c *glContext) setUniforms(uniformOffset, image int) {
 func (c *glContext) fill(call *glCall) {
 	pathSentinel := call.pathOffset + call.pathCount
 
+	clipRef, clipMask := call.clipStencil()
+
 	// Draw shapes
 	gl.Enable(gl.STENCIL_TEST)
-	c.setStencilMask(0xff)
-	c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+	c.setStencilMask(glnvgStencilBits)
+	if call.clipped {
+		// Count the winding only in the clip region
+		c.setStencilFunc(gl.EQUAL, clipRef, clipMask)
+	} else {
+		c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+	}
 	gl.ColorMask(false, false, false, false)
 
 	// set bindpoint for solid loc
 	c.setUniforms(call.uniformOffset, 0)
 	checkError(c, "fill simple")
 
-	gl.StencilOpSeparate(gl.FRONT, gl.KEEP, gl.KEEP, gl.INCR_WRAP)
-	gl.StencilOpSeparate(gl.BACK, gl.KEEP, gl.KEEP, gl.DECR_WRAP)
+	if call.fillRule == EvenOdd {
+		// Each covering triangle toggles the stencil between 0x00 and 0x7f.
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.INVERT)
+	} else {
+		gl.StencilOpSeparate(gl.FRONT, gl.KEEP, gl.KEEP, gl.INCR_WRAP)
+		gl.StencilOpSeparate(gl.BACK, gl.KEEP, gl.KEEP, gl.DECR_WRAP)
+	}
 
 	gl.Disable(gl.CULL_FACE)
 	for i := call.pathOffset; i < pathSentinel; i++ {
 		path := &c.paths[i]
-		gl.DrawArrays(gl.TRIANGLE_FAN, path.fillOffset, path.fillCount)
+		c.drawArrays(gl.TRIANGLE_FAN, path.fillOffset, path.fillCount)
 	}
 	gl.Enable(gl.CULL_FACE)
 
@@ -296,19 +338,19 @@ func (c *glContext) fill(call *glCall) {
 	c.setUniforms(call.uniformOffset+1, call.image)
 
 	if c.flags&AntiAlias != 0 {
-		c.setStencilFunc(gl.EQUAL, 0x00, 0xff)
+		c.setStencilFunc(gl.EQUAL, clipRef, glnvgStencilBits|clipMask)
 		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
 		// Draw fringes
 		for i := call.pathOffset; i < pathSentinel; i++ {
 			path := &c.paths[i]
-			gl.DrawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
+			c.drawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
 		}
 	}
 
 	// Draw fill
-	c.setStencilFunc(gl.NOTEQUAL, 0x00, 0xff)
+	c.setStencilFunc(gl.NOTEQUAL, 0x00, glnvgStencilBits)
 	gl.StencilOp(gl.ZERO, gl.ZERO, gl.ZERO)
-	gl.DrawArrays(gl.TRIANGLES, call.triangleOffset, call.triangleCount)
+	c.drawArrays(gl.TRIANGLES, call.triangleOffset, call.triangleCount)
 
 	gl.Disable(gl.STENCIL_TEST)
 }
@@ -318,44 +360,47 @@ func (c *glContext) convexFill(call *glCall) {
 
 	c.setUniforms(call.uniformOffset, call.image)
 	checkError(c, "convex fill")
+	c.enableClipTest(call)
 
 	for i := range paths {
 		path := &paths[i]
-		gl.DrawArrays(gl.TRIANGLE_FAN, path.fillOffset, path.fillCount)
+		c.drawArrays(gl.TRIANGLE_FAN, path.fillOffset, path.fillCount)
 	}
 
 	if c.flags&AntiAlias != 0 {
 		for i := range paths {
 			path := &paths[i]
-			gl.DrawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
+			c.drawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
 		}
 	}
+	c.disableClipTest(call)
 }
 
 func (c *glContext) stroke(call *glCall) {
 	paths := c.paths[call.pathOffset : call.pathOffset+call.pathCount]
 
 	if c.flags&StencilStrokes != 0 {
+		clipRef, clipMask := call.clipStencil()
 		gl.Enable(gl.STENCIL_TEST)
-		c.setStencilMask(0xff)
+		c.setStencilMask(glnvgStencilBits)
 
 		// Fill the stroke base without overlap
-		c.setStencilFunc(gl.EQUAL, 0x00, 0xff)
+		c.setStencilFunc(gl.EQUAL, clipRef, glnvgStencilBits|clipMask)
 		gl.StencilOp(gl.KEEP, gl.KEEP, gl.INCR)
 		c.setUniforms(call.uniformOffset+1, call.image)
 		checkError(c, "stroke fill 0")
 		for i := range paths {
 			path := &paths[i]
-			gl.DrawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
+			c.drawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
 		}
 
 		// Draw anti-aliased pixels.
 		c.setUniforms(call.uniformOffset, call.image)
-		c.setStencilFunc(gl.EQUAL, 0x00, 0xff)
+		c.setStencilFunc(gl.EQUAL, clipRef, glnvgStencilBits|clipMask)
 		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
 		for i := range paths {
 			path := &paths[i]
-			gl.DrawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
+			c.drawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
 		}
 
 		// Clear stencil buffer.
@@ -365,30 +410,36 @@ func (c *glContext) stroke(call *glCall) {
 		checkError(c, "stroke fill 1")
 		for i := range paths {
 			path := &paths[i]
-			gl.DrawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
+			c.drawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
 		}
 		gl.ColorMask(true, true, true, true)
 		gl.Disable(gl.STENCIL_TEST)
 	} else {
 		c.setUniforms(call.uniformOffset, call.image)
 		checkError(c, "stroke fill")
+		c.enableClipTest(call)
 		for i := range paths {
 			path := &paths[i]
-			gl.DrawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
+			c.drawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
 		}
+		c.disableClipTest(call)
 	}
 }
 
 func (c *glContext) triangles(call *glCall) {
 	c.setUniforms(call.uniformOffset, call.image)
 	checkError(c, "triangles fill")
-	gl.DrawArrays(gl.TRIANGLES, call.triangleOffset, call.triangleCount)
+	c.enableClipTest(call)
+	c.drawArrays(gl.TRIANGLES, call.triangleOffset, call.triangleCount)
+	c.disableClipTest(call)
 }
 
 func (c *glContext) triangleStrip(call *glCall) {
 	c.setUniforms(call.uniformOffset, call.image)
 	checkError(c, "triangle strip fill")
-	gl.DrawArrays(gl.TRIANGLE_STRIP, call.triangleOffset, call.triangleCount)
+	c.enableClipTest(call)
+	c.drawArrays(gl.TRIANGLE_STRIP, call.triangleOffset, call.triangleCount)
+	c.disableClipTest(call)
 }
 
 type glParams struct {
@@ -396,23 +447,30 @@ type glParams struct {
 	context         *glContext
 }
 
-func (p *glParams) edgeAntiAlias() bool {
+func (p *glParams) EdgeAntiAlias() bool {
 	return p.isEdgeAntiAlias
 }
 
-func (p *glParams) renderCreate() error {
+func (p *glParams) RenderCreate() error {
 	context := p.context
 	//align := 4
 
 	checkError(context, "init")
 
-	if p.edgeAntiAlias() {
-		err := context.shader.createShader("shader", shaderHeader, "#define EDGE_AA 1", fillVertexShader, fillFragmentShader)
+	header := shaderHeader
+	if context.flags&GL3 != 0 {
+		if err := gl3Init(); err != nil {
+			return err
+		}
+		header = shaderHeaderGL3
+	}
+	if p.EdgeAntiAlias() {
+		err := context.shader.createShader("shader", header, "#define EDGE_AA 1", fillVertexShader, fillFragmentShader)
 		if err != nil {
 			return err
 		}
 	} else {
-		err := context.shader.createShader("shader", shaderHeader, "", fillVertexShader, fillFragmentShader)
+		err := context.shader.createShader("shader", header, "", fillVertexShader, fillFragmentShader)
 		if err != nil {
 			return err
 		}
@@ -422,25 +480,43 @@ func (p *glParams) renderCreate() error {
 
 	context.vertexBuffer = gl.CreateBuffer()
 	context.vertexBuffer = gl.CreateBuffer()
+	context.maxTexSize = gl.GetInteger(gl.MAX_TEXTURE_SIZE)
+
+	if context.flags&GL3 != 0 {
+		// Core profile requires vertex array object. The frag uniform block uses the default binding point 0.
+		context.vertexArray = gl3CreateVertexArray()
+		context.fragBuffer = gl.CreateBuffer()
+		align := gl.GetInteger(glUniformBufferOffsetAlignment)
+		if align < 4 {
+			align = 4
+		}
+		fragSize := len(glFragUniforms{}) * 4
+		context.fragSize = (fragSize + align - 1) / align * align
+	}
 
 	checkError(context, "create done")
 	gl.Finish()
 	return nil
 }
 
-func (p *glParams) renderCreateTexture(texType nvgTextureType, w, h int, flags ImageFlags, data []byte) int {
+func (p *glParams) RenderCreateTexture(texType TextureType, w, h int, flags ImageFlags, data []byte) int {
 	if nearestPow2(w) != w || nearestPow2(h) != h {
 		if (flags&ImageRepeatX) != 0 || (flags&ImageRepeatY) != 0 {
-			dumpLog("Repeat X/Y is not supported for non power-of-two textures (%d x %d)\n", w, h)
+			dumpLog(fmt.Sprintf("Repeat X/Y is not supported for non power-of-two textures (%d x %d)", w, h))
 			flags &= ^(ImageRepeatY | ImageRepeatX)
 		}
 		if (flags & ImageGenerateMipmaps) != 0 {
-			dumpLog("Mip-maps is not support for non power-of-two textures (%d x %d)\n", w, h)
+			dumpLog(fmt.Sprintf("Mip-maps is not support for non power-of-two textures (%d x %d)", w, h))
 			flags &= ^ImageGenerateMipmaps
 		}
 	}
+	glTex := gl.CreateTexture()
+	if !glTex.Valid() {
+		dumpLog(fmt.Sprintf("Can't create texture (%d x %d)", w, h))
+		return 0
+	}
 	tex := p.context.allocTexture()
-	tex.tex = gl.CreateTexture()
+	tex.tex = glTex
 	tex.width = w
 	tex.height = h
 	tex.texType = texType
@@ -449,12 +525,12 @@ func (p *glParams) renderCreateTexture(texType nvgTextureType, w, h int, flags I
 	p.context.bindTexture(&tex.tex)
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
 
-	if texType == nvgTextureRGBA {
+	if texType == TextureRGBA {
 		data = prepareTextureBuffer(data, w, h, 4)
 		gl.TexImage2D(gl.TEXTURE_2D, 0, w, h, gl.RGBA, gl.UNSIGNED_BYTE, data)
 	} else {
 		data = prepareTextureBuffer(data, w, h, 1)
-		gl.TexImage2D(gl.TEXTURE_2D, 0, w, h, gl.LUMINANCE, gl.UNSIGNED_BYTE, data)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, w, h, p.context.alphaFormat(), gl.UNSIGNED_BYTE, data)
 	}
 
 	if (flags & ImageGenerateMipmaps) != 0 {
@@ -488,7 +564,12 @@ func (p *glParams) renderCreateTexture(texType nvgTextureType, w, h int, flags I
 	return tex.id
 }
 
-func (p *glParams) renderDeleteTexture(id int) error {
+// RenderMaxTextureSize returns GL_MAX_TEXTURE_SIZE.
+func (p *glParams) RenderMaxTextureSize() int {
+	return p.context.maxTexSize
+}
+
+func (p *glParams) RenderDeleteTexture(id int) error {
 	tex := p.context.findTexture(id)
 	if tex.tex.Valid() && (tex.flags&ImageNoDelete) == 0 {
 		gl.DeleteTexture(tex.tex)
@@ -499,7 +580,7 @@ func (p *glParams) renderDeleteTexture(id int) error {
 	return errors.New("invalid texture in GLParams.deleteTexture")
 }
 
-func (p *glParams) renderUpdateTexture(image, x, y, w, h int, data []byte) error {
+func (p *glParams) RenderUpdateTexture(image, x, y, w, h int, data []byte) error {
 	tex := p.context.findTexture(image)
 	if tex == nil {
 		return errors.New("invalid texture in GLParams.updateTexture")
@@ -507,7 +588,7 @@ func (p *glParams) renderUpdateTexture(image, x, y, w, h int, data []byte) error
 	p.context.bindTexture(&tex.tex)
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
 
-	if tex.texType == nvgTextureRGBA {
+	if tex.texType == TextureRGBA {
 		data = data[y*tex.width*4:]
 	} else {
 		data = data[y*tex.width:]
@@ -515,10 +596,10 @@ func (p *glParams) renderUpdateTexture(image, x, y, w, h int, data []byte) error
 	x = 0
 	w = tex.width
 
-	if tex.texType == nvgTextureRGBA {
+	if tex.texType == TextureRGBA {
 		gl.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, w, h, gl.RGBA, gl.UNSIGNED_BYTE, data)
 	} else {
-		gl.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, w, h, gl.LUMINANCE, gl.UNSIGNED_BYTE, data)
+		gl.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, w, h, p.context.alphaFormat(), gl.UNSIGNED_BYTE, data)
 	}
 
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
@@ -528,7 +609,7 @@ func (p *glParams) renderUpdateTexture(image, x, y, w, h int, data []byte) error
 	return nil
 }
 
-func (p *glParams) renderGetTextureSize(image int) (int, int, error) {
+func (p *glParams) RenderGetTextureSize(image int) (int, int, error) {
 	tex := p.context.findTexture(image)
 	if tex == nil {
 		return -1, -1, errors.New("invalid texture in GLParams.getTextureSize")
@@ -536,23 +617,26 @@ func (p *glParams) renderGetTextureSize(image int) (int, int, error) {
 	return tex.width, tex.height, nil
 }
 
-func (p *glParams) renderViewport(width, height int) {
+func (p *glParams) RenderViewport(width, height int) {
 	p.context.view[0] = float32(width)
 	p.context.view[1] = float32(height)
 }
 
-func (p *glParams) renderCancel() {
+func (p *glParams) RenderCancel() {
 	c := p.context
 	c.vertexes = c.vertexes[:0]
 	c.paths = c.paths[:0]
 	c.calls = c.calls[:0]
 	c.uniforms = c.uniforms[:0]
+	c.clipPath = nil
 }
 
-func (p *glParams) renderFlush() {
+func (p *glParams) RenderFlush() {
 	c := p.context
 
+	c.drawCalls = 0
 	if len(c.calls) > 0 {
+		c.batchCalls()
 		gl.UseProgram(c.shader.program)
 
 		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
@@ -572,6 +656,12 @@ func (p *glParams) renderFlush() {
 		c.stencilFunc = gl.ALWAYS
 		c.stencilFuncRef = 0
 		c.stencilFuncMask = 0xffffffff
+		if c.flags&GL3 != 0 {
+			// Upload uniforms of all calls at once
+			gl.BindBuffer(glUniformBuffer, c.fragBuffer)
+			gl.BufferData(glUniformBuffer, c.uniformBufferData(), gl.STREAM_DRAW)
+			gl3BindVertexArray(c.vertexArray)
+		}
 		b := castFloat32ToByte(c.vertexes)
 		//dumpLog("vertex:", c.vertexes)
 		// Upload vertex data
@@ -599,32 +689,55 @@ func (p *glParams) renderFlush() {
 				c.triangles(call)
 			case glnvgTRIANGLESTRIP:
 				c.triangleStrip(call)
+			case glnvgCLIP:
+				c.clip(call)
 			}
 		}
 		gl.DisableVertexAttribArray(c.shader.vertexAttrib)
 		gl.DisableVertexAttribArray(c.shader.tcoordAttrib)
 		gl.Disable(gl.CULL_FACE)
 		gl.BindBuffer(gl.ARRAY_BUFFER, gl.Buffer{})
+		if c.flags&GL3 != 0 {
+			gl3BindVertexArray(0)
+			gl.BindBuffer(glUniformBuffer, gl.Buffer{})
+		}
 		gl.UseProgram(gl.Program{})
 		c.bindTexture(nil)
 	}
+	c.flushedVertexBytes = len(c.vertexes) * 4
+	c.flushedUniformBlocks = len(c.uniforms)
+	c.flushedDrawCalls = c.drawCalls
 	c.vertexes = c.vertexes[:0]
 	c.paths = c.paths[:0]
 	c.calls = c.calls[:0]
 	c.uniforms = c.uniforms[:0]
+	c.clipPath = nil
+}
+
+// RenderFrameStats returns the vertex bytes and the uniform blocks uploaded and the draw calls issued
+// by the last RenderFlush().
+func (p *glParams) RenderFrameStats() (vertexBytes, uniformBlocks, drawCalls int) {
+	return p.context.flushedVertexBytes, p.context.flushedUniformBlocks, p.context.flushedDrawCalls
+}
+
+func (p *glParams) RenderFill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath) {
+	p.RenderFillRule(paint, scissor, fringe, bounds, paths, NonZero)
 }
 
-func (p *glParams) renderFill(paint *Paint, scissor *nvgScissor, fringe float32, bounds [4]float32, paths []nvgPath) {
+func (p *glParams) RenderFillRule(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath, fillRule FillRule) {
 	c := p.context
 	var glPaths []glPath
+	clipped := p.useClip(scissor.clip)
 	c.calls = append(c.calls, glCall{
 		pathCount: len(paths),
 		image:     paint.image,
+		fillRule:  fillRule,
+		clipped:   clipped,
 	})
 	call := &c.calls[len(c.calls)-1]
 	glPaths, call.pathOffset = c.allocPath(call.pathCount)
 
-	if len(paths) == 0 && paths[0].convex {
+	if len(paths) == 1 && paths[0].convex {
 		call.callType = glnvgCONVEXFILL
 	} else {
 		call.callType = glnvgFILL
@@ -674,41 +787,7 @@ func (p *glParams) renderFill(paint *Paint, scissor *nvgScissor, fringe float32,
 	// Quad
 	call.triangleOffset = vertexOffset / 4
 	call.triangleCount = 6
-
-	c.vertexes[vertexOffset] = bounds[0]
-	c.vertexes[vertexOffset+1] = bounds[3]
-	c.vertexes[vertexOffset+2] = 0.5
-	c.vertexes[vertexOffset+3] = 1.0
-	vertexOffset += 4
-
-	c.vertexes[vertexOffset] = bounds[2]
-	c.vertexes[vertexOffset+1] = bounds[3]
-	c.vertexes[vertexOffset+2] = 0.5
-	c.vertexes[vertexOffset+3] = 1.0
-	vertexOffset += 4
-
-	c.vertexes[vertexOffset] = bounds[2]
-	c.vertexes[vertexOffset+1] = bounds[1]
-	c.vertexes[vertexOffset+2] = 0.5
-	c.vertexes[vertexOffset+3] = 1.0
-	vertexOffset += 4
-
-	c.vertexes[vertexOffset] = bounds[0]
-	c.vertexes[vertexOffset+1] = bounds[3]
-	c.vertexes[vertexOffset+2] = 0.5
-	c.vertexes[vertexOffset+3] = 1.0
-	vertexOffset += 4
-
-	c.vertexes[vertexOffset] = bounds[2]
-	c.vertexes[vertexOffset+1] = bounds[1]
-	c.vertexes[vertexOffset+2] = 0.5
-	c.vertexes[vertexOffset+3] = 1.0
-	vertexOffset += 4
-
-	c.vertexes[vertexOffset] = bounds[0]
-	c.vertexes[vertexOffset+1] = bounds[1]
-	c.vertexes[vertexOffset+2] = 0.5
-	c.vertexes[vertexOffset+3] = 1.0
+	setQuadVertexes(c.vertexes[vertexOffset:], bounds)
 
 	// Setup uniforms for draw calls
 	var paintFrag *glFragUniforms
@@ -731,10 +810,11 @@ func (p *glParams) renderFill(paint *Paint, scissor *nvgScissor, fringe float32,
 	c.convertPaint(paintFrag, paint, scissor, fringe, fringe, -1.0)
 }
 
-func (p *glParams) renderStroke(paint *Paint, scissor *nvgScissor, fringe float32, strokeWidth float32, paths []nvgPath) {
+func (p *glParams) RenderStroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []RenderPath) {
 	c := p.context
 	var glPaths []glPath
-	p.context.calls = append(c.calls, glCall{})
+	clipped := p.useClip(scissor.clip)
+	p.context.calls = append(c.calls, glCall{clipped: clipped})
 	call := &c.calls[len(c.calls)-1]
 	call.callType = glnvgSTROKE
 	glPaths, call.pathOffset = c.allocPath(len(paths))
@@ -785,9 +865,10 @@ func (p *glParams) renderStroke(paint *Paint, scissor *nvgScissor, fringe float3
 	}
 }
 
-func (p *glParams) renderTriangles(paint *Paint, scissor *nvgScissor, vertexes []nvgVertex) {
+func (p *glParams) RenderTriangles(paint *Paint, scissor *Scissor, vertexes []Vertex) {
 	c := p.context
 
+	clipped := p.useClip(scissor.clip)
 	vertexCount := len(vertexes)
 	vertexOffset := c.allocVertexMemory(vertexCount)
 	callIndex := len(c.calls)
@@ -797,6 +878,7 @@ func (p *glParams) renderTriangles(paint *Paint, scissor *nvgScissor, vertexes [
 		image:          paint.image,
 		triangleOffset: vertexOffset / 4,
 		triangleCount:  vertexCount,
+		clipped:        clipped,
 	})
 	call := &c.calls[callIndex]
 
@@ -818,9 +900,10 @@ func (p *glParams) renderTriangles(paint *Paint, scissor *nvgScissor, vertexes [
 	f0.setType(nsvgShaderIMG)
 }
 
-func (p *glParams) renderTriangleStrip(paint *Paint, scissor *nvgScissor, vertexes []nvgVertex) {
+func (p *glParams) RenderTriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {
 	c := p.context
 
+	clipped := p.useClip(scissor.clip)
 	vertexCount := len(vertexes)
 	vertexOffset := c.allocVertexMemory(vertexCount)
 	callIndex := len(c.calls)
@@ -830,6 +913,7 @@ func (p *glParams) renderTriangleStrip(paint *Paint, scissor *nvgScissor, vertex
 		image:          paint.image,
 		triangleOffset: vertexOffset / 4,
 		triangleCount:  vertexCount,
+		clipped:        clipped,
 	})
 	call := &c.calls[callIndex]
 
@@ -851,12 +935,18 @@ func (p *glParams) renderTriangleStrip(paint *Paint, scissor *nvgScissor, vertex
 	f0.setType(nsvgShaderIMG)
 }
 
-func (p *glParams) renderDelete() {
+func (p *glParams) RenderDelete() {
 	c := p.context
 	c.shader.deleteShader()
 	if c.vertexBuffer.Valid() {
 		gl.DeleteBuffer(c.vertexBuffer)
 	}
+	if c.fragBuffer.Valid() {
+		gl.DeleteBuffer(c.fragBuffer)
+	}
+	if c.vertexArray != 0 {
+		gl3DeleteVertexArray(c.vertexArray)
+	}
 	for _, texture := range c.textures {
 		if texture.tex.Valid() && (texture.flags&ImageNoDelete) == 0 {
 			gl.DeleteTexture(texture.tex)
@@ -885,11 +975,11 @@ func checkError(p *glContext, str string) {
 	}
 	err := gl.GetError()
 	if err != gl.NO_ERROR {
-		dumpLog("Error %08x after %s\n", int(err), str)
+		dumpLog(fmt.Sprintf("Error %08x after %s", int(err), str))
 	}
 }
 
-func maxVertexCount(paths []nvgPath) int {
+func maxVertexCount(paths []RenderPath) int {
 	count := 0
 	for i := range paths {
 		path := &paths[i]
@@ -0,0 +1,34 @@
+//go:build gl3 && !js
+// +build gl3,!js
+
+package nanovgo
+
+import (
+	gl3 "github.com/go-gl/gl/v3.2-core/gl"
+	"github.com/goxjs/gl"
+)
+
+// github.com/goxjs/gl is OpenGL ES 2.0 level API and doesn't have vertex array object and uniform buffer.
+// GL3 mode calls them via github.com/go-gl/gl/v3.2-core/gl. It is enabled by the build tag gl3.
+
+func gl3Init() error {
+	return gl3.Init()
+}
+
+func gl3CreateVertexArray() uint32 {
+	var vertexArray uint32
+	gl3.GenVertexArrays(1, &vertexArray)
+	return vertexArray
+}
+
+func gl3BindVertexArray(vertexArray uint32) {
+	gl3.BindVertexArray(vertexArray)
+}
+
+func gl3DeleteVertexArray(vertexArray uint32) {
+	gl3.DeleteVertexArrays(1, &vertexArray)
+}
+
+func gl3BindBufferRange(target gl.Enum, index uint32, buffer gl.Buffer, offset, size int) {
+	gl3.BindBufferRange(uint32(target), index, buffer.Value, offset, size)
+}
@@ -0,0 +1,28 @@
+//go:build !gl3 || js
+// +build !gl3 js
+
+package nanovgo
+
+import (
+	"errors"
+	"github.com/goxjs/gl"
+)
+
+// Without the build tag gl3, GL backend can't use GL3 mode. See gl_backend_gl3.go.
+
+func gl3Init() error {
+	return errors.New("nanovgo: GL3 needs the build tag gl3 and github.com/go-gl/gl/v3.2-core/gl")
+}
+
+func gl3CreateVertexArray() uint32 {
+	return 0
+}
+
+func gl3BindVertexArray(vertexArray uint32) {
+}
+
+func gl3DeleteVertexArray(vertexArray uint32) {
+}
+
+func gl3BindBufferRange(target gl.Enum, index uint32, buffer gl.Buffer, offset, size int) {
+}
@@ -0,0 +1,117 @@
+package nanovgo
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestUniformBufferData(t *testing.T) {
+	c := &glContext{flags: GL3, fragSize: 256}
+	frags, _ := c.allocFragUniforms(2)
+	frags[1].reset()
+	frags[1].setType(nsvgShaderIMG)
+	frags[1][24] = 0.5
+
+	b := c.uniformBufferData()
+	if len(b) != 512 {
+		t.Fatalf("uniform buffer should have 2 aligned blocks, but %d bytes", len(b))
+	}
+	if v := math.Float32frombits(binary.LittleEndian.Uint32(b[256+24*4:])); v != 0.5 {
+		t.Errorf("innerCol of the second block should be 0.5, but %f", v)
+	}
+	if v := int32(binary.LittleEndian.Uint32(b[256+43*4:])); v != nsvgShaderIMG {
+		t.Errorf("type should be stored as int %d, but %d", nsvgShaderIMG, v)
+	}
+}
+
+func TestRenderCreateGL3WithoutBuildTag(t *testing.T) {
+	if gl3Init() == nil {
+		t.Skip("built with the build tag gl3")
+	}
+	p := &glParams{context: &glContext{flags: GL3}}
+	if err := p.RenderCreate(); err == nil {
+		t.Error("RenderCreate() should fail in GL3 mode without the build tag gl3")
+	}
+}
+
+func TestBatchCalls(t *testing.T) {
+	c := &glContext{flags: AntiAlias}
+	// 4 vertexes of a quad as a triangle strip, then a triangle
+	offset := c.allocVertexMemory(7)
+	for i := 0; i < 7; i++ {
+		c.vertexes[offset+i*4] = float32(i)
+	}
+	frags, _ := c.allocFragUniforms(3)
+	frags[2].setType(nsvgShaderIMG)
+	c.calls = []glCall{
+		{callType: glnvgTRIANGLESTRIP, triangleOffset: 0, triangleCount: 4, uniformOffset: 0},
+		{callType: glnvgTRIANGLES, triangleOffset: 4, triangleCount: 3, uniformOffset: 1},
+		{callType: glnvgTRIANGLES, triangleOffset: 4, triangleCount: 3, uniformOffset: 2},
+		{callType: glnvgFILL, pathCount: 0, uniformOffset: 0},
+	}
+	c.batchCalls()
+
+	if len(c.calls) != 3 {
+		t.Fatalf("first two calls should be merged, but %d calls", len(c.calls))
+	}
+	call := c.calls[0]
+	if call.callType != glnvgTRIANGLES || call.triangleCount != 9 {
+		t.Fatalf("merged call should be triangles with 9 vertexes, but %v", call)
+	}
+	expected := []float32{0, 1, 2, 2, 1, 3, 4, 5, 6}
+	for i, x := range expected {
+		if v := c.vertexes[(call.triangleOffset+i)*4]; v != x {
+			t.Errorf("vertex %d of merged call should be %f, but %f", i, x, v)
+		}
+	}
+	if c.calls[1].uniformOffset != 2 || c.calls[2].callType != glnvgFILL {
+		t.Error("calls with different uniforms or stencil fill should not be merged")
+	}
+	// RenderFlush() uploads the merged triangles and the vertexes of the third call only.
+	if bytes := len(castFloat32ToByte(c.vertexes)); bytes != (9+3)*4*4 {
+		t.Errorf("vertex buffer should have 12 vertexes, but %d bytes", bytes)
+	}
+	for i, x := range []float32{4, 5, 6} {
+		if v := c.vertexes[(c.calls[1].triangleOffset+i)*4]; v != x {
+			t.Errorf("vertex %d of the third call should be %f, but %f", i, x, v)
+		}
+	}
+}
+
+func TestRenderFillConvex(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		commands []float32
+		callType glnvgCallType
+	}{
+		{"rect", rectCommands(0, 0, 10, 10), glnvgCONVEXFILL},
+		{"two rects", append(rectCommands(0, 0, 10, 10), rectCommands(20, 0, 10, 10)...), glnvgFILL},
+		{"concave", []float32{
+			float32(nvgMOVETO), 0, 0,
+			float32(nvgLINETO), 0, 10,
+			float32(nvgLINETO), 10, 10,
+			float32(nvgLINETO), 10, 5,
+			float32(nvgLINETO), 5, 5,
+			float32(nvgLINETO), 5, 0,
+			float32(nvgCLOSE),
+		}, glnvgFILL},
+		{"star", []float32{
+			float32(nvgMOVETO), 10, 0,
+			float32(nvgLINETO), 16, 19,
+			float32(nvgLINETO), 0, 7,
+			float32(nvgLINETO), 20, 7,
+			float32(nvgLINETO), 4, 19,
+			float32(nvgCLOSE),
+		}, glnvgFILL},
+	} {
+		var cache nvgPathCache
+		cache.flattenPaths(testCase.commands, 0.25, 0.01, NonZero)
+		cache.expandFill(0.5, Miter, 2.4, 1.0)
+		p := &glParams{context: &glContext{flags: AntiAlias}}
+		p.RenderFill(&Paint{}, &Scissor{}, 1.0, cache.bounds, cache.paths)
+		if callType := p.context.calls[0].callType; callType != testCase.callType {
+			t.Errorf("%s should be drawn by call type %v, but %v", testCase.name, testCase.callType, callType)
+		}
+	}
+}
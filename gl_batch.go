@@ -0,0 +1,142 @@
+package nanovgo
+
+// batchable returns true if the call is drawn by only one uniform and one image without stencil,
+// so it can be drawn as a part of a triangle list.
+func (c *glContext) batchable(call *glCall) bool {
+	switch call.callType {
+	case glnvgCONVEXFILL, glnvgTRIANGLES, glnvgTRIANGLESTRIP:
+		return true
+	case glnvgSTROKE:
+		return c.flags&StencilStrokes == 0
+	}
+	return false
+}
+
+func (c *glContext) canBatch(a, b *glCall) bool {
+	return c.batchable(a) && c.batchable(b) && a.image == b.image && a.clipped == b.clipped &&
+		c.uniforms[a.uniformOffset] == c.uniforms[b.uniformOffset]
+}
+
+// batchCalls merges consecutive calls that use the same uniforms and image into one GL_TRIANGLES call.
+// Triangle fans and strips of the calls are converted into triangle lists in the same drawing order and
+// with the same winding, so the output is identical to drawing the calls one by one.
+// The vertexes are rebuilt into the other buffer which has the triangle lists instead of the vertexes of
+// the merged calls, so they are not uploaded twice.
+func (c *glContext) batchCalls() {
+	if !c.hasBatch() {
+		return
+	}
+	calls := c.calls
+	merged := c.batchedCalls[:0]
+	c.batchVertexes = c.batchVertexes[:0]
+	for i := 0; i < len(calls); {
+		j := i + 1
+		for j < len(calls) && c.canBatch(&calls[i], &calls[j]) {
+			j++
+		}
+		if j-i == 1 {
+			c.moveCallVertexes(&calls[i])
+			merged = append(merged, calls[i])
+			i++
+			continue
+		}
+		offset := len(c.batchVertexes) / 4
+		for k := i; k < j; k++ {
+			c.appendCallTriangles(&calls[k])
+		}
+		merged = append(merged, glCall{
+			callType:       glnvgTRIANGLES,
+			image:          calls[i].image,
+			triangleOffset: offset,
+			triangleCount:  len(c.batchVertexes)/4 - offset,
+			uniformOffset:  calls[i].uniformOffset,
+			clipped:        calls[i].clipped,
+		})
+		i = j
+	}
+	c.calls, c.batchedCalls = merged, calls
+	c.vertexes, c.batchVertexes = c.batchVertexes, c.vertexes
+}
+
+// hasBatch returns true if any calls can be merged.
+func (c *glContext) hasBatch() bool {
+	for i := 1; i < len(c.calls); i++ {
+		if c.canBatch(&c.calls[i-1], &c.calls[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// moveCallVertexes copies the vertexes of the call which is not merged into the batch buffer and updates
+// the offsets of the call and its paths.
+func (c *glContext) moveCallVertexes(call *glCall) {
+	paths := c.paths[call.pathOffset : call.pathOffset+call.pathCount]
+	for i := range paths {
+		paths[i].fillOffset = c.moveVertexes(paths[i].fillOffset, paths[i].fillCount)
+		paths[i].strokeOffset = c.moveVertexes(paths[i].strokeOffset, paths[i].strokeCount)
+	}
+	call.triangleOffset = c.moveVertexes(call.triangleOffset, call.triangleCount)
+}
+
+func (c *glContext) moveVertexes(offset, count int) int {
+	if count == 0 {
+		return 0
+	}
+	newOffset := len(c.batchVertexes) / 4
+	c.batchVertexes = append(c.batchVertexes, c.vertexes[offset*4:(offset+count)*4]...)
+	return newOffset
+}
+
+// appendCallTriangles appends the triangles drawn by the call to the batch buffer as a triangle list.
+func (c *glContext) appendCallTriangles(call *glCall) {
+	switch call.callType {
+	case glnvgCONVEXFILL:
+		paths := c.paths[call.pathOffset : call.pathOffset+call.pathCount]
+		for i := range paths {
+			c.appendFan(paths[i].fillOffset, paths[i].fillCount)
+		}
+		if c.flags&AntiAlias != 0 {
+			for i := range paths {
+				c.appendStrip(paths[i].strokeOffset, paths[i].strokeCount)
+			}
+		}
+	case glnvgSTROKE:
+		paths := c.paths[call.pathOffset : call.pathOffset+call.pathCount]
+		for i := range paths {
+			c.appendStrip(paths[i].strokeOffset, paths[i].strokeCount)
+		}
+	case glnvgTRIANGLES:
+		for i := 0; i < call.triangleCount; i++ {
+			c.appendVertex(call.triangleOffset + i)
+		}
+	case glnvgTRIANGLESTRIP:
+		c.appendStrip(call.triangleOffset, call.triangleCount)
+	}
+}
+
+func (c *glContext) appendVertex(index int) {
+	c.batchVertexes = append(c.batchVertexes, c.vertexes[index*4:index*4+4]...)
+}
+
+func (c *glContext) appendFan(offset, count int) {
+	for i := 2; i < count; i++ {
+		c.appendVertex(offset)
+		c.appendVertex(offset + i - 1)
+		c.appendVertex(offset + i)
+	}
+}
+
+func (c *glContext) appendStrip(offset, count int) {
+	for i := 0; i+2 < count; i++ {
+		// Odd triangles of the strip are reversed to keep the winding.
+		if i%2 == 0 {
+			c.appendVertex(offset + i)
+			c.appendVertex(offset + i + 1)
+		} else {
+			c.appendVertex(offset + i + 1)
+			c.appendVertex(offset + i)
+		}
+		c.appendVertex(offset + i + 2)
+	}
+}
@@ -0,0 +1,160 @@
+package nanovgo
+
+import (
+	"github.com/goxjs/gl"
+)
+
+// The clip region is kept in the highest bit of the stencil buffer (glnvgClipBit) and the other bits are
+// used to count the winding of fills as before. The clip bits are drawn by glnvgCLIP calls only when
+// the clip path of the calls is changed.
+
+// clipStencil returns the stencil reference and mask bits to test the clip region.
+func (call *glCall) clipStencil() (int, uint32) {
+	if call.clipped {
+		return glnvgClipBit, glnvgClipBit
+	}
+	return 0, 0
+}
+
+// enableClipTest enables the stencil test of the clip region for the calls drawn without stencil.
+func (c *glContext) enableClipTest(call *glCall) {
+	if call.clipped {
+		gl.Enable(gl.STENCIL_TEST)
+		c.setStencilFunc(gl.EQUAL, glnvgClipBit, glnvgClipBit)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+	}
+}
+
+func (c *glContext) disableClipTest(call *glCall) {
+	if call.clipped {
+		gl.Disable(gl.STENCIL_TEST)
+	}
+}
+
+func (c *glContext) clip(call *glCall) {
+	viewportOffset := call.triangleOffset
+	boundsOffset := call.triangleOffset + 6
+
+	gl.Enable(gl.STENCIL_TEST)
+	gl.ColorMask(false, false, false, false)
+	c.setUniforms(call.uniformOffset, 0)
+	checkError(c, "clip")
+
+	if !call.clipped {
+		// Set the clip bit on the whole viewport
+		c.setStencilMask(glnvgClipBit)
+		c.setStencilFunc(gl.ALWAYS, glnvgClipBit, 0xff)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+		c.drawArrays(gl.TRIANGLES, viewportOffset, 6)
+	}
+
+	// Count the winding of the clip path
+	c.setStencilMask(glnvgStencilBits)
+	c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+	if call.fillRule == EvenOdd {
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.INVERT)
+	} else {
+		gl.StencilOpSeparate(gl.FRONT, gl.KEEP, gl.KEEP, gl.INCR_WRAP)
+		gl.StencilOpSeparate(gl.BACK, gl.KEEP, gl.KEEP, gl.DECR_WRAP)
+	}
+	gl.Disable(gl.CULL_FACE)
+	for i := call.pathOffset; i < call.pathOffset+call.pathCount; i++ {
+		path := &c.paths[i]
+		c.drawArrays(gl.TRIANGLE_FAN, path.fillOffset, path.fillCount)
+	}
+	gl.Enable(gl.CULL_FACE)
+
+	// Clear the clip bit out of the clip path
+	c.setStencilMask(glnvgClipBit)
+	c.setStencilFunc(gl.EQUAL, 0x00, glnvgStencilBits)
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.ZERO)
+	c.drawArrays(gl.TRIANGLES, viewportOffset, 6)
+
+	// Clear the winding
+	c.setStencilMask(glnvgStencilBits)
+	c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+	gl.StencilOp(gl.ZERO, gl.ZERO, gl.ZERO)
+	c.drawArrays(gl.TRIANGLES, boundsOffset, 6)
+
+	gl.ColorMask(true, true, true, true)
+	gl.Disable(gl.STENCIL_TEST)
+}
+
+// useClip adds the calls to draw the clip region if it is changed from the last call, and returns true if
+// the next call should be clipped.
+func (p *glParams) useClip(clip *ClipPath) bool {
+	c := p.context
+	if clip == c.clipPath {
+		return clip != nil
+	}
+	// Only the clip paths after the current one are drawn if the current one is a parent of the new one.
+	var chain []*ClipPath
+	intersect := false
+	for parent := clip; parent != nil; parent = parent.parent {
+		if parent == c.clipPath {
+			intersect = true
+			break
+		}
+		chain = append(chain, parent)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		p.appendClipCall(chain[i], intersect)
+		intersect = true
+	}
+	c.clipPath = clip
+	return clip != nil
+}
+
+func (p *glParams) appendClipCall(clip *ClipPath, intersect bool) {
+	c := p.context
+	var glPaths []glPath
+	c.calls = append(c.calls, glCall{
+		callType:  glnvgCLIP,
+		pathCount: len(clip.paths),
+		fillRule:  clip.fillRule,
+		clipped:   intersect,
+	})
+	call := &c.calls[len(c.calls)-1]
+	glPaths, call.pathOffset = c.allocPath(call.pathCount)
+
+	vertexOffset := c.allocVertexMemory(maxVertexCount(clip.paths) + 12)
+	for i := range clip.paths {
+		fills := clip.paths[i].fills
+		glPaths[i].fillOffset = vertexOffset / 4
+		glPaths[i].fillCount = len(fills)
+		for j := range fills {
+			vertex := &fills[j]
+			c.vertexes[vertexOffset] = vertex.x
+			c.vertexes[vertexOffset+1] = vertex.y
+			c.vertexes[vertexOffset+2] = vertex.u
+			c.vertexes[vertexOffset+3] = vertex.v
+			vertexOffset += 4
+		}
+	}
+
+	// Quads of the viewport and the clip path
+	call.triangleOffset = vertexOffset / 4
+	call.triangleCount = 12
+	setQuadVertexes(c.vertexes[vertexOffset:], [4]float32{0, 0, c.view[0], c.view[1]})
+	setQuadVertexes(c.vertexes[vertexOffset+24:], clip.bounds)
+
+	// Simple shader for stencil
+	var uniforms []glFragUniforms
+	uniforms, call.uniformOffset = c.allocFragUniforms(1)
+	u0 := &uniforms[0]
+	u0.reset()
+	u0.setStrokeThr(-1.0)
+	u0.setType(nsvgShaderSIMPLE)
+}
+
+// setQuadVertexes sets two front facing triangles of the bounds (minX, minY, maxX, maxY).
+func setQuadVertexes(dst []float32, bounds [4]float32) {
+	copy(dst, []float32{
+		bounds[0], bounds[3], 0.5, 1.0,
+		bounds[2], bounds[3], 0.5, 1.0,
+		bounds[2], bounds[1], 0.5, 1.0,
+		bounds[0], bounds[3], 0.5, 1.0,
+		bounds[2], bounds[1], 0.5, 1.0,
+		bounds[0], bounds[1], 0.5, 1.0,
+	})
+}
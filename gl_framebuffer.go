@@ -0,0 +1,118 @@
+package nanovgo
+
+import (
+	"errors"
+	"github.com/goxjs/gl"
+)
+
+// Framebuffer is an offscreen render target of GL backend like nvgluCreateFramebuffer() of NanoVG.
+// Its color buffer is an image of the context, so the result can be drawn with ImagePattern() via Image().
+//
+//	fb, _ := ctx.CreateFramebuffer(256, 256, 0)
+//	fb.Bind()
+//	gl.Viewport(0, 0, 256, 256)
+//	gl.Clear(gl.COLOR_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+//	ctx.BeginFrame(256, 256, 1.0)
+//	// draw layer
+//	ctx.EndFrame()
+//	fb.Unbind()
+//
+// Unbind() binds the framebuffer that was bound when Bind() was called, so it works with the platforms whose
+// default framebuffer is not 0 like iOS.
+type Framebuffer struct {
+	ctx     *Context
+	fbo     gl.Framebuffer
+	rbo     gl.Renderbuffer
+	texture gl.Texture
+	image   int
+	width   int
+	height  int
+	prevFBO gl.Framebuffer // framebuffer bound before Bind()
+}
+
+// The functions to bind the framebuffer. They are replaced to test the binding without GL context.
+var (
+	bindFramebuffer  = gl.BindFramebuffer
+	boundFramebuffer = gl.GetBoundFramebuffer
+)
+
+// CreateFramebuffer creates framebuffer with the color image and the stencil buffer. The image is created with
+// ImageFlippy and ImagePreMultiplied flags in addition to imageFlags, because GL renders it upside down
+// with premultiplied alpha. It returns error if the context doesn't use GL backend.
+func (c *Context) CreateFramebuffer(w, h int, imageFlags ImageFlags) (*Framebuffer, error) {
+	params, ok := c.params.(*glParams)
+	if !ok {
+		return nil, errors.New("framebuffer is supported only by GL backend")
+	}
+	image, err := c.CreateImageRGBAE(w, h, imageFlags|ImageFlippy|ImagePreMultiplied, nil)
+	if err != nil {
+		return nil, err
+	}
+	tex := params.context.findTexture(image)
+	fb := &Framebuffer{
+		ctx:     c,
+		texture: tex.tex,
+		image:   image,
+		width:   w,
+		height:  h,
+	}
+
+	prevFBO := boundFramebuffer()
+	fb.fbo = gl.CreateFramebuffer()
+	bindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+	fb.rbo = gl.CreateRenderbuffer()
+	gl.BindRenderbuffer(gl.RENDERBUFFER, fb.rbo)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.STENCIL_INDEX8, w, h)
+
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, fb.texture, 0)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.STENCIL_ATTACHMENT, gl.RENDERBUFFER, fb.rbo)
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, gl.Renderbuffer{})
+	bindFramebuffer(gl.FRAMEBUFFER, prevFBO)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		fb.Delete()
+		return nil, errors.New("framebuffer is not complete")
+	}
+	return fb, nil
+}
+
+// Bind makes the framebuffer the target of the following drawing. The current framebuffer is saved for Unbind().
+func (fb *Framebuffer) Bind() {
+	if prevFBO := boundFramebuffer(); prevFBO != fb.fbo {
+		fb.prevFBO = prevFBO
+	}
+	bindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+}
+
+// Unbind makes the framebuffer bound before Bind() the target of the following drawing.
+func (fb *Framebuffer) Unbind() {
+	bindFramebuffer(gl.FRAMEBUFFER, fb.prevFBO)
+}
+
+// Image returns the image handle of the color buffer.
+func (fb *Framebuffer) Image() int {
+	return fb.image
+}
+
+// Size returns the size of the framebuffer.
+func (fb *Framebuffer) Size() (int, int) {
+	return fb.width, fb.height
+}
+
+// Delete deletes the framebuffer, the stencil buffer and the color image.
+func (fb *Framebuffer) Delete() {
+	if fb.fbo.Valid() {
+		gl.DeleteFramebuffer(fb.fbo)
+		fb.fbo = gl.Framebuffer{}
+	}
+	if fb.rbo.Valid() {
+		gl.DeleteRenderbuffer(fb.rbo)
+		fb.rbo = gl.Renderbuffer{}
+	}
+	if fb.image != 0 {
+		fb.ctx.DeleteImage(fb.image)
+		fb.image = 0
+	}
+	fb.texture = gl.Texture{}
+}
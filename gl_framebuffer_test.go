@@ -0,0 +1,56 @@
+//go:build !js
+// +build !js
+
+package nanovgo
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goxjs/gl"
+)
+
+func TestFramebufferRequiresGL(t *testing.T) {
+	c, err := NewSoftwareContext(image.NewRGBA(image.Rect(0, 0, 16, 16)), 0)
+	if err != nil {
+		t.Fatalf("NewSoftwareContext() should succeed, but %v", err)
+	}
+	defer c.Delete()
+
+	if fb, err := c.CreateFramebuffer(16, 16, 0); err == nil || fb != nil {
+		t.Error("CreateFramebuffer() should fail without GL backend")
+	}
+}
+
+// TestFramebufferBinding builds the framebuffer values with the Value field of the native binding.
+func TestFramebufferBinding(t *testing.T) {
+	defaultFBO := gl.Framebuffer{Value: 7} // default framebuffer of iOS is not 0
+	current := defaultFBO
+	bindFramebuffer = func(target gl.Enum, fb gl.Framebuffer) {
+		current = fb
+	}
+	boundFramebuffer = func() gl.Framebuffer {
+		return current
+	}
+	defer func() {
+		bindFramebuffer = gl.BindFramebuffer
+		boundFramebuffer = gl.GetBoundFramebuffer
+	}()
+
+	fb1 := &Framebuffer{fbo: gl.Framebuffer{Value: 1}}
+	fb2 := &Framebuffer{fbo: gl.Framebuffer{Value: 2}}
+	fb1.Bind()
+	fb1.Bind()
+	fb2.Bind()
+	if current != fb2.fbo {
+		t.Errorf("framebuffer should be bound, but %v", current)
+	}
+	fb2.Unbind()
+	if current != fb1.fbo {
+		t.Errorf("Unbind() should restore the outer framebuffer, but %v", current)
+	}
+	fb1.Unbind()
+	if current != defaultFBO {
+		t.Errorf("Unbind() should restore the default framebuffer, but %v", current)
+	}
+}
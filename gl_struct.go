@@ -20,6 +20,13 @@ const (
 	glnvgSTROKE
 	glnvgTRIANGLES
 	glnvgTRIANGLESTRIP
+	glnvgCLIP
+)
+
+// The stencil buffer is shared by the fill winding and the clip region.
+const (
+	glnvgStencilBits = 0x7f // stencil bits to count the winding
+	glnvgClipBit     = 0x80 // stencil bit set in the clip region
 )
 
 type glCall struct {
@@ -30,6 +37,8 @@ type glCall struct {
 	triangleOffset int
 	triangleCount  int
 	uniformOffset  int
+	fillRule       FillRule
+	clipped        bool // The call is clipped by the clip region. glnvgCLIP call intersects the current region.
 }
 
 type glPath struct {
@@ -107,10 +116,55 @@ func (u *glFragUniforms) setType(typeCode float32) {
 	u[43] = typeCode
 }
 
+func (u *glFragUniforms) convertPaint(paint *Paint, scissor *Scissor, width, fringe, strokeThr float32, texType TextureType, texFlags ImageFlags) {
+	u.setInnerColor(paint.innerColor.PreMultiply())
+	u.setOuterColor(paint.outerColor.PreMultiply())
+
+	if scissor.extent[0] < -0.5 || scissor.extent[1] < -0.5 {
+		u.clearScissorMat()
+		u.setScissorExt(1.0, 1.0)
+		u.setScissorScale(1.0, 1.0)
+	} else {
+		xform := &scissor.xform
+		u.setScissorMat(xform.Inverse().ToMat3x4())
+		u.setScissorExt(scissor.extent[0], scissor.extent[1])
+		scaleX := sqrtF(xform[0]*xform[0]+xform[2]*xform[2]) / fringe
+		scaleY := sqrtF(xform[1]*xform[1]+xform[3]*xform[3]) / fringe
+		u.setScissorScale(scaleX, scaleY)
+	}
+	u.setExtent(paint.extent)
+	u.setStrokeMult((width*0.5 + fringe*0.5) / fringe)
+	u.setStrokeThr(strokeThr)
+
+	if paint.image != 0 {
+		if texFlags&ImageFlippy != 0 {
+			u.setPaintMat(ScaleMatrix(1.0, -1.0).Multiply(paint.xform).Inverse().ToMat3x4())
+		} else {
+			u.setPaintMat(paint.xform.Inverse().ToMat3x4())
+		}
+		u.setType(nsvgShaderFILLIMG)
+
+		if texType == TextureRGBA {
+			if texFlags&ImagePreMultiplied != 0 {
+				u.setTexType(0)
+			} else {
+				u.setTexType(1)
+			}
+		} else {
+			u.setTexType(2)
+		}
+	} else {
+		u.setType(nsvgShaderFILLGRAD)
+		u.setRadius(paint.radius)
+		u.setFeather(paint.feather)
+		u.setPaintMat(paint.xform.Inverse().ToMat3x4())
+	}
+}
+
 type glTexture struct {
 	id            int
 	tex           gl.Texture
 	width, height int
-	texType       nvgTextureType
+	texType       TextureType
 	flags         ImageFlags
 }
@@ -0,0 +1,178 @@
+package nanovgo
+
+// IsPointInFill returns true if the point is inside of the current path as it is filled by Fill().
+// The point is in the window coordinates (like mouse position), and the path is tested after applying
+// the transforms used when it was built. The current fill rule and sub-path windings are used as same as Fill().
+// Anti-aliasing fringe is not included.
+func (c *Context) IsPointInFill(x, y float32) bool {
+	c.flattenPaths()
+	winding := 0
+	for i := range c.cache.paths {
+		path := &c.cache.paths[i]
+		points := c.cache.points[path.first : path.first+path.count]
+		winding += windingNumber(points, x, y)
+	}
+	if c.getState().fillRule == EvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// IsPointInStroke returns true if the point is on the current path as it is stroked by Stroke() with
+// the current stroke width, stroke align, line cap, line join and miter limit. The point is in the window coordinates.
+// Dash pattern and anti-aliasing fringe are not included.
+func (c *Context) IsPointInStroke(x, y float32) bool {
+	state := c.getState()
+	c.flattenPaths()
+	hw := clampF(state.strokeWidth*state.xform.getAverageScale(), 0.0, 200.0) * 0.5
+	if hw <= 0 {
+		return false
+	}
+	for i := range c.cache.paths {
+		path := &c.cache.paths[i]
+		points := c.cache.points[path.first : path.first+path.count]
+		if len(points) < 2 {
+			continue
+		}
+		if path.closed && state.strokeAlign != Center {
+			// The aligned stroke is the one side of the stroke with the double width.
+			if isOutside(points, x, y) == (state.strokeAlign == Outside) &&
+				hitStroke(points, true, x, y, hw*2, state.lineCap, state.lineJoin, state.miterLimit) {
+				return true
+			}
+			continue
+		}
+		if hitStroke(points, path.closed, x, y, hw, state.lineCap, state.lineJoin, state.miterLimit) {
+			return true
+		}
+	}
+	return false
+}
+
+// windingNumber returns the winding number of the closed polygon around the point.
+func windingNumber(points []nvgPoint, x, y float32) int {
+	winding := 0
+	for i := range points {
+		p0 := &points[i]
+		p1 := &points[(i+1)%len(points)]
+		if p0.y <= y {
+			if p1.y > y && cross(p1.x-p0.x, p1.y-p0.y, x-p0.x, y-p0.y) > 0 {
+				winding++
+			}
+		} else if p1.y <= y && cross(p1.x-p0.x, p1.y-p0.y, x-p0.x, y-p0.y) < 0 {
+			winding--
+		}
+	}
+	return winding
+}
+
+// isOutside returns true if the point is on the outside of the closed path as it is a Solid path.
+// Hole paths are in the opposite direction, so the inside of them is the outside.
+func isOutside(points []nvgPoint, x, y float32) bool {
+	inside := windingNumber(points, x, y) != 0
+	return inside == (polyArea(points, len(points)) < 0.0)
+}
+
+func hitStroke(points []nvgPoint, closed bool, x, y, hw float32, lineCap, lineJoin LineCap, miterLimit float32) bool {
+	count := len(points)
+	segments := count - 1
+	if closed {
+		segments = count
+	}
+	// Segments with square caps at the open ends
+	for i := 0; i < segments; i++ {
+		p0 := &points[i]
+		p1 := &points[(i+1)%count]
+		var ext0, ext1 float32
+		if !closed && lineCap == Square {
+			if i == 0 {
+				ext0 = hw
+			}
+			if i == segments-1 {
+				ext1 = hw
+			}
+		}
+		if hitSegment(p0.x, p0.y, p1.x, p1.y, x, y, hw, ext0, ext1) {
+			return true
+		}
+	}
+	// Round caps
+	if !closed && lineCap == Round {
+		first := &points[0]
+		last := &points[count-1]
+		if distSquare(first.x, first.y, x, y) <= hw*hw || distSquare(last.x, last.y, x, y) <= hw*hw {
+			return true
+		}
+	}
+	// Joins
+	for i := 0; i < count; i++ {
+		if !closed && (i == 0 || i == count-1) {
+			continue
+		}
+		p0 := &points[(i+count-1)%count]
+		p1 := &points[i]
+		p2 := &points[(i+1)%count]
+		if hitJoin(p0, p1, p2, x, y, hw, lineJoin, miterLimit) {
+			return true
+		}
+	}
+	return false
+}
+
+// hitSegment tests the rectangle around the segment. ext0 and ext1 extend the rectangle at the ends.
+func hitSegment(x0, y0, x1, y1, px, py, hw, ext0, ext1 float32) bool {
+	length, dx, dy := normalize(x1-x0, y1-y0)
+	if length == 0 {
+		return false
+	}
+	// position along the segment and the distance from it
+	t := (px-x0)*dx + (py-y0)*dy
+	d := (px-x0)*dy - (py-y0)*dx
+	return t >= -ext0 && t <= length+ext1 && absF(d) <= hw
+}
+
+func hitJoin(p0, p1, p2 *nvgPoint, x, y, hw float32, lineJoin LineCap, miterLimit float32) bool {
+	_, dx0, dy0 := normalize(p1.x-p0.x, p1.y-p0.y)
+	_, dx1, dy1 := normalize(p2.x-p1.x, p2.y-p1.y)
+	corner := p1.flags&nvgPtCORNER != 0
+	if corner && lineJoin == Round {
+		return distSquare(p1.x, p1.y, x, y) <= hw*hw
+	}
+	// Normals at the outer side of the corner
+	nx0, ny0 := dy0, -dx0
+	nx1, ny1 := dy1, -dx1
+	if nx0*dx1+ny0*dy1 > 0 {
+		nx0, ny0, nx1, ny1 = -nx0, -ny0, -nx1, -ny1
+	}
+	ax, ay := p1.x+nx0*hw, p1.y+ny0*hw
+	bx, by := p1.x+nx1*hw, p1.y+ny1*hw
+	if hitTriangle(p1.x, p1.y, ax, ay, bx, by, x, y) {
+		return true
+	}
+	if corner && lineJoin == Bevel {
+		return false
+	}
+	// Miter join. Non-corner points of curves are always joined with miter.
+	dmx := (nx0 + nx1) * 0.5
+	dmy := (ny0 + ny1) * 0.5
+	dmr2 := dmx*dmx + dmy*dmy
+	if dmr2 < 1e-6 || (corner && dmr2*miterLimit*miterLimit < 1.0) {
+		return false
+	}
+	mx := p1.x + dmx/dmr2*hw
+	my := p1.y + dmy/dmr2*hw
+	return hitTriangle(ax, ay, mx, my, bx, by, x, y)
+}
+
+func hitTriangle(ax, ay, bx, by, cx, cy, x, y float32) bool {
+	d0 := cross(bx-ax, by-ay, x-ax, y-ay)
+	d1 := cross(cx-bx, cy-by, x-bx, y-by)
+	d2 := cross(ax-cx, ay-cy, x-cx, y-cy)
+	return (d0 >= 0 && d1 >= 0 && d2 >= 0) || (d0 <= 0 && d1 <= 0 && d2 <= 0)
+}
+
+func distSquare(x0, y0, x1, y1 float32) float32 {
+	dx := x1 - x0
+	dy := y1 - y0
+	return dx*dx + dy*dy
+}
@@ -0,0 +1,119 @@
+package nanovgo
+
+import (
+	"testing"
+)
+
+func newHitTestContext() *Context {
+	c := &Context{tessTol: 0.25, distTol: 0.01}
+	c.Save()
+	c.Reset()
+	return c
+}
+
+func TestIsPointInFill(t *testing.T) {
+	c := newHitTestContext()
+	c.Translate(10, 10)
+	c.BeginPath()
+	c.Rect(0, 0, 40, 40)
+	c.Circle(20, 20, 10)
+	c.PathWinding(Hole)
+
+	for _, testCase := range []struct {
+		x, y     float32
+		expected bool
+	}{
+		{12, 12, true},
+		{5, 5, false},
+		{30, 30, false}, // in the hole
+		{45, 30, true},
+		{51, 30, false},
+	} {
+		if c.IsPointInFill(testCase.x, testCase.y) != testCase.expected {
+			t.Errorf("IsPointInFill(%v, %v) should be %v", testCase.x, testCase.y, testCase.expected)
+		}
+	}
+}
+
+func TestIsPointInFillRule(t *testing.T) {
+	c := newHitTestContext()
+	c.BeginPath()
+	c.Rect(0, 0, 20, 20)
+	c.Rect(10, 10, 20, 20)
+	if !c.IsPointInFill(15, 15) {
+		t.Error("overlapped area should be inside with NonZero")
+	}
+	c.SetFillRule(EvenOdd)
+	if c.IsPointInFill(15, 15) || !c.IsPointInFill(5, 5) || !c.IsPointInFill(25, 25) {
+		t.Error("only the area surrounded by odd number of sub-paths should be inside with EvenOdd")
+	}
+}
+
+func TestIsPointInStroke(t *testing.T) {
+	c := newHitTestContext()
+	c.BeginPath()
+	c.MoveTo(10, 10)
+	c.LineTo(50, 10)
+	c.LineTo(50, 50)
+	c.SetStrokeWidth(4)
+
+	check := func(x, y float32, expected bool, message string) {
+		if c.IsPointInStroke(x, y) != expected {
+			t.Errorf("IsPointInStroke(%v, %v) should be %v: %s", x, y, expected, message)
+		}
+	}
+	check(30, 11.5, true, "on the line")
+	check(30, 13, false, "outside of the stroke width")
+	check(8.5, 10, false, "butt cap")
+	check(51.5, 8.5, true, "miter join")
+	c.SetLineJoin(Round)
+	check(51.8, 8.2, false, "round join")
+	c.SetLineCap(Square)
+	check(8.5, 10, true, "square cap")
+	c.SetLineCap(Round)
+	check(8.5, 11, true, "round cap")
+	check(8.3, 8.3, false, "round cap corner")
+
+	c.Scale(2, 2)
+	check(30, 13, true, "stroke width is scaled by the transform")
+}
+
+func TestIsPointInStrokeAlign(t *testing.T) {
+	c := newHitTestContext()
+	c.BeginPath()
+	c.Rect(10, 10, 40, 40)
+	c.Circle(30, 30, 10)
+	c.PathWinding(Hole)
+	c.SetStrokeWidth(4)
+
+	for _, testCase := range []struct {
+		align    StrokeAlign
+		x, y     float32
+		expected bool
+		message  string
+	}{
+		{Center, 8.5, 30, true, "center"},
+		{Center, 11.5, 30, true, "center"},
+		{Inside, 13, 30, true, "inside"},
+		{Inside, 9, 30, false, "outside of the inside stroke"},
+		{Inside, 30, 18.5, true, "inside of the solid path is outside of the hole"},
+		{Inside, 30, 21.5, false, "inside of the hole"},
+		{Outside, 7, 30, true, "outside"},
+		{Outside, 11, 30, false, "inside of the outside stroke"},
+		{Outside, 7, 7, true, "miter join"},
+		{Outside, 30, 23, true, "outside of the hole is inside of the hole"},
+	} {
+		c.SetStrokeAlign(testCase.align)
+		if c.IsPointInStroke(testCase.x, testCase.y) != testCase.expected {
+			t.Errorf("IsPointInStroke(%v, %v) should be %v: %s", testCase.x, testCase.y, testCase.expected, testCase.message)
+		}
+	}
+
+	c.SetStrokeAlign(Inside)
+	c.BeginPath()
+	c.MoveTo(10, 10)
+	c.LineTo(50, 10)
+	if !c.IsPointInStroke(30, 8.5) || !c.IsPointInStroke(30, 11.5) {
+		t.Error("open paths should be stroked at center")
+	}
+}
@@ -0,0 +1,145 @@
+package nanovgo
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// ImageDecodeError is returned when the image data is broken.
+type ImageDecodeError struct {
+	Path string // File path of the image. It is empty if the image is loaded from memory.
+	Err  error
+}
+
+func (e *ImageDecodeError) Error() string {
+	if e.Path == "" {
+		return "nanovgo: can't decode image: " + e.Err.Error()
+	}
+	return fmt.Sprintf("nanovgo: can't decode image %s: %v", e.Path, e.Err)
+}
+
+// ImageFormatError is returned when the image format is not supported. Import the decoder package
+// (like image/gif) to support more formats.
+type ImageFormatError struct {
+	Path string // File path of the image. It is empty if the image is loaded from memory.
+}
+
+func (e *ImageFormatError) Error() string {
+	if e.Path == "" {
+		return "nanovgo: unsupported image format"
+	}
+	return "nanovgo: unsupported image format: " + e.Path
+}
+
+// ImageSizeError is returned when the image is empty or larger than the maximum texture size of the renderer.
+type ImageSizeError struct {
+	Width, Height int
+	MaxSize       int // 0 if the renderer doesn't have the limit.
+}
+
+func (e *ImageSizeError) Error() string {
+	if e.MaxSize == 0 {
+		return fmt.Sprintf("nanovgo: invalid image size %dx%d", e.Width, e.Height)
+	}
+	return fmt.Sprintf("nanovgo: image size %dx%d exceeds maximum texture size %d", e.Width, e.Height, e.MaxSize)
+}
+
+// ImageDataError is returned when the pixel data is shorter than the image size.
+type ImageDataError struct {
+	Width, Height int
+	Length        int // Length of the pixel data in bytes.
+}
+
+func (e *ImageDataError) Error() string {
+	return fmt.Sprintf("nanovgo: image data of %d bytes is too short for %dx%d RGBA image", e.Length, e.Width, e.Height)
+}
+
+// ImageAllocError is returned when the renderer fails to create the texture.
+type ImageAllocError struct {
+	Width, Height int
+}
+
+func (e *ImageAllocError) Error() string {
+	return fmt.Sprintf("nanovgo: can't create texture %dx%d", e.Width, e.Height)
+}
+
+// CreateImageE creates image by loading it from the disk from specified file name.
+// Returns handle to the image. The error is *os.PathError, *ImageDecodeError, *ImageFormatError,
+// *ImageSizeError or *ImageAllocError.
+func (c *Context) CreateImageE(filePath string, flags ImageFlags) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	img, err := decodeImage(file, filePath)
+	if err != nil {
+		return 0, err
+	}
+	return c.CreateImageFromGoImageE(flags, img)
+}
+
+// CreateImageFromMemoryE creates image by loading it from the specified chunk of memory.
+// Returns handle to the image. The error is *ImageDecodeError, *ImageFormatError, *ImageSizeError or *ImageAllocError.
+func (c *Context) CreateImageFromMemoryE(flags ImageFlags, data []byte) (int, error) {
+	img, err := decodeImage(bytes.NewReader(data), "")
+	if err != nil {
+		return 0, err
+	}
+	return c.CreateImageFromGoImageE(flags, img)
+}
+
+// CreateImageFromGoImageE creates image by loading it from the specified image.Image object.
+// Returns handle to the image. The error is *ImageSizeError or *ImageAllocError.
+func (c *Context) CreateImageFromGoImageE(imageFlag ImageFlags, img image.Image) (int, error) {
+	bounds := img.Bounds()
+	size := bounds.Size()
+	rgba, ok := img.(*image.RGBA)
+	if ok && rgba.Stride == size.X*4 {
+		return c.CreateImageRGBAE(size.X, size.Y, imageFlag, rgba.Pix)
+	}
+	rgba = image.NewRGBA(bounds)
+	for x := 0; x < size.X; x++ {
+		for y := 0; y < size.Y; y++ {
+			rgba.Set(bounds.Min.X+x, bounds.Min.Y+y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return c.CreateImageRGBAE(size.X, size.Y, imageFlag, rgba.Pix)
+}
+
+// CreateImageRGBAE creates image from specified image data.
+// Returns handle to the image. The error is *ImageSizeError, *ImageDataError or *ImageAllocError.
+func (c *Context) CreateImageRGBAE(w, h int, imageFlags ImageFlags, data []byte) (int, error) {
+	if w <= 0 || h <= 0 {
+		return 0, &ImageSizeError{Width: w, Height: h}
+	}
+	if data != nil && len(data) < w*h*4 {
+		return 0, &ImageDataError{Width: w, Height: h, Length: len(data)}
+	}
+	if limiter, ok := c.params.(TextureSizeLimiter); ok {
+		if maxSize := limiter.RenderMaxTextureSize(); maxSize > 0 && (w > maxSize || h > maxSize) {
+			return 0, &ImageSizeError{Width: w, Height: h, MaxSize: maxSize}
+		}
+	}
+	img := c.params.RenderCreateTexture(TextureRGBA, w, h, imageFlags, data)
+	if img == 0 {
+		return 0, &ImageAllocError{Width: w, Height: h}
+	}
+	if data != nil {
+		c.textureUploads++
+	}
+	return img, nil
+}
+
+func decodeImage(reader io.Reader, path string) (image.Image, error) {
+	img, _, err := image.Decode(reader)
+	if err == image.ErrFormat {
+		return nil, &ImageFormatError{Path: path}
+	} else if err != nil {
+		return nil, &ImageDecodeError{Path: path, Err: err}
+	}
+	return img, nil
+}
@@ -0,0 +1,80 @@
+package nanovgo
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+)
+
+type limitedParams struct {
+	*softParams
+	maxSize int
+}
+
+func (p *limitedParams) RenderMaxTextureSize() int {
+	return p.maxSize
+}
+
+func TestCreateImageErrors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+
+	if _, err := c.CreateImageE("not-exist.png", 0); !os.IsNotExist(err) {
+		t.Errorf("CreateImageE() should return not exist error, but %v", err)
+	}
+	if _, err := c.CreateImageFromMemoryE(0, []byte("not an image")); err == nil {
+		t.Error("CreateImageFromMemoryE() should fail for unknown data")
+	} else if _, ok := err.(*ImageFormatError); !ok {
+		t.Errorf("CreateImageFromMemoryE() should return ImageFormatError, but %T", err)
+	}
+
+	var buffer bytes.Buffer
+	png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	broken := buffer.Bytes()[:buffer.Len()/2]
+	if _, err := c.CreateImageFromMemoryE(0, broken); err == nil {
+		t.Error("CreateImageFromMemoryE() should fail for broken png")
+	} else if _, ok := err.(*ImageDecodeError); !ok {
+		t.Errorf("CreateImageFromMemoryE() should return ImageDecodeError, but %T", err)
+	}
+	if handle := c.CreateImageFromMemory(0, broken); handle != 0 {
+		t.Errorf("CreateImageFromMemory() should return 0 for broken png, but %d", handle)
+	}
+
+	handle, err := c.CreateImageFromMemoryE(0, buffer.Bytes())
+	if err != nil || handle == 0 {
+		t.Errorf("CreateImageFromMemoryE() should succeed, but %v", err)
+	}
+	if _, err := c.CreateImageRGBAE(0, 4, 0, nil); err == nil {
+		t.Error("CreateImageRGBAE() should fail for empty image")
+	}
+	if _, err := c.CreateImageRGBAE(4, 4, 0, make([]byte, 4*4*4-1)); err == nil {
+		t.Error("CreateImageRGBAE() should fail for short data")
+	} else if dataErr, ok := err.(*ImageDataError); !ok || dataErr.Length != 63 {
+		t.Errorf("CreateImageRGBAE() should return ImageDataError, but %v", err)
+	}
+}
+
+func TestCreateImageMaxTextureSize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	params := &limitedParams{
+		softParams: &softParams{context: &softContext{target: img}},
+		maxSize:    8,
+	}
+	c, _ := createInternal(params, 0)
+	defer c.Delete()
+
+	_, err := c.CreateImageRGBAE(16, 4, 0, make([]byte, 16*4*4))
+	sizeErr, ok := err.(*ImageSizeError)
+	if !ok {
+		t.Fatalf("CreateImageRGBAE() should return ImageSizeError, but %v", err)
+	}
+	if sizeErr.Width != 16 || sizeErr.Height != 4 || sizeErr.MaxSize != 8 {
+		t.Errorf("unexpected ImageSizeError: %v", sizeErr)
+	}
+	if handle, err := c.CreateImageRGBAE(8, 8, 0, make([]byte, 8*8*4)); err != nil || handle == 0 {
+		t.Errorf("CreateImageRGBAE() should succeed within the limit, but %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package nanovgo
+
+import (
+	"sort"
+)
+
+// PathMeasure reports the length of the flattened path and the points on it. It is made by Context.MeasurePath()
+// or Path.Measure(). Distances are measured along the sub-paths (contours) in the drawing order, and the distance
+// of each contour follows the end of the previous contour. Sub-paths with only one point are ignored.
+//
+//	measure := path.Measure()
+//	vg.StrokePath(measure.SubPath(0, measure.Length()*progress))
+//	x, y, angle := measure.PointAt(measure.Length())
+type PathMeasure struct {
+	contours []measureContour
+	length   float32
+}
+
+type measureContour struct {
+	points    []nvgPoint
+	distances []float32 // Distance from the start of the contour to each point.
+	closed    bool
+	start     float32 // Distance from the start of the path.
+	length    float32
+}
+
+// MeasurePath measures the current path. The lengths and the points are in the current coordinate system.
+func (c *Context) MeasurePath() *PathMeasure {
+	xform := c.getState().xform
+	commands := append([]float32(nil), c.commands...)
+	transformCommands(commands, xform.Inverse())
+	// Keep the flattening tolerance in the window coordinates.
+	tessTol, distTol := c.tessTol, c.distTol
+	if scale := xform.getAverageScale(); scale > 0 {
+		tessTol /= scale
+		distTol /= scale
+	}
+	return newPathMeasure(commands, tessTol, distTol)
+}
+
+// Measure measures the path in its local coordinates. Curves are flattened with the tolerance for
+// the device pixel ratio 1.0.
+func (p *Path) Measure() *PathMeasure {
+	return newPathMeasure(p.commands, 0.25, 0.01)
+}
+
+func newPathMeasure(commands []float32, tessTol, distTol float32) *PathMeasure {
+	var cache nvgPathCache
+	cache.flattenCommands(commands, tessTol, distTol)
+	// The direction of the sub-paths is kept to measure from the first point.
+	cache.calculateSegments(distTol, false)
+
+	m := &PathMeasure{}
+	for i := range cache.paths {
+		path := &cache.paths[i]
+		if path.count < 2 {
+			continue
+		}
+		contour := measureContour{
+			points:    cache.points[path.first : path.first+path.count],
+			distances: make([]float32, path.count+1),
+			closed:    path.closed,
+			start:     m.length,
+		}
+		// The segment from the last point to the first point is only used for closed path.
+		segments := path.count - 1
+		if path.closed {
+			segments++
+		}
+		for j := 0; j < segments; j++ {
+			contour.distances[j+1] = contour.distances[j] + contour.points[j].len
+		}
+		contour.distances = contour.distances[:segments+1]
+		contour.length = contour.distances[segments]
+		m.length += contour.length
+		m.contours = append(m.contours, contour)
+	}
+	return m
+}
+
+// Length returns the total length of the path.
+func (m *PathMeasure) Length() float32 {
+	return m.length
+}
+
+// ContourCount returns the number of the measured sub-paths.
+func (m *PathMeasure) ContourCount() int {
+	return len(m.contours)
+}
+
+// ContourLength returns the length of the sub-path.
+func (m *PathMeasure) ContourLength(index int) float32 {
+	return m.contours[index].length
+}
+
+// ContourClosed returns true if the sub-path is closed.
+func (m *PathMeasure) ContourClosed(index int) bool {
+	return m.contours[index].closed
+}
+
+// PointAt returns the position and the tangent angle (in radians) at the distance from the start of the path.
+// The distance is clamped into the path. It returns zeros for the empty path.
+func (m *PathMeasure) PointAt(distance float32) (x, y, angle float32) {
+	if len(m.contours) == 0 {
+		return 0, 0, 0
+	}
+	distance = clampF(distance, 0, m.length)
+	contour := &m.contours[m.findContour(distance)]
+	return contour.pointAt(distance - contour.start)
+}
+
+// SubPath returns the part of the path between the two distances from the start of the path as a new path.
+// The path from the start to the end of a closed sub-path is closed. It returns an empty path if start is not
+// less than end.
+func (m *PathMeasure) SubPath(start, end float32) *Path {
+	result := NewPath()
+	start = maxF(start, 0)
+	end = minF(end, m.length)
+	if start >= end {
+		return result
+	}
+	for i := range m.contours {
+		contour := &m.contours[i]
+		s, e := start-contour.start, end-contour.start
+		if e < 0 || s > contour.length || (s == contour.length && contour.length > 0) {
+			continue
+		}
+		contour.appendSubPath(result, maxF(s, 0), minF(e, contour.length))
+	}
+	return result
+}
+
+// findContour returns the index of the contour including the distance.
+func (m *PathMeasure) findContour(distance float32) int {
+	index := sort.Search(len(m.contours), func(i int) bool {
+		return m.contours[i].start+m.contours[i].length >= distance
+	})
+	if index == len(m.contours) {
+		index--
+	}
+	return index
+}
+
+// segmentAt returns the index of the segment including the distance.
+func (c *measureContour) segmentAt(distance float32) int {
+	index := sort.Search(len(c.distances)-1, func(i int) bool {
+		return c.distances[i+1] >= distance
+	})
+	if index == len(c.distances)-1 {
+		index--
+	}
+	return index
+}
+
+func (c *measureContour) point(segment int, distance float32) (x, y float32) {
+	p := &c.points[segment]
+	d := distance - c.distances[segment]
+	return p.x + p.dx*d, p.y + p.dy*d
+}
+
+func (c *measureContour) pointAt(distance float32) (x, y, angle float32) {
+	segment := c.segmentAt(distance)
+	p := &c.points[segment]
+	x, y = c.point(segment, distance)
+	return x, y, atan2F(p.dy, p.dx)
+}
+
+func (c *measureContour) appendSubPath(path *Path, start, end float32) {
+	first := c.segmentAt(start)
+	last := c.segmentAt(end)
+	path.MoveTo(c.point(first, start))
+	for i := first + 1; i <= last; i++ {
+		path.LineTo(c.points[i].x, c.points[i].y)
+	}
+	if c.closed && start == 0 && end == c.length {
+		path.ClosePath()
+		return
+	}
+	path.LineTo(c.point(last, end))
+}
@@ -0,0 +1,93 @@
+package nanovgo
+
+import (
+	"testing"
+)
+
+func nearlyEqual(a, b float32) bool {
+	return absF(a-b) < 1e-3
+}
+
+func TestPathMeasure(t *testing.T) {
+	path := NewPath()
+	path.Rect(0, 0, 10, 20)
+	path.MoveTo(100, 0)
+	path.LineTo(130, 40)
+
+	measure := path.Measure()
+	if measure.ContourCount() != 2 {
+		t.Fatalf("measure should have 2 contours, but %d", measure.ContourCount())
+	}
+	if !measure.ContourClosed(0) || measure.ContourClosed(1) {
+		t.Errorf("only the first contour should be closed")
+	}
+	if !nearlyEqual(measure.ContourLength(0), 60) || !nearlyEqual(measure.ContourLength(1), 50) {
+		t.Errorf("contour lengths should be 60 and 50, but %v and %v", measure.ContourLength(0), measure.ContourLength(1))
+	}
+	if !nearlyEqual(measure.Length(), 110) {
+		t.Errorf("total length should be 110, but %v", measure.Length())
+	}
+
+	for _, testCase := range []struct {
+		distance, x, y, angle float32
+	}{
+		{-5, 0, 0, PI / 2},
+		{5, 0, 5, PI / 2},
+		{25, 5, 20, 0},
+		{85, 115, 20, atan2F(40, 30)},
+		{200, 130, 40, atan2F(40, 30)},
+	} {
+		x, y, angle := measure.PointAt(testCase.distance)
+		if !nearlyEqual(x, testCase.x) || !nearlyEqual(y, testCase.y) || !nearlyEqual(angle, testCase.angle) {
+			t.Errorf("PointAt(%v) should be (%v, %v, %v), but (%v, %v, %v)", testCase.distance,
+				testCase.x, testCase.y, testCase.angle, x, y, angle)
+		}
+	}
+}
+
+func TestPathMeasureSubPath(t *testing.T) {
+	path := NewPath()
+	path.Rect(0, 0, 10, 20)
+	path.MoveTo(100, 0)
+	path.LineTo(130, 40)
+	measure := path.Measure()
+
+	whole := measure.SubPath(0, measure.Length())
+	if paths, points := countSubPaths(whole.commands); paths != 2 || points != 6 {
+		t.Errorf("whole sub path should have 2 sub-paths with 6 points, but %d, %d", paths, points)
+	}
+
+	part := measure.SubPath(25, 85).Measure()
+	if part.ContourCount() != 2 || part.ContourClosed(0) {
+		t.Fatalf("sub path over two contours should have 2 open contours")
+	}
+	if !nearlyEqual(part.Length(), 60) {
+		t.Errorf("sub path length should be 60, but %v", part.Length())
+	}
+	if x, y, _ := part.PointAt(0); !nearlyEqual(x, 5) || !nearlyEqual(y, 20) {
+		t.Errorf("sub path should start at (5, 20), but (%v, %v)", x, y)
+	}
+
+	if empty := measure.SubPath(50, 40); len(empty.commands) != 0 {
+		t.Errorf("sub path with reversed range should be empty")
+	}
+}
+
+func TestMeasurePath(t *testing.T) {
+	c := newHitTestContext()
+	c.Translate(50, 50)
+	c.Scale(2, 2)
+	c.BeginPath()
+	c.MoveTo(0, 0)
+	c.LineTo(10, 0)
+	c.Arc(10, 10, 10, -PI/2, 0, Clockwise)
+
+	measure := c.MeasurePath()
+	expected := 10 + PI*5
+	if absF(measure.Length()-expected) > 0.05 {
+		t.Errorf("length should be %v in local coordinates, but %v", expected, measure.Length())
+	}
+	if x, y, _ := measure.PointAt(measure.Length()); !nearlyEqual(x, 20) || absF(y-10) > 0.01 {
+		t.Errorf("end point should be (20, 10), but (%v, %v)", x, y)
+	}
+}
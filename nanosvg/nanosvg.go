@@ -0,0 +1,239 @@
+// Package nanosvg loads SVG documents and draws them onto nanovgo.Context like nanosvg of NanoVG.
+//
+// It supports the static subset of SVG 1.1: shapes (path, rect, circle, ellipse, line, polyline and polygon),
+// groups, nested svg elements, transforms, fill and stroke styles including dashes, linear and radial gradients, opacity and clip paths.
+// The document is parsed into a retained tree once and drawn as vector shapes at any size, so it stays sharp
+// on Hi-DPI screens. Each shape keeps nanovgo.Path, so the tessellation is reused while the size is not changed.
+//
+// There are some limitations from NanoVGo:
+//
+// - Gradients use the first and the last stops only.
+// - Clip paths are applied as the scissor of the bounding box of the clip shapes.
+// - Sub-paths of nonzero fill rule are filled as Solid sub-paths of NanoVGo. Holes need evenodd fill rule.
+// - Group opacity is applied to each shape by the global alpha instead of compositing the group.
+// - Text, images, markers, patterns, masks, filters, use elements and CSS style sheets are ignored.
+package nanosvg
+
+import (
+	"github.com/shibukawa/nanovgo"
+	"io"
+	"os"
+	"strings"
+)
+
+// Image is a parsed SVG document.
+type Image struct {
+	Width   float32    // Width of the document in pixels
+	Height  float32    // Height of the document in pixels
+	ViewBox [4]float32 // x, y, width and height of the view box
+	root    *group
+}
+
+// Parse parses the SVG document from the reader.
+func Parse(r io.Reader) (*Image, error) {
+	return newParser(r).parse()
+}
+
+// ParseString parses the SVG document from the string.
+func ParseString(svg string) (*Image, error) {
+	return Parse(strings.NewReader(svg))
+}
+
+// ParseFile parses the SVG document from the file.
+func ParseFile(filePath string) (*Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Draw draws the image into the rectangle (x, y, w, h) in the current coordinate system of the context.
+// The view box is scaled uniformly and centered in the rectangle as preserveAspectRatio="xMidYMid meet",
+// and the shapes outside of the view box are clipped. The render state of the context is not changed.
+func (img *Image) Draw(ctx *nanovgo.Context, x, y, w, h float32) {
+	vbX, vbY, vbW, vbH := img.ViewBox[0], img.ViewBox[1], img.ViewBox[2], img.ViewBox[3]
+	if vbW <= 0 || vbH <= 0 || w <= 0 || h <= 0 {
+		return
+	}
+	scale := w / vbW
+	if h/vbH < scale {
+		scale = h / vbH
+	}
+	ctx.Save()
+	defer ctx.Restore()
+	ctx.Translate(x+(w-vbW*scale)*0.5, y+(h-vbH*scale)*0.5)
+	ctx.Scale(scale, scale)
+	ctx.Translate(-vbX, -vbY)
+	ctx.IntersectScissor(vbX, vbY, vbW, vbH)
+	img.root.draw(ctx)
+}
+
+type node interface {
+	draw(ctx *nanovgo.Context)
+}
+
+// element is the common part of the groups and the shapes.
+type element struct {
+	transform nanovgo.TransformMatrix
+	opacity   float32
+	clipID    string
+	clip      *clipPath
+}
+
+func (e *element) begin(ctx *nanovgo.Context) {
+	ctx.Save()
+	ctx.SetTransform(e.transform)
+	if e.clip != nil {
+		e.clip.apply(ctx)
+	}
+	if e.opacity < 1 {
+		ctx.SetGlobalAlpha(ctx.GlobalAlpha() * e.opacity)
+	}
+}
+
+type group struct {
+	element
+	children []node
+}
+
+func (g *group) draw(ctx *nanovgo.Context) {
+	g.begin(ctx)
+	for _, child := range g.children {
+		child.draw(ctx)
+	}
+	ctx.Restore()
+}
+
+type paintKind int
+
+const (
+	paintNone paintKind = iota
+	paintColor
+	paintGradient
+)
+
+type paint struct {
+	kind     paintKind
+	color    nanovgo.Color
+	ref      string
+	gradient *gradient
+}
+
+type shape struct {
+	element
+	path          *nanovgo.Path
+	bounds        [4]float32
+	fill          paint
+	stroke        paint
+	fillOpacity   float32
+	fillRule      nanovgo.FillRule
+	strokeOpacity float32
+	strokeWidth   float32
+	miterLimit    float32
+	lineCap       nanovgo.LineCap
+	lineJoin      nanovgo.LineCap
+	dash          []float32
+	dashOffset    float32
+}
+
+func (s *shape) draw(ctx *nanovgo.Context) {
+	s.begin(ctx)
+	if s.fill.kind != paintNone {
+		s.setPaint(ctx, &s.fill, s.fillOpacity, ctx.SetFillColor, ctx.SetFillPaint)
+		ctx.SetFillRule(s.fillRule)
+		ctx.FillPath(s.path)
+	}
+	if s.stroke.kind != paintNone && s.strokeWidth > 0 {
+		s.setPaint(ctx, &s.stroke, s.strokeOpacity, ctx.SetStrokeColor, ctx.SetStrokePaint)
+		ctx.SetStrokeWidth(s.strokeWidth)
+		ctx.SetMiterLimit(s.miterLimit)
+		ctx.SetLineCap(s.lineCap)
+		ctx.SetLineJoin(s.lineJoin)
+		ctx.SetLineDash(s.dash)
+		ctx.SetLineDashOffset(s.dashOffset)
+		ctx.StrokePath(s.path)
+	}
+	ctx.Restore()
+}
+
+func (s *shape) setPaint(ctx *nanovgo.Context, p *paint, opacity float32, setColor func(nanovgo.Color), setPaint func(nanovgo.Paint)) {
+	if p.kind == paintColor {
+		color := p.color
+		color.A *= opacity
+		setColor(color)
+		return
+	}
+	// The gradient is defined in the gradient space. Set it with the transform from the gradient space.
+	xform := ctx.CurrentTransform()
+	ctx.SetTransform(p.gradient.spaceTransform(s.bounds))
+	setPaint(p.gradient.paint(opacity))
+	ctx.ResetTransform()
+	ctx.SetTransform(xform)
+}
+
+// clipPath keeps the bounding box of the clip shapes in the coordinates of clipPath element.
+type clipPath struct {
+	transform nanovgo.TransformMatrix
+	bounds    [4]float32
+}
+
+func (c *clipPath) apply(ctx *nanovgo.Context) {
+	xform := ctx.CurrentTransform()
+	ctx.SetTransform(c.transform)
+	ctx.IntersectScissor(c.bounds[0], c.bounds[1], c.bounds[2]-c.bounds[0], c.bounds[3]-c.bounds[1])
+	ctx.ResetTransform()
+	ctx.SetTransform(xform)
+}
+
+type gradientStop struct {
+	offset float32
+	color  nanovgo.Color
+}
+
+type gradient struct {
+	radial bool
+	attrs  map[string]string
+	stops  []gradientStop
+	href   string
+
+	// resolved values
+	resolved  bool
+	userSpace bool
+	transform nanovgo.TransformMatrix
+	points    [4]float32 // x1, y1, x2, y2 of linear gradient or cx, cy, r of radial gradient
+}
+
+// spaceTransform returns the transform from the gradient space to the user space of the shape.
+func (g *gradient) spaceTransform(bounds [4]float32) nanovgo.TransformMatrix {
+	if g.userSpace {
+		return g.transform
+	}
+	box := nanovgo.TransformMatrix{bounds[2] - bounds[0], 0, 0, bounds[3] - bounds[1], bounds[0], bounds[1]}
+	return g.transform.Multiply(box)
+}
+
+func (g *gradient) paint(opacity float32) nanovgo.Paint {
+	var start, end gradientStop
+	switch len(g.stops) {
+	case 0:
+		start.offset, end.offset = 0, 1
+	case 1:
+		start, end = g.stops[0], g.stops[0]
+		start.offset, end.offset = 0, 1
+	default:
+		start, end = g.stops[0], g.stops[len(g.stops)-1]
+	}
+	if end.offset-start.offset < 1e-4 {
+		end.offset = start.offset + 1e-4
+	}
+	start.color.A *= opacity
+	end.color.A *= opacity
+	p := g.points
+	if g.radial {
+		return nanovgo.RadialGradient(p[0], p[1], p[2]*start.offset, p[2]*end.offset, start.color, end.color)
+	}
+	dx, dy := p[2]-p[0], p[3]-p[1]
+	return nanovgo.LinearGradient(p[0]+dx*start.offset, p[1]+dy*start.offset, p[0]+dx*end.offset, p[1]+dy*end.offset, start.color, end.color)
+}
@@ -0,0 +1,126 @@
+package nanosvg
+
+import (
+	"github.com/shibukawa/nanovgo"
+	"image"
+	"image/color"
+	"testing"
+)
+
+const testSVG = `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="40px" height="20px" viewBox="0 0 20 10">
+  <defs>
+    <linearGradient id="base" x1="0" y1="0" x2="1" y2="0">
+      <stop offset="0" stop-color="#f00"/>
+      <stop offset="100%" stop-color="blue"/>
+    </linearGradient>
+    <linearGradient id="grad" xlink:href="#base"/>
+    <clipPath id="clip"><rect x="10" y="0" width="5" height="10"/></clipPath>
+  </defs>
+  <rect width="10" height="10" fill="url(#grad)"/>
+  <g transform="translate(10, 0)" style="fill: rgb(0, 255, 0)">
+    <circle cx="5" cy="5" r="5" clip-path="url(#clip2)"/>
+  </g>
+  <rect x="10" width="10" height="10" fill="none" stroke="black" clip-path="url(#clip)"/>
+</svg>`
+
+func TestParse(t *testing.T) {
+	img, err := ParseString(testSVG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 40 || img.Height != 20 || img.ViewBox != [4]float32{0, 0, 20, 10} {
+		t.Errorf("unexpected size: %v x %v, %v", img.Width, img.Height, img.ViewBox)
+	}
+	if len(img.root.children) != 3 {
+		t.Fatalf("root should have 3 children, but %d", len(img.root.children))
+	}
+	rect := img.root.children[0].(*shape)
+	if rect.fill.kind != paintGradient || rect.fill.gradient == nil || len(rect.fill.gradient.stops) != 2 {
+		t.Errorf("gradient should be resolved with inherited stops: %+v", rect.fill)
+	}
+	g := img.root.children[1].(*group)
+	if g.transform != nanovgo.TranslateMatrix(10, 0) {
+		t.Errorf("unexpected transform: %v", g.transform)
+	}
+	circle := g.children[0].(*shape)
+	if circle.fill.color != nanovgo.RGB(0, 255, 0) || circle.clip != nil {
+		t.Errorf("circle should inherit fill and ignore unknown clip path: %+v", circle)
+	}
+	frame := img.root.children[2].(*shape)
+	if frame.fill.kind != paintNone || frame.stroke.kind != paintColor || frame.clip == nil {
+		t.Errorf("unexpected style: %+v", frame)
+	}
+
+	if _, err := ParseString("<html></html>"); err == nil {
+		t.Error("non-svg document should be error")
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	xform := parseTransform("translate(10,20) scale(2) rotate(90 1 1)")
+	x, y := xform.TransformPoint(2, 1)
+	if absF(x-12) > 1e-4 || absF(y-24) > 1e-4 {
+		t.Errorf("transform should map (2, 1) to (12, 24), but (%v, %v)", x, y)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	img, _ := ParseString(testSVG)
+	target := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	ctx, err := nanovgo.NewSoftwareContext(target, nanovgo.AntiAlias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Delete()
+	ctx.BeginFrame(40, 20, 1.0)
+	img.Draw(ctx, 0, 0, 40, 20)
+	ctx.EndFrame()
+
+	check := func(x, y int, expected func(c color.RGBA) bool, message string) {
+		if c := target.RGBAAt(x, y); !expected(c) {
+			t.Errorf("pixel (%d, %d) %s, but %v", x, y, message, c)
+		}
+	}
+	check(1, 10, func(c color.RGBA) bool { return c.R > 200 && c.B < 50 }, "should be red side of gradient")
+	check(18, 10, func(c color.RGBA) bool { return c.B > 200 && c.R < 50 }, "should be blue side of gradient")
+	check(30, 10, func(c color.RGBA) bool { return c.G == 255 && c.R == 0 }, "should be green circle")
+	check(24, 0, func(c color.RGBA) bool { return c.A > 128 && c.G < 50 }, "should be stroked inside of clip path")
+	check(38, 0, func(c color.RGBA) bool { return c.A == 0 }, "should not be stroked outside of clip path")
+}
+
+func absF(a float32) float32 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func TestDrawGroupClip(t *testing.T) {
+	img, err := ParseString(`<svg xmlns="http://www.w3.org/2000/svg" width="20" height="10">
+  <clipPath id="left"><rect width="10" height="10"/></clipPath>
+  <g clip-path="url(#left)"><rect width="20" height="10" fill="red"/></g>
+</svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := img.root.children[0].(*group); g.clip == nil {
+		t.Fatal("clip path of the group should be resolved")
+	}
+	target := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	ctx, err := nanovgo.NewSoftwareContext(target, nanovgo.AntiAlias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Delete()
+	ctx.BeginFrame(20, 10, 1.0)
+	img.Draw(ctx, 0, 0, 20, 10)
+	ctx.EndFrame()
+
+	if c := target.RGBAAt(5, 5); c.R != 255 || c.A != 255 {
+		t.Errorf("pixel (5, 5) should be filled inside of clip path, but %v", c)
+	}
+	if c := target.RGBAAt(15, 5); c.A != 0 {
+		t.Errorf("pixel (15, 5) should not be filled outside of clip path, but %v", c)
+	}
+}
@@ -0,0 +1,646 @@
+package nanosvg
+
+import (
+	"encoding/xml"
+	"errors"
+	"github.com/shibukawa/nanovgo"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// style is the inherited properties.
+type style struct {
+	color         nanovgo.Color
+	fill          paint
+	stroke        paint
+	fillOpacity   float32
+	fillRule      nanovgo.FillRule
+	strokeOpacity float32
+	strokeWidth   float32
+	miterLimit    float32
+	lineCap       nanovgo.LineCap
+	lineJoin      nanovgo.LineCap
+	dash          []float32
+	dashOffset    float32
+}
+
+func defaultStyle() style {
+	black := nanovgo.RGB(0, 0, 0)
+	return style{
+		color:         black,
+		fill:          paint{kind: paintColor, color: black},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		miterLimit:    4,
+		lineCap:       nanovgo.Butt,
+		lineJoin:      nanovgo.Miter,
+	}
+}
+
+type parser struct {
+	decoder   *xml.Decoder
+	image     *Image
+	viewport  [2]float32
+	gradients map[string]*gradient
+	clipPaths map[string]*clipPath
+	shapes    []*shape
+	elements  []*element
+}
+
+func newParser(r io.Reader) *parser {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	return &parser{
+		decoder:   decoder,
+		gradients: make(map[string]*gradient),
+		clipPaths: make(map[string]*clipPath),
+	}
+}
+
+func (p *parser) parse() (*Image, error) {
+	for {
+		token, err := p.decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("nanosvg: svg element is not found")
+		} else if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "svg" {
+				return nil, errors.New("nanosvg: root element should be svg, but " + start.Name.Local)
+			}
+			if err := p.parseRoot(start); err != nil {
+				return nil, err
+			}
+			p.resolve()
+			return p.image, nil
+		}
+	}
+}
+
+func (p *parser) parseRoot(start xml.StartElement) error {
+	attrs := attributes(start)
+	img := &Image{}
+	if viewBox, ok := attrs["viewBox"]; ok {
+		values := parseNumbers(viewBox)
+		if len(values) == 4 {
+			copy(img.ViewBox[:], values)
+		}
+	}
+	img.Width = parseLength(attrs["width"], img.ViewBox[2])
+	img.Height = parseLength(attrs["height"], img.ViewBox[3])
+	if img.ViewBox[2] <= 0 || img.ViewBox[3] <= 0 {
+		img.ViewBox = [4]float32{0, 0, img.Width, img.Height}
+	}
+	if img.Width <= 0 || img.Height <= 0 {
+		img.Width, img.Height = img.ViewBox[2], img.ViewBox[3]
+	}
+	p.image = img
+	p.viewport = [2]float32{img.ViewBox[2], img.ViewBox[3]}
+
+	root := &group{element: element{transform: nanovgo.IdentityMatrix(), opacity: 1}}
+	st := defaultStyle()
+	p.applyStyle(&st, attrs)
+	root.opacity = p.opacity(attrs)
+	img.root = root
+	return p.parseChildren(root, st)
+}
+
+// parseChildren parses the child elements until the end of the current element.
+func (p *parser) parseChildren(parent *group, st style) error {
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			if err := p.parseElement(parent, st, token); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *parser) parseElement(parent *group, parentStyle style, start xml.StartElement) error {
+	attrs := attributes(start)
+	if attrs["display"] == "none" {
+		return p.decoder.Skip()
+	}
+	st := parentStyle
+	p.applyStyle(&st, attrs)
+
+	switch start.Name.Local {
+	case "g", "svg", "a":
+		g := &group{element: p.newElement(attrs)}
+		if start.Name.Local == "svg" {
+			// Nested svg element is placed at (x, y). Its view box is not supported.
+			x := parseLength(attrs["x"], p.viewport[0])
+			y := parseLength(attrs["y"], p.viewport[1])
+			g.transform = nanovgo.TranslateMatrix(x, y)
+		}
+		p.elements = append(p.elements, &g.element)
+		parent.children = append(parent.children, g)
+		return p.parseChildren(g, st)
+	case "defs":
+		// Shapes in defs are not drawn. Gradients and clip paths are registered by id.
+		return p.parseChildren(&group{}, st)
+	case "path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+		if s := p.newShape(start.Name.Local, attrs, st); s != nil && attrs["visibility"] != "hidden" {
+			parent.children = append(parent.children, s)
+		}
+	case "linearGradient", "radialGradient":
+		return p.parseGradient(start.Name.Local == "radialGradient", attrs)
+	case "clipPath":
+		return p.parseClipPath(attrs, st)
+	}
+	return p.decoder.Skip()
+}
+
+func (p *parser) newElement(attrs map[string]string) element {
+	return element{
+		transform: parseTransform(attrs["transform"]),
+		opacity:   p.opacity(attrs),
+		clipID:    urlID(attrs["clip-path"]),
+	}
+}
+
+func (p *parser) opacity(attrs map[string]string) float32 {
+	if value, ok := attrs["opacity"]; ok {
+		return clamp(parseNumber(value, 1), 0, 1)
+	}
+	return 1
+}
+
+func (p *parser) newShape(name string, attrs map[string]string, st style) *shape {
+	path := nanovgo.NewPath()
+	vw, vh := p.viewport[0], p.viewport[1]
+	diagonal := float32(math.Sqrt(float64(vw*vw+vh*vh) / 2))
+	switch name {
+	case "path":
+		// Draw the sub-paths before the error as same as browsers.
+		path.AppendSVGPath(attrs["d"])
+	case "rect":
+		x := parseLength(attrs["x"], vw)
+		y := parseLength(attrs["y"], vh)
+		w := parseLength(attrs["width"], vw)
+		h := parseLength(attrs["height"], vh)
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx, hasRx := attrs["rx"]
+		ry, hasRy := attrs["ry"]
+		if !hasRx {
+			rx = ry
+		} else if !hasRy {
+			ry = rx
+		}
+		roundedRect(path, x, y, w, h, parseLength(rx, vw), parseLength(ry, vh))
+	case "circle":
+		r := parseLength(attrs["r"], diagonal)
+		if r <= 0 {
+			return nil
+		}
+		path.Circle(parseLength(attrs["cx"], vw), parseLength(attrs["cy"], vh), r)
+	case "ellipse":
+		rx := parseLength(attrs["rx"], vw)
+		ry := parseLength(attrs["ry"], vh)
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		path.Ellipse(parseLength(attrs["cx"], vw), parseLength(attrs["cy"], vh), rx, ry)
+	case "line":
+		path.MoveTo(parseLength(attrs["x1"], vw), parseLength(attrs["y1"], vh))
+		path.LineTo(parseLength(attrs["x2"], vw), parseLength(attrs["y2"], vh))
+	case "polyline", "polygon":
+		points := parseNumbers(attrs["points"])
+		if len(points) < 4 {
+			return nil
+		}
+		path.MoveTo(points[0], points[1])
+		for i := 2; i+1 < len(points); i += 2 {
+			path.LineTo(points[i], points[i+1])
+		}
+		if name == "polygon" {
+			path.ClosePath()
+		}
+	}
+	s := &shape{
+		element:       p.newElement(attrs),
+		path:          path,
+		bounds:        path.Bounds(),
+		fill:          st.fill,
+		stroke:        st.stroke,
+		fillOpacity:   st.fillOpacity,
+		fillRule:      st.fillRule,
+		strokeOpacity: st.strokeOpacity,
+		strokeWidth:   st.strokeWidth,
+		miterLimit:    st.miterLimit,
+		lineCap:       st.lineCap,
+		lineJoin:      st.lineJoin,
+		dash:          st.dash,
+		dashOffset:    st.dashOffset,
+	}
+	p.shapes = append(p.shapes, s)
+	p.elements = append(p.elements, &s.element)
+	return s
+}
+
+// roundedRect adds the rounded rectangle with the different horizontal and vertical radius.
+func roundedRect(path *nanovgo.Path, x, y, w, h, rx, ry float32) {
+	rx = clamp(rx, 0, w/2)
+	ry = clamp(ry, 0, h/2)
+	if rx == 0 || ry == 0 {
+		path.Rect(x, y, w, h)
+		return
+	}
+	k := 1 - nanovgo.Kappa90
+	path.MoveTo(x+rx, y)
+	path.LineTo(x+w-rx, y)
+	path.BezierTo(x+w-rx*k, y, x+w, y+ry*k, x+w, y+ry)
+	path.LineTo(x+w, y+h-ry)
+	path.BezierTo(x+w, y+h-ry*k, x+w-rx*k, y+h, x+w-rx, y+h)
+	path.LineTo(x+rx, y+h)
+	path.BezierTo(x+rx*k, y+h, x, y+h-ry*k, x, y+h-ry)
+	path.LineTo(x, y+ry)
+	path.BezierTo(x, y+ry*k, x+rx*k, y, x+rx, y)
+	path.ClosePath()
+}
+
+func (p *parser) parseGradient(radial bool, attrs map[string]string) error {
+	g := &gradient{
+		radial: radial,
+		attrs:  attrs,
+		href:   urlID(attrs["href"]),
+	}
+	if id := attrs["id"]; id != "" {
+		p.gradients[id] = g
+	}
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			if token.Name.Local == "stop" {
+				stopAttrs := attributes(token)
+				stop := gradientStop{
+					offset: clamp(parseLength(stopAttrs["offset"], 1), 0, 1),
+					color:  nanovgo.RGB(0, 0, 0),
+				}
+				if color, ok := parseColor(stopAttrs["stop-color"]); ok {
+					stop.color = color
+				}
+				if opacity, ok := stopAttrs["stop-opacity"]; ok {
+					stop.color.A = clamp(parseNumber(opacity, 1), 0, 1)
+				}
+				if len(g.stops) > 0 && stop.offset < g.stops[len(g.stops)-1].offset {
+					stop.offset = g.stops[len(g.stops)-1].offset
+				}
+				g.stops = append(g.stops, stop)
+			}
+			if err := p.decoder.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *parser) parseClipPath(attrs map[string]string, st style) error {
+	shapes := &group{}
+	if err := p.parseChildren(shapes, st); err != nil {
+		return err
+	}
+	clip := &clipPath{
+		transform: parseTransform(attrs["transform"]),
+		bounds:    [4]float32{1e6, 1e6, -1e6, -1e6},
+	}
+	for _, child := range shapes.children {
+		s, ok := child.(*shape)
+		if !ok {
+			continue
+		}
+		b := s.bounds
+		for _, corner := range [4][2]float32{{b[0], b[1]}, {b[2], b[1]}, {b[2], b[3]}, {b[0], b[3]}} {
+			x, y := s.transform.TransformPoint(corner[0], corner[1])
+			clip.bounds = [4]float32{minF(clip.bounds[0], x), minF(clip.bounds[1], y), maxF(clip.bounds[2], x), maxF(clip.bounds[3], y)}
+		}
+	}
+	if clip.bounds[0] > clip.bounds[2] {
+		// Empty clip path hides everything.
+		clip.bounds = [4]float32{}
+	}
+	if id := attrs["id"]; id != "" {
+		p.clipPaths[id] = clip
+	}
+	return nil
+}
+
+// resolve links the references of paints and clip paths after parsing, because they can be defined after use.
+func (p *parser) resolve() {
+	for _, s := range p.shapes {
+		p.resolvePaint(&s.fill)
+		p.resolvePaint(&s.stroke)
+	}
+	for _, e := range p.elements {
+		if e.clipID != "" {
+			e.clip = p.clipPaths[e.clipID]
+		}
+	}
+}
+
+func (p *parser) resolvePaint(paint *paint) {
+	if paint.kind != paintGradient {
+		return
+	}
+	g := p.gradients[paint.ref]
+	if g == nil {
+		paint.kind = paintNone
+		return
+	}
+	p.resolveGradient(g)
+	paint.gradient = g
+}
+
+func (p *parser) resolveGradient(g *gradient) {
+	if g.resolved {
+		return
+	}
+	g.resolved = true
+	// Inherit the attributes and the stops from the referenced gradient.
+	attrs := g.attrs
+	for ref, depth := g.href, 0; ref != "" && depth < 16; depth++ {
+		parent := p.gradients[ref]
+		if parent == nil {
+			break
+		}
+		merged := make(map[string]string)
+		for key, value := range parent.attrs {
+			if key != "id" && key != "href" {
+				merged[key] = value
+			}
+		}
+		for key, value := range attrs {
+			merged[key] = value
+		}
+		attrs = merged
+		if len(g.stops) == 0 {
+			g.stops = parent.stops
+		}
+		ref = parent.href
+	}
+
+	g.userSpace = attrs["gradientUnits"] == "userSpaceOnUse"
+	g.transform = parseTransform(attrs["gradientTransform"])
+	length := func(name, defaultValue string, reference float32) float32 {
+		value, ok := attrs[name]
+		if !ok {
+			value = defaultValue
+		}
+		if !g.userSpace {
+			reference = 1
+		}
+		return parseLength(value, reference)
+	}
+	vw, vh := p.viewport[0], p.viewport[1]
+	if g.radial {
+		diagonal := float32(math.Sqrt(float64(vw*vw+vh*vh) / 2))
+		g.points = [4]float32{length("cx", "50%", vw), length("cy", "50%", vh), length("r", "50%", diagonal)}
+	} else {
+		g.points = [4]float32{length("x1", "0%", vw), length("y1", "0%", vh), length("x2", "100%", vw), length("y2", "0%", vh)}
+	}
+}
+
+// attributes returns the attributes of the element. The declarations of style attribute override them.
+func attributes(start xml.StartElement) map[string]string {
+	attrs := make(map[string]string, len(start.Attr))
+	for _, attr := range start.Attr {
+		attrs[attr.Name.Local] = strings.TrimSpace(attr.Value)
+	}
+	if css, ok := attrs["style"]; ok {
+		for _, declaration := range strings.Split(css, ";") {
+			colon := strings.Index(declaration, ":")
+			if colon < 0 {
+				continue
+			}
+			name := strings.TrimSpace(declaration[:colon])
+			value := strings.TrimSpace(declaration[colon+1:])
+			value = strings.TrimSpace(strings.TrimSuffix(value, "!important"))
+			attrs[name] = value
+		}
+	}
+	return attrs
+}
+
+func (p *parser) applyStyle(st *style, attrs map[string]string) {
+	vw, vh := p.viewport[0], p.viewport[1]
+	if value, ok := attrs["color"]; ok {
+		if color, ok := parseColor(value); ok {
+			st.color = color
+		}
+	}
+	if value, ok := attrs["fill"]; ok {
+		st.fill = parsePaint(value, st.color, st.fill)
+	}
+	if value, ok := attrs["stroke"]; ok {
+		st.stroke = parsePaint(value, st.color, st.stroke)
+	}
+	if value, ok := attrs["fill-opacity"]; ok {
+		st.fillOpacity = clamp(parseNumber(value, 1), 0, 1)
+	}
+	if value, ok := attrs["stroke-opacity"]; ok {
+		st.strokeOpacity = clamp(parseNumber(value, 1), 0, 1)
+	}
+	if value, ok := attrs["stroke-width"]; ok {
+		st.strokeWidth = parseLength(value, float32(math.Sqrt(float64(vw*vw+vh*vh)/2)))
+	}
+	if value, ok := attrs["stroke-miterlimit"]; ok {
+		st.miterLimit = parseNumber(value, 4)
+	}
+	if value, ok := attrs["stroke-dasharray"]; ok {
+		st.dash = nil
+		if value != "none" {
+			st.dash = parseNumbers(value)
+		}
+	}
+	if value, ok := attrs["stroke-dashoffset"]; ok {
+		st.dashOffset = parseNumber(value, 0)
+	}
+	switch attrs["fill-rule"] {
+	case "nonzero":
+		st.fillRule = nanovgo.NonZero
+	case "evenodd":
+		st.fillRule = nanovgo.EvenOdd
+	}
+	switch attrs["stroke-linecap"] {
+	case "butt":
+		st.lineCap = nanovgo.Butt
+	case "round":
+		st.lineCap = nanovgo.Round
+	case "square":
+		st.lineCap = nanovgo.Square
+	}
+	switch attrs["stroke-linejoin"] {
+	case "miter":
+		st.lineJoin = nanovgo.Miter
+	case "round":
+		st.lineJoin = nanovgo.Round
+	case "bevel":
+		st.lineJoin = nanovgo.Bevel
+	}
+}
+
+func parsePaint(value string, currentColor nanovgo.Color, inherited paint) paint {
+	switch value {
+	case "none", "transparent":
+		return paint{kind: paintNone}
+	case "inherit":
+		return inherited
+	case "currentColor":
+		return paint{kind: paintColor, color: currentColor}
+	}
+	if strings.HasPrefix(value, "url(") {
+		return paint{kind: paintGradient, ref: urlID(value)}
+	}
+	if color, ok := parseColor(value); ok {
+		return paint{kind: paintColor, color: color}
+	}
+	return inherited
+}
+
+// urlID returns the id of the reference like "url(#id)" or "#id".
+func urlID(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "url(") {
+		end := strings.Index(value, ")")
+		if end < 0 {
+			return ""
+		}
+		value = strings.Trim(strings.TrimSpace(value[4:end]), `'"`)
+	}
+	if strings.HasPrefix(value, "#") {
+		return value[1:]
+	}
+	return ""
+}
+
+func parseNumber(value string, defaultValue float32) float32 {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 32)
+	if err != nil {
+		return defaultValue
+	}
+	return float32(number)
+}
+
+var unitScales = map[string]float32{
+	"px": 1,
+	"pt": 96.0 / 72.0,
+	"pc": 16,
+	"mm": 96.0 / 25.4,
+	"cm": 96.0 / 2.54,
+	"in": 96,
+	"em": 16,
+	"ex": 8,
+}
+
+// parseLength parses the length with the unit. Percentage is relative to the reference.
+func parseLength(value string, reference float32) float32 {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "%") {
+		return parseNumber(value[:len(value)-1], 0) * reference / 100
+	}
+	if len(value) > 2 {
+		if scale, ok := unitScales[value[len(value)-2:]]; ok {
+			return parseNumber(value[:len(value)-2], 0) * scale
+		}
+	}
+	return parseNumber(value, 0)
+}
+
+// parseNumbers parses the list of numbers separated by white spaces or commas.
+func parseNumbers(value string) []float32 {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+	numbers := make([]float32, 0, len(fields))
+	for _, field := range fields {
+		number, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, float32(number))
+	}
+	return numbers
+}
+
+// parseTransform parses the transform list like "translate(10, 20) rotate(45)".
+func parseTransform(value string) nanovgo.TransformMatrix {
+	result := nanovgo.IdentityMatrix()
+	for {
+		open := strings.Index(value, "(")
+		end := strings.Index(value, ")")
+		if open < 0 || end < open {
+			return result
+		}
+		name := strings.Trim(value[:open], " \t\r\n,")
+		args := parseNumbers(value[open+1 : end])
+		value = value[end+1:]
+
+		t := nanovgo.IdentityMatrix()
+		switch {
+		case name == "matrix" && len(args) == 6:
+			t = nanovgo.TransformMatrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case name == "translate" && len(args) == 1:
+			t = nanovgo.TranslateMatrix(args[0], 0)
+		case name == "translate" && len(args) == 2:
+			t = nanovgo.TranslateMatrix(args[0], args[1])
+		case name == "scale" && len(args) == 1:
+			t = nanovgo.ScaleMatrix(args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			t = nanovgo.ScaleMatrix(args[0], args[1])
+		case name == "rotate" && len(args) == 1:
+			t = nanovgo.RotateMatrix(nanovgo.DegToRad(args[0]))
+		case name == "rotate" && len(args) == 3:
+			// translate(cx, cy) rotate(a) translate(-cx, -cy)
+			t = nanovgo.TranslateMatrix(-args[1], -args[2]).Multiply(nanovgo.RotateMatrix(nanovgo.DegToRad(args[0]))).Multiply(nanovgo.TranslateMatrix(args[1], args[2]))
+		case name == "skewX" && len(args) == 1:
+			t = nanovgo.SkewXMatrix(nanovgo.DegToRad(args[0]))
+		case name == "skewY" && len(args) == 1:
+			t = nanovgo.SkewYMatrix(nanovgo.DegToRad(args[0]))
+		}
+		// The rightmost transform of the list is applied first.
+		result = result.PreMultiply(t)
+	}
+}
+
+func clamp(a, min, max float32) float32 {
+	if a < min {
+		return min
+	}
+	if a > max {
+		return max
+	}
+	return a
+}
+
+func minF(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxF(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
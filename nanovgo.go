@@ -1,13 +1,11 @@
 package nanovgo
 
 import (
-	"bytes"
 	"github.com/shibukawa/nanovgo/fontstashmini"
 	"image"
 	_ "image/jpeg" // to read jpeg
 	_ "image/png"  // to read png
 	"log"
-	"os"
 )
 
 // Context is an entry point object to use NanoVGo API and created by NewContext() function.
@@ -65,9 +63,12 @@ import (
 // to draw common shapes like rectangles and circles, and lower level step-by-step functions,
 // which allow to define a path curve by curve.
 //
-// NanoVG uses even-odd fill rule to draw the shapes. Solid shapes should have counter clockwise
-// winding and holes should have counter clockwise order. To specify winding of a path you can
-// call PathWinding(). This is useful especially for the common shapes, which are drawn CCW.
+// NanoVG uses non-zero fill rule to draw the shapes by default, and even-odd fill rule can be selected
+// by SetFillRule(). Solid shapes should have counter clockwise winding and holes should have clockwise
+// order. To specify winding of a path you can call PathWinding(). This is useful especially for the
+// common shapes, which are drawn CCW. The sub-paths without PathWinding() are Solid, and they are reversed
+// to match their windings. With even-odd fill rule, the sub-paths keep the directions they are drawn unless
+// PathWinding() is used.
 //
 // Finally you can fill the path using current fill style by calling Fill(), and stroke it
 // with current stroke style by calling Stroke().
@@ -105,7 +106,8 @@ import (
 //
 // Note: currently only solid color fill is supported for text.
 type Context struct {
-	params         nvgParams
+	params         Renderer
+	flags          CreateFlags
 	commands       []float32
 	commandX       float32
 	commandY       float32
@@ -122,6 +124,14 @@ type Context struct {
 	fillTriCount   int
 	strokeTriCount int
 	textTriCount   int
+	pathCount      int
+	dashSource     nvgPathCache
+	pointCount     int
+	textureUploads int
+	frameFlushed   bool // EndFrame() flushed the current frame, see FrameStats()
+	recording      *DisplayList
+	pathCommands   []float32    // scratch commands of FillPath() and StrokePath() without the tessellation cache
+	pathCache      nvgPathCache // scratch cache of FillPath() and StrokePath() without the tessellation cache
 }
 
 // Delete is called when tearing down NanoVGo context
@@ -133,7 +143,7 @@ func (c *Context) Delete() {
 			c.fontImages[i] = 0
 		}
 	}
-	c.params.renderDelete()
+	c.params.RenderDelete()
 }
 
 // BeginFrame begins drawing a new frame
@@ -150,22 +160,27 @@ func (c *Context) BeginFrame(windowWidth, windowHeight int, devicePixelRatio flo
 	c.Reset()
 
 	c.setDevicePixelRatio(devicePixelRatio)
-	c.params.renderViewport(windowWidth, windowHeight)
+	c.params.RenderViewport(windowWidth, windowHeight)
 
 	c.drawCallCount = 0
 	c.fillTriCount = 0
 	c.strokeTriCount = 0
 	c.textTriCount = 0
+	c.pathCount = 0
+	c.pointCount = 0
+	c.textureUploads = 0
+	c.frameFlushed = false
 }
 
 // CancelFrame cancels drawing the current frame.
 func (c *Context) CancelFrame() {
-	c.params.renderCancel()
+	c.params.RenderCancel()
 }
 
 // EndFrame ends drawing flushing remaining render state.
 func (c *Context) EndFrame() {
-	c.params.renderFlush()
+	c.params.RenderFlush()
+	c.frameFlushed = true
 	if c.fontImageIdx != 0 {
 		fontImage := c.fontImages[c.fontImageIdx]
 		if fontImage == 0 {
@@ -237,6 +252,20 @@ func (c *Context) StrokeWidth() float32 {
 	return c.getState().strokeWidth
 }
 
+// SetStrokeAlign sets the position of the stroke against closed paths.
+// Can be one of Center (default), Inside, Outside. Inside and Outside strokes keep the stroke width and are drawn
+// on one side of the closed paths and their dashes. The sides are decided by the directions of the sub-paths
+// as same as the non-zero fill rule, so Solid paths are stroked inside and Hole paths are stroked outside of
+// their outlines by Inside. Open paths are always stroked at center.
+func (c *Context) SetStrokeAlign(align StrokeAlign) {
+	c.getState().strokeAlign = align
+}
+
+// StrokeAlign gets the position of the stroke against closed paths.
+func (c *Context) StrokeAlign() StrokeAlign {
+	return c.getState().strokeAlign
+}
+
 // SetMiterLimit sets the miter limit of the stroke style.
 // Miter limit controls when a sharp corner is beveled.
 func (c *Context) SetMiterLimit(limit float32) {
@@ -259,6 +288,58 @@ func (c *Context) LineCap() LineCap {
 	return c.getState().lineCap
 }
 
+// SetLineDash sets the dash pattern of the stroke style. The pattern is the lengths of the dashes and the gaps
+// in the current coordinate system. If the number of the values is odd, the values are repeated to make it even
+// as same as SVG and HTML5 canvas. Up to 16 values are used. Empty pattern, or the pattern including negative values
+// or with zero total length draws solid lines.
+//
+//	vg.SetLineDash([]float32{6, 3})   // dashed line
+//	vg.SetLineCap(nanovgo.Round)
+//	vg.SetLineDash([]float32{0, 4})   // dotted line
+func (c *Context) SetLineDash(pattern []float32) {
+	state := c.getState()
+	state.dashCount = normalizeDash(&state.dash, pattern)
+}
+
+// LineDash returns the dash pattern of the stroke style. It returns nil for solid lines.
+func (c *Context) LineDash() []float32 {
+	state := c.getState()
+	if state.dashCount == 0 {
+		return nil
+	}
+	return append([]float32(nil), state.dash[:state.dashCount]...)
+}
+
+// SetLineDashOffset sets the distance into the dash pattern at which the stroke starts.
+func (c *Context) SetLineDashOffset(offset float32) {
+	c.getState().dashOffset = offset
+}
+
+// LineDashOffset returns the dash offset of the stroke style.
+func (c *Context) LineDashOffset() float32 {
+	return c.getState().dashOffset
+}
+
+// SetFillRule sets the fill rule of the fill style. Can be one of NonZero (default) or EvenOdd.
+// EvenOdd needs the renderer which implements FillRuleRenderer or VectorRenderer.
+func (c *Context) SetFillRule(rule FillRule) {
+	c.getState().fillRule = rule
+}
+
+// FillRule returns the fill rule of the fill style.
+func (c *Context) FillRule() FillRule {
+	return c.getState().fillRule
+}
+
+// renderFillRule returns the fill rule used by the renderer. It is NonZero if the renderer implements
+// neither FillRuleRenderer nor VectorRenderer.
+func (c *Context) renderFillRule() FillRule {
+	if _, ok := c.params.(FillRuleRenderer); ok || c.isVectorRenderer() {
+		return c.getState().fillRule
+	}
+	return NonZero
+}
+
 // SetLineJoin sets how sharp path corners are drawn.
 // Can be one of Miter (default), Round, Bevel.
 func (c *Context) SetLineJoin(joint LineCap) {
@@ -368,72 +449,51 @@ func (c *Context) SetFillPaint(paint Paint) {
 }
 
 // CreateImage creates image by loading it from the disk from specified file name.
-// Returns handle to the image.
+// Returns handle to the image, or 0 if it fails. Use CreateImageE() to know the reason.
 func (c *Context) CreateImage(filePath string, flags ImageFlags) int {
-	file, err := os.Open(filePath)
-	defer file.Close()
-	if err != nil {
-		return 0
-	}
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return 0
-	}
-	return c.CreateImageFromGoImage(flags, img)
+	img, _ := c.CreateImageE(filePath, flags)
+	return img
 }
 
 // CreateImageFromMemory creates image by loading it from the specified chunk of memory.
-// Returns handle to the image.
+// Returns handle to the image, or 0 if it fails. Use CreateImageFromMemoryE() to know the reason.
 func (c *Context) CreateImageFromMemory(flags ImageFlags, data []byte) int {
-	reader := bytes.NewReader(data)
-	img, _, err := image.Decode(reader)
-	if err != nil {
-		return 0
-	}
-	return c.CreateImageFromGoImage(flags, img)
+	img, _ := c.CreateImageFromMemoryE(flags, data)
+	return img
 }
 
 // CreateImageFromGoImage creates image by loading it from the specified image.Image object.
-// Returns handle to the image.
+// Returns handle to the image, or 0 if it fails. Use CreateImageFromGoImageE() to know the reason.
 func (c *Context) CreateImageFromGoImage(imageFlag ImageFlags, img image.Image) int {
-	bounds := img.Bounds()
-	size := bounds.Size()
-	rgba, ok := img.(*image.RGBA)
-	if ok {
-		return c.CreateImageRGBA(size.X, size.Y, imageFlag, rgba.Pix)
-	}
-	rgba = image.NewRGBA(bounds)
-	for x := 0; x < size.X; x++ {
-		for y := 0; y < size.Y; y++ {
-			rgba.Set(x, y, img.At(x, y))
-		}
-	}
-	return c.CreateImageRGBA(size.X, size.Y, imageFlag, rgba.Pix)
+	handle, _ := c.CreateImageFromGoImageE(imageFlag, img)
+	return handle
 }
 
 // CreateImageRGBA creates image from specified image data.
-// Returns handle to the image.
+// Returns handle to the image, or 0 if it fails. Use CreateImageRGBAE() to know the reason.
 func (c *Context) CreateImageRGBA(w, h int, imageFlags ImageFlags, data []byte) int {
-	return c.params.renderCreateTexture(nvgTextureRGBA, w, h, imageFlags, data)
+	img, _ := c.CreateImageRGBAE(w, h, imageFlags, data)
+	return img
 }
 
 // UpdateImage updates image data specified by image handle.
 func (c *Context) UpdateImage(img int, data []byte) error {
-	w, h, err := c.params.renderGetTextureSize(img)
+	w, h, err := c.params.RenderGetTextureSize(img)
 	if err != nil {
 		return err
 	}
-	return c.params.renderUpdateTexture(img, 0, 0, w, h, data)
+	c.textureUploads++
+	return c.params.RenderUpdateTexture(img, 0, 0, w, h, data)
 }
 
 // ImageSize returns the dimensions of a created image.
 func (c *Context) ImageSize(img int) (int, int, error) {
-	return c.params.renderGetTextureSize(img)
+	return c.params.RenderGetTextureSize(img)
 }
 
 // DeleteImage deletes created image.
 func (c *Context) DeleteImage(img int) {
-	c.params.renderDeleteTexture(img)
+	c.params.RenderDeleteTexture(img)
 }
 
 // Scissor sets the current scissor rectangle.
@@ -466,8 +526,8 @@ func (c *Context) IntersectScissor(x, y, w, h float32) {
 	ex := state.scissor.extent[0]
 	ey := state.scissor.extent[1]
 
-	teX := ex * absF(pXform[0]) * ey * absF(pXform[2])
-	teY := ex * absF(pXform[1]) * ey * absF(pXform[3])
+	teX := ex*absF(pXform[0]) + ey*absF(pXform[2])
+	teY := ex*absF(pXform[1]) + ey*absF(pXform[3])
 	rect := intersectRects(pXform[4]-teX, pXform[5]-teY, teX*2, teY*2, x, y, w, h)
 	c.Scissor(rect[0], rect[1], rect[2], rect[3])
 }
@@ -503,13 +563,7 @@ func (c *Context) BezierTo(c1x, c1y, c2x, c2y, x, y float32) {
 
 // QuadTo adds quadratic bezier segment from last point in the path via a control point to the specified point.
 func (c *Context) QuadTo(cx, cy, x, y float32) {
-	x0 := c.commandX
-	y0 := c.commandY
-	c.appendCommand([]float32{float32(nvgBEZIERTO),
-		x0 + 2.0/3.0*(cx-x0), y0 + 2.0/3.0*(cy-y0),
-		x + 2.0/3.0*(cx-x), y + 2.0/3.0*(cy-y),
-		x, y,
-	})
+	c.appendCommand(quadToCommands(c.commandX, c.commandY, cx, cy, x, y))
 }
 
 // Arc creates new circle arc shaped sub-path. The arc center is at cx,cy, the arc radius is r,
@@ -522,56 +576,7 @@ func (c *Context) Arc(cx, cy, r, a0, a1 float32, dir Direction) {
 	} else {
 		move = nvgMOVETO
 	}
-
-	// Clamp angles
-	da := a1 - a0
-	if dir == Clockwise {
-		if absF(da) >= PI*2 {
-			da = PI * 2
-		} else {
-			for da < 0.0 {
-				da += PI * 2
-			}
-		}
-	} else {
-		if absF(da) >= PI*2 {
-			da = -PI * 2
-		} else {
-			for da > 0.0 {
-				da -= PI * 2
-			}
-		}
-	}
-	// Split arc into max 90 degree segments.
-	nDivs := clampI(int(absF(da)/(PI*0.5)+0.5), 1, 5)
-	hda := da / float32(nDivs) / 2.0
-	sin, cos := sinCosF(hda)
-	kappa := absF(4.0 / 3.0 * (1.0 - cos) / sin)
-
-	if dir == CounterClockwise {
-		kappa = -kappa
-	}
-	values := make([]float32, 0, 3+5*7+100)
-	var px, py, pTanX, pTanY float32
-
-	for i := 0; i <= nDivs; i++ {
-		a := a0 + da*float32(i)/float32(nDivs)
-		dy, dx := sinCosF(a)
-		x := cx + dx*r
-		y := cy + dy*r
-		tanX := -dy * r * kappa
-		tanY := dx * r * kappa
-		if i == 0 {
-			values = append(values, float32(move), x, y)
-		} else {
-			values = append(values, float32(nvgBEZIERTO), px+pTanX, py+pTanY, x-tanX, y-tanY, x, y)
-		}
-		px = x
-		py = y
-		pTanX = tanX
-		pTanY = tanY
-	}
-	c.appendCommand(values)
+	c.appendCommand(arcCommands(move, cx, cy, r, a0, a1, dir))
 }
 
 // ArcTo adds an arc segment at the corner defined by the last path point, and two specified points.
@@ -579,93 +584,49 @@ func (c *Context) ArcTo(x1, y1, x2, y2, radius float32) {
 	if len(c.commands) == 0 {
 		return
 	}
-	x0 := c.commandX
-	y0 := c.commandY
-
-	// Handle degenerate cases.
-	if ptEquals(x0, y0, x1, y1, c.distTol) ||
-		ptEquals(x1, y1, x2, y2, c.distTol) ||
-		distPtSeg(x1, y1, x0, y0, x2, y2) < c.distTol*c.distTol ||
-		radius < c.distTol {
-		c.LineTo(x1, y1)
-		return
-	}
+	c.appendCommand(arcToCommands(c.commandX, c.commandY, x1, y1, x2, y2, radius, c.distTol))
+}
 
-	// Calculate tangential circle to lines (x0,y0)-(x1,y1) and (x1,y1)-(x2,y2).
-	dx0 := x0 - x1
-	dy0 := y0 - y1
-	dx1 := x2 - x1
-	dy1 := y2 - y1
-	_, dx0, dy0 = normalize(dx0, dy0)
-	_, dx1, dy1 = normalize(dx1, dy1)
-	a := acosF(dx0*dx1 + dy0*dy1)
-	d := radius / tanF(a/2.0)
-
-	if d > 10000.0 {
-		c.LineTo(x1, y1)
+// EllipticalArcTo adds an elliptical arc segment from the last path point to the point x,y as same as the arc
+// command of SVG path. The radii of the ellipse are rx and ry, and its x-axis is rotated by xAxisRotation
+// (in radians). largeArc selects the arc larger than 180 degrees, and sweep selects the arc drawn in
+// the positive-angle (clockwise on screen) direction. The radii are scaled up if they are too small to reach
+// the end point.
+func (c *Context) EllipticalArcTo(rx, ry, xAxisRotation float32, largeArc, sweep bool, x, y float32) {
+	if len(c.commands) == 0 {
 		return
 	}
-	var cx, cy, a0, a1 float32
-	var dir Direction
-	if cross(dx0, dy0, dx1, dy1) > 0.0 {
-		cx = x1 + dx0*d + dy0*radius
-		cy = y1 + dy0*d + -dx0*radius
-		a0 = atan2F(dx0, -dy0)
-		a1 = atan2F(-dx1, dy1)
-		dir = Clockwise
+	if commands := ellipticalArcToCommands(c.commandX, c.commandY, rx, ry, xAxisRotation, largeArc, sweep, x, y, c.distTol); commands != nil {
+		c.appendCommand(commands)
+	}
+}
+
+// EllipseArc creates new ellipse arc shaped sub-path. The ellipse center is at cx,cy, the radii are rx and ry,
+// and its x-axis is rotated by rotation. The arc is drawn from angle a0 to a1 of the ellipse before rotation,
+// and swept in direction dir (CounterClockwise, or Clockwise). Angles are specified in radians.
+func (c *Context) EllipseArc(cx, cy, rx, ry, rotation, a0, a1 float32, dir Direction) {
+	var move nvgCommands
+	if len(c.commands) > 0 {
+		move = nvgLINETO
 	} else {
-		cx = x1 + dx0*d + -dy0*radius
-		cy = y1 + dy0*d + dx0*radius
-		a0 = atan2F(-dx0, dy0)
-		a1 = atan2F(dx1, -dy1)
-		dir = CounterClockwise
+		move = nvgMOVETO
 	}
-	c.Arc(cx, cy, radius, a0, a1, dir)
+	c.appendCommand(ellipseArcCommands(move, cx, cy, rx, ry, rotation, a0, a1, dir))
 }
 
 // Rect creates new rectangle shaped sub-path.
 func (c *Context) Rect(x, y, w, h float32) {
-	c.appendCommand([]float32{
-		float32(nvgMOVETO), x, y,
-		float32(nvgLINETO), x, y + h,
-		float32(nvgLINETO), x + w, y + h,
-		float32(nvgLINETO), x + w, y,
-		float32(nvgCLOSE),
-	})
+	c.appendCommand(rectCommands(x, y, w, h))
 }
 
 // RoundedRect creates new rounded rectangle shaped sub-path.
 func (c *Context) RoundedRect(x, y, w, h, r float32) {
-	if r < 0.1 {
-		c.Rect(x, y, w, h)
-	} else {
-		rx := minF(r, absF(w)*0.5) * signF(w)
-		ry := minF(r, absF(h)*0.5) * signF(h)
-		c.appendCommand([]float32{
-			float32(nvgMOVETO), x, y + ry,
-			float32(nvgLINETO), x, y + h - ry,
-			float32(nvgBEZIERTO), x, y + h - ry*(1-Kappa90), x + rx*(1-Kappa90), y + h, x + rx, y + h,
-			float32(nvgLINETO), x + w - rx, y + h,
-			float32(nvgBEZIERTO), x + w - rx*(1-Kappa90), y + h, x + w, y + h - ry*(1-Kappa90), x + w, y + h - ry,
-			float32(nvgLINETO), x + w, y + ry,
-			float32(nvgBEZIERTO), x + w, y + ry*(1-Kappa90), x + w - rx*(1-Kappa90), y, x + w - rx, y,
-			float32(nvgLINETO), x + rx, y,
-			float32(nvgBEZIERTO), x + rx*(1-Kappa90), y, x, y + ry*(1-Kappa90), x, y + ry,
-			float32(nvgCLOSE),
-		})
-	}
+	c.appendCommand(roundedRectCommands(x, y, w, h, r))
 }
 
 // Ellipse creates new ellipse shaped sub-path.
 func (c *Context) Ellipse(cx, cy, rx, ry float32) {
-	c.appendCommand([]float32{
-		float32(nvgMOVETO), cx - rx, cy,
-		float32(nvgBEZIERTO), cx - rx, cy + ry*Kappa90, cx - rx*Kappa90, cy + ry, cx, cy + ry,
-		float32(nvgBEZIERTO), cx + rx*Kappa90, cy + ry, cx + rx, cy + ry*Kappa90, cx + rx, cy,
-		float32(nvgBEZIERTO), cx + rx, cy - ry*Kappa90, cx + rx*Kappa90, cy - ry, cx, cy - ry,
-		float32(nvgBEZIERTO), cx - rx*Kappa90, cy - ry, cx - rx, cy - ry*Kappa90, cx - rx, cy,
-		float32(nvgCLOSE),
-	})
+	c.appendCommand(ellipseCommands(cx, cy, rx, ry))
 }
 
 // Circle creates new circle shaped sub-path.
@@ -706,36 +667,85 @@ func (c *Context) DebugDumpPathCache() {
 
 // Fill fills the current path with current fill style.
 func (c *Context) Fill() {
-	state := c.getState()
-	fillPaint := state.fill
+	if c.recording != nil {
+		c.recording.recordPath(displayFill, c, c.commands)
+	}
+	if r, ok := c.params.(VectorRenderer); ok {
+		c.fillVectorPath(r)
+		return
+	}
+	c.flattenPaths()
+	c.expandFill(&c.cache)
+	c.renderFill(c.cache.paths, c.cache.bounds)
+}
+
+// Stroke draws the current path with current stroke style.
+func (c *Context) Stroke() {
+	if c.recording != nil {
+		c.recording.recordPath(displayStroke, c, c.commands)
+	}
+	if r, ok := c.params.(VectorRenderer); ok {
+		c.strokeVectorPath(r)
+		return
+	}
+	strokePaint, strokeWidth := c.strokeParams()
 	c.flattenPaths()
+	c.expandStroke(&c.cache, strokeWidth)
+	c.renderStroke(&strokePaint, strokeWidth, c.cache.paths)
+	if c.getState().dashCount > 0 {
+		// The path cache keeps the dashes. Flatten the path again for the next Fill() or Stroke().
+		c.cache.clearPathCache()
+	}
+}
+
+// edgeAntiAlias returns true if both the create flags and the renderer enable the anti-aliasing fringes.
+func (c *Context) edgeAntiAlias() bool {
+	return c.flags&AntiAlias != 0 && c.params.EdgeAntiAlias()
+}
 
-	if c.params.edgeAntiAlias() {
-		c.cache.expandFill(c.fringeWidth, Miter, 2.4, c.fringeWidth)
+func (c *Context) expandFill(cache *nvgPathCache) {
+	if c.edgeAntiAlias() {
+		cache.orientFringes()
+		cache.expandFill(c.fringeWidth, Miter, 2.4, c.fringeWidth)
 	} else {
-		c.cache.expandFill(0.0, Miter, 2.4, c.fringeWidth)
+		cache.expandFill(0.0, Miter, 2.4, c.fringeWidth)
 	}
+}
+
+func (c *Context) renderFill(paths []RenderPath, bounds [4]float32) {
+	state := c.getState()
+	fillPaint := state.fill
 
 	// Apply global alpha
 	fillPaint.innerColor.A *= state.alpha
 	fillPaint.outerColor.A *= state.alpha
 
-	c.params.renderFill(&fillPaint, &state.scissor, c.fringeWidth, c.cache.bounds, c.cache.paths)
+	if r, ok := c.params.(FillRuleRenderer); ok {
+		r.RenderFillRule(&fillPaint, &state.scissor, c.fringeWidth, bounds, paths, state.fillRule)
+	} else {
+		c.params.RenderFill(&fillPaint, &state.scissor, c.fringeWidth, bounds, paths)
+	}
 
 	// Count triangles
-	for i := 0; i < len(c.cache.paths); i++ {
-		path := &c.cache.paths[i]
+	c.countPaths(paths)
+	for i := 0; i < len(paths); i++ {
+		path := &paths[i]
 		c.fillTriCount += len(path.fills) - 2
 		c.strokeTriCount += len(path.strokes) - 2
 		c.drawCallCount += 2
 	}
 }
 
-// Stroke draws the current path with current stroke style.
-func (c *Context) Stroke() {
+// strokeParams returns the stroke paint and the stroke width in the window coordinates.
+func (c *Context) strokeParams() (Paint, float32) {
 	state := c.getState()
 	scale := state.xform.getAverageScale()
-	strokeWidth := clampF(state.strokeWidth*scale, 0.0, 200.0)
+	return c.strokeParamsWithWidth(clampF(state.strokeWidth*scale, 0.0, 200.0))
+}
+
+// strokeParamsWithWidth returns the stroke paint and the stroke width for the stroke width in the window coordinates.
+func (c *Context) strokeParamsWithWidth(strokeWidth float32) (Paint, float32) {
+	state := c.getState()
 	strokePaint := state.stroke
 
 	if strokeWidth < c.fringeWidth {
@@ -750,23 +760,46 @@ func (c *Context) Stroke() {
 	// Apply global alpha
 	strokePaint.innerColor.A *= state.alpha
 	strokePaint.outerColor.A *= state.alpha
+	return strokePaint, strokeWidth
+}
 
-	c.flattenPaths()
-	for _, path := range c.cache.paths {
+func (c *Context) expandStroke(cache *nvgPathCache, strokeWidth float32) {
+	state := c.getState()
+	if state.dashCount > 0 {
+		scale := state.xform.getAverageScale()
+		cache.dashPaths(&c.dashSource, state.dash[:state.dashCount], state.dashOffset, scale, state.lineCap, c.distTol)
+	}
+	for _, path := range cache.paths {
 		if path.count == 1 {
 			panic("")
 		}
 	}
-	if c.params.edgeAntiAlias() {
-		c.cache.expandStroke(strokeWidth*0.5+c.fringeWidth*0.5, state.lineCap, state.lineJoin, state.miterLimit, c.fringeWidth, c.tessTol)
+	offset := strokeAlignOffset(state.strokeAlign, strokeWidth)
+	if c.edgeAntiAlias() {
+		cache.expandStroke(strokeWidth*0.5+c.fringeWidth*0.5, offset, state.lineCap, state.lineJoin, state.miterLimit, c.fringeWidth, c.tessTol)
 	} else {
-		c.cache.expandStroke(strokeWidth*0.5, state.lineCap, state.lineJoin, state.miterLimit, c.fringeWidth, c.tessTol)
+		cache.expandStroke(strokeWidth*0.5, offset, state.lineCap, state.lineJoin, state.miterLimit, c.fringeWidth, c.tessTol)
+	}
+}
+
+// strokeAlignOffset returns the distance from the path to the center of the stroke. It is positive to the inside.
+func strokeAlignOffset(align StrokeAlign, strokeWidth float32) float32 {
+	switch align {
+	case Inside:
+		return strokeWidth * 0.5
+	case Outside:
+		return -strokeWidth * 0.5
 	}
-	c.params.renderStroke(&strokePaint, &state.scissor, c.fringeWidth, strokeWidth, c.cache.paths)
+	return 0.0
+}
+
+func (c *Context) renderStroke(strokePaint *Paint, strokeWidth float32, paths []RenderPath) {
+	c.params.RenderStroke(strokePaint, &c.getState().scissor, c.fringeWidth, strokeWidth, paths)
 
 	// Count triangles
-	for i := 0; i < len(c.cache.paths); i++ {
-		path := &c.cache.paths[i]
+	c.countPaths(paths)
+	for i := 0; i < len(paths); i++ {
+		path := &paths[i]
 		c.strokeTriCount += len(path.strokes) - 2
 		c.drawCallCount += 2
 	}
@@ -881,10 +914,26 @@ func (c *Context) TextRune(x, y float32, runes []rune) float32 {
 	c.fs.SetBlur(state.fontBlur * scale)
 	c.fs.SetAlign(fontstashmini.FONSAlign(state.textAlign))
 	c.fs.SetFont(state.fontID)
+	if c.recording != nil {
+		c.recording.recordText(c, x, y, runes, c.fs.GetFontName())
+	}
 
 	vertexCount := maxI(2, len(runes)) * 4 // conservative estimate.
 	vertexes := c.cache.allocVertexes(vertexCount)
 
+	vectorRenderer, isVector := c.params.(VectorRenderer)
+	var textRun *TextRun
+	if isVector {
+		textRun = &TextRun{
+			Runes:    make([]rune, 0, len(runes)),
+			X:        make([]float32, 0, len(runes)),
+			Xform:    state.xform,
+			FontName: c.fs.GetFontName(),
+			FontSize: state.fontSize,
+			FontData: c.fs.GetFontData(),
+		}
+	}
+
 	iter := c.fs.TextIterForRunes(x*scale, y*scale, runes)
 	prevIter := iter
 	index := 0
@@ -910,6 +959,15 @@ func (c *Context) TextRune(x, y float32, runes []rune) float32 {
 			}
 		}
 		prevIter = iter
+		if isVector {
+			if iter.CodePoint < 0x20 {
+				continue // skip control characters like new line
+			}
+			textRun.Runes = append(textRun.Runes, iter.CodePoint)
+			textRun.X = append(textRun.X, iter.X*invScale)
+			textRun.Y = iter.Y * invScale
+			continue
+		}
 		// Transform corners.
 		c0, c1 := state.xform.TransformPoint(quad.X0*invScale, quad.Y0*invScale)
 		c2, c3 := state.xform.TransformPoint(quad.X1*invScale, quad.Y0*invScale)
@@ -926,6 +984,12 @@ func (c *Context) TextRune(x, y float32, runes []rune) float32 {
 		}
 	}
 	c.flushTextTexture()
+	if isVector {
+		if len(textRun.Runes) > 0 {
+			c.renderVectorText(vectorRenderer, textRun)
+		}
+		return iter.X
+	}
 	c.renderText(vertexes[:index])
 	return iter.X
 }
@@ -1342,26 +1406,41 @@ func (c *Context) TextBreakLinesRune(runes []rune, breakRowWidth float32) []Text
 	return rows
 }
 
-func createInternal(params nvgParams) (*Context, error) {
+// NewContextWithRenderer makes new NanoVGo context that draws via the specified renderer.
+//
+// The context makes the anti-aliasing fringes if flags has AntiAlias and r.EdgeAntiAlias() returns true.
+// If r implements CreateFlagsReceiver, flags is passed to it before RenderCreate() to apply the other flags
+// like StencilStrokes and Debug.
+func NewContextWithRenderer(r Renderer, flags CreateFlags) (*Context, error) {
+	return createInternal(r, flags)
+}
+
+func createInternal(params Renderer, flags CreateFlags) (*Context, error) {
 	context := &Context{
 		params:     params,
+		flags:      flags,
 		states:     make([]nvgState, 0, nvgMaxStates),
 		fontImages: make([]int, nvgMaxFontImages),
 		commands:   make([]float32, 0, nvgInitCommandsSize),
 		cache: nvgPathCache{
 			points:   make([]nvgPoint, 0, nvgInitPointsSize),
-			paths:    make([]nvgPath, 0, nvgInitPathsSize),
-			vertexes: make([]nvgVertex, 0, nvgInitVertsSize),
+			paths:    make([]RenderPath, 0, nvgInitPathsSize),
+			vertexes: make([]Vertex, 0, nvgInitVertsSize),
 		},
 	}
 	context.Save()
 	context.Reset()
 	context.setDevicePixelRatio(1.0)
-	context.params.renderCreate()
+	if receiver, ok := params.(CreateFlagsReceiver); ok {
+		receiver.RenderCreateFlags(flags)
+	}
+	if err := context.params.RenderCreate(); err != nil {
+		return nil, err
+	}
 
 	context.fs = fontstashmini.New(nvgInitFontImageSize, nvgInitFontImageSize)
 
-	context.fontImages[0] = context.params.renderCreateTexture(nvgTextureALPHA, nvgInitFontImageSize, nvgInitFontImageSize, 0, nil)
+	context.fontImages[0] = context.params.RenderCreateTexture(TextureAlpha, nvgInitFontImageSize, nvgInitFontImageSize, 0, nil)
 	context.fontImageIdx = 0
 
 	return context, nil
@@ -1412,59 +1491,85 @@ func (c *Context) appendCommand(vals []float32) {
 }
 
 func (c *Context) flattenPaths() {
-	cache := &c.cache
-	if len(cache.paths) > 0 {
-		return
+	fillRule := c.renderFillRule()
+	if len(c.cache.paths) > 0 {
+		if c.cache.fillRule == fillRule {
+			return
+		}
+		c.cache.clearPathCache()
 	}
-	// Flatten
+	c.cache.flattenPaths(c.commands, c.tessTol, c.distTol, fillRule)
+}
+
+// flattenPaths flattens the commands into the paths. The sub-paths are reversed to match their windings
+// unless they are filled by EvenOdd fill rule without PathWinding(), see enforcesWinding().
+func (c *nvgPathCache) flattenPaths(commands []float32, tessTol, distTol float32, fillRule FillRule) {
+	c.flattenCommands(commands, tessTol, distTol)
+	c.fillRule = fillRule
+	c.calculateSegments(distTol, c.enforcesWinding())
+}
+
+// enforcesWinding returns true if the sub-paths are reversed to match their windings. The sub-paths keep their
+// directions only if they are filled by EvenOdd fill rule and PathWinding() is not used, because the directions
+// don't change the filled area but the side of the anti-aliasing fringe and the stroke align.
+func (c *nvgPathCache) enforcesWinding() bool {
+	return c.fillRule != EvenOdd || c.hasWinding
+}
+
+// flattenCommands flattens the path commands into the points.
+func (c *nvgPathCache) flattenCommands(commands []float32, tessTol, distTol float32) {
 	i := 0
-	for i < len(c.commands) {
-		switch nvgCommands(c.commands[i]) {
+	for i < len(commands) {
+		switch nvgCommands(commands[i]) {
 		case nvgMOVETO:
-			cache.addPath()
-			cache.addPoint(c.commands[i+1], c.commands[i+2], nvgPtCORNER, c.distTol)
+			c.addPath()
+			c.addPoint(commands[i+1], commands[i+2], nvgPtCORNER, distTol)
 			i += 3
 		case nvgLINETO:
-			cache.addPoint(c.commands[i+1], c.commands[i+2], nvgPtCORNER, c.distTol)
+			c.addPoint(commands[i+1], commands[i+2], nvgPtCORNER, distTol)
 			i += 3
 		case nvgBEZIERTO:
-			last := cache.lastPoint()
+			last := c.lastPoint()
 			if last != nil {
-				cache.tesselateBezier(
+				c.tesselateBezier(
 					last.x, last.y,
-					c.commands[i+1], c.commands[i+2],
-					c.commands[i+3], c.commands[i+4],
-					c.commands[i+5], c.commands[i+6], 0, nvgPtCORNER, c.tessTol, c.distTol)
+					commands[i+1], commands[i+2],
+					commands[i+3], commands[i+4],
+					commands[i+5], commands[i+6], 0, nvgPtCORNER, tessTol, distTol)
 			}
 			i += 7
 		case nvgCLOSE:
-			cache.closePath()
+			c.closePath()
 			i++
 		case nvgWINDING:
-			cache.pathWinding(Winding(c.commands[i+1]))
+			c.pathWinding(Winding(commands[i+1]))
 			i += 2
 		default:
 			i++
 		}
 	}
+}
 
-	cache.bounds = [4]float32{1e6, 1e6, -1e6, -1e6}
+// calculateSegments calculates the direction and length of line segments and the bounds of the flattened paths.
+// If enforceWinding is true, the sub-paths are reversed to match their windings.
+func (c *nvgPathCache) calculateSegments(distTol float32, enforceWinding bool) {
+	c.bounds = [4]float32{1e6, 1e6, -1e6, -1e6}
 
 	// Calculate the direction and length of line segments.
-	for j := 0; j < len(cache.paths); j++ {
-		path := &cache.paths[j]
-		points := cache.points[path.first:]
+	for j := 0; j < len(c.paths); j++ {
+		path := &c.paths[j]
+		points := c.points[path.first:]
 		p0 := &points[path.count-1]
 		p1Index := 0
 		p1 := &points[p1Index]
-		if ptEquals(p0.x, p0.y, p1.x, p1.y, c.distTol) && path.count > 2 {
+		if ptEquals(p0.x, p0.y, p1.x, p1.y, distTol) && path.count > 2 {
 			path.count--
 			p0 = &points[path.count-1]
 			path.closed = true
 		}
 
 		// Enforce winding.
-		if path.count > 2 {
+		if enforceWinding && path.count > 2 {
 			area := polyArea(points, path.count)
 			if path.winding == Solid && area < 0.0 {
 				polyReverse(points, path.count)
@@ -1476,11 +1581,11 @@ func (c *Context) flattenPaths() {
 			// Calculate segment direction and length
 			p0.len, p0.dx, p0.dy = normalize(p1.x-p0.x, p1.y-p0.y)
 			// Update bounds
-			cache.bounds = [4]float32{
-				minF(cache.bounds[0], p0.x),
-				minF(cache.bounds[1], p0.y),
-				maxF(cache.bounds[2], p0.x),
-				maxF(cache.bounds[3], p0.y),
+			c.bounds = [4]float32{
+				minF(c.bounds[0], p0.x),
+				minF(c.bounds[1], p0.y),
+				maxF(c.bounds[2], p0.x),
+				maxF(c.bounds[3], p0.y),
 			}
 			// Advance
 			p1Index++
@@ -1503,7 +1608,8 @@ func (c *Context) flushTextTexture() {
 			y := dirty[1]
 			w := dirty[2] - x
 			h := dirty[3] - y
-			c.params.renderUpdateTexture(fontImage, x, y, w, h, data)
+			c.params.RenderUpdateTexture(fontImage, x, y, w, h, data)
+			c.textureUploads++
 		}
 	}
 }
@@ -1528,14 +1634,14 @@ func (c *Context) allocTextAtlas() bool {
 			iw = nvgMaxFontImageSize
 			ih = nvgMaxFontImageSize
 		}
-		c.fontImages[c.fontImageIdx+1] = c.params.renderCreateTexture(nvgTextureALPHA, iw, ih, 0, nil)
+		c.fontImages[c.fontImageIdx+1] = c.params.RenderCreateTexture(TextureAlpha, iw, ih, 0, nil)
 	}
 	c.fontImageIdx++
 	c.fs.ResetAtlas(iw, ih)
 	return true
 }
 
-func (c *Context) renderText(vertexes []nvgVertex) {
+func (c *Context) renderText(vertexes []Vertex) {
 	state := c.getState()
 	paint := state.fill
 
@@ -1546,7 +1652,7 @@ func (c *Context) renderText(vertexes []nvgVertex) {
 	paint.innerColor.A *= state.alpha
 	paint.outerColor.A *= state.alpha
 
-	c.params.renderTriangleStrip(&paint, &state.scissor, vertexes)
+	c.params.RenderTriangleStrip(&paint, &state.scissor, vertexes)
 
 	c.drawCallCount++
 	c.textTriCount += len(vertexes) / 3
@@ -71,3 +71,153 @@ func TestStateSaveRestore2(t *testing.T) {
 		t.Errorf("Restore() should set saved xform, but %v", topStateAgain.xform)
 	}
 }
+
+func TestIntersectScissor(t *testing.T) {
+	c := &Context{}
+	c.Save()
+	c.Reset()
+
+	c.Scale(2, 2)
+	c.Scissor(0, 0, 20, 10)
+	c.IntersectScissor(10, 0, 20, 20)
+
+	scissor := c.getState().scissor
+	if !equal(scissor.xform, TranslateMatrix(15, 5).Multiply(ScaleMatrix(2, 2))) {
+		t.Errorf("scissor should be centered at (15, 5), but %v", scissor.xform)
+	}
+	if scissor.extent != [2]float32{5, 5} {
+		t.Errorf("scissor extent should be (5, 5), but %v", scissor.extent)
+	}
+}
+
+// stubRenderer is a Renderer of the third party to test NewContextWithRenderer().
+type stubRenderer struct {
+	antiAlias bool
+	flags     CreateFlags
+	textures  int
+	fringes   []int
+}
+
+func (r *stubRenderer) EdgeAntiAlias() bool {
+	return r.antiAlias
+}
+
+func (r *stubRenderer) RenderCreateFlags(flags CreateFlags) {
+	r.flags = flags
+}
+
+func (r *stubRenderer) RenderCreate() error {
+	return nil
+}
+
+func (r *stubRenderer) RenderCreateTexture(texType TextureType, w, h int, flags ImageFlags, data []byte) int {
+	r.textures++
+	return r.textures
+}
+
+func (r *stubRenderer) RenderDeleteTexture(image int) error {
+	return nil
+}
+
+func (r *stubRenderer) RenderUpdateTexture(image, x, y, w, h int, data []byte) error {
+	return nil
+}
+
+func (r *stubRenderer) RenderGetTextureSize(image int) (int, int, error) {
+	return 512, 512, nil
+}
+
+func (r *stubRenderer) RenderViewport(width, height int) {
+}
+
+func (r *stubRenderer) RenderCancel() {
+}
+
+func (r *stubRenderer) RenderFlush() {
+}
+
+func (r *stubRenderer) RenderFill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath) {
+	r.fringes = append(r.fringes, len(paths[0].Strokes()))
+}
+
+func (r *stubRenderer) RenderStroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []RenderPath) {
+}
+
+func (r *stubRenderer) RenderTriangles(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+}
+
+func (r *stubRenderer) RenderTriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+}
+
+func (r *stubRenderer) RenderDelete() {
+}
+
+func TestNewContextWithRenderer(t *testing.T) {
+	for _, testCase := range []struct {
+		antiAlias bool
+		flags     CreateFlags
+		fringe    bool
+	}{
+		{true, AntiAlias | StencilStrokes, true},
+		{true, StencilStrokes | Debug, false},
+		{false, AntiAlias, false},
+	} {
+		r := &stubRenderer{antiAlias: testCase.antiAlias}
+		c, err := NewContextWithRenderer(r, testCase.flags)
+		if err != nil {
+			t.Fatalf("NewContextWithRenderer() should succeed, but %v", err)
+		}
+		if r.flags != testCase.flags {
+			t.Errorf("renderer should receive the flags %v, but %v", testCase.flags, r.flags)
+		}
+		c.BeginFrame(32, 32, 1.0)
+		c.BeginPath()
+		c.Rect(8, 8, 16, 16)
+		c.Fill()
+		c.EndFrame()
+		c.Delete()
+		if len(r.fringes) != 1 || (r.fringes[0] > 0) != testCase.fringe {
+			t.Errorf("fringe of the fill with the flags %v and EdgeAntiAlias() %v should be %v, but %v",
+				testCase.flags, testCase.antiAlias, testCase.fringe, r.fringes)
+		}
+	}
+}
+
+type fillRuleRenderer struct {
+	stubRenderer
+	fillRules []FillRule
+}
+
+func (r *fillRuleRenderer) RenderFillRule(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath, fillRule FillRule) {
+	r.fillRules = append(r.fillRules, fillRule)
+}
+
+func TestFillRuleRenderer(t *testing.T) {
+	fill := func(c *Context) {
+		c.BeginFrame(32, 32, 1.0)
+		c.SetFillRule(EvenOdd)
+		c.BeginPath()
+		c.Rect(8, 8, 16, 16)
+		c.Fill()
+		c.EndFrame()
+	}
+
+	r := &fillRuleRenderer{}
+	c, _ := NewContextWithRenderer(r, 0)
+	fill(c)
+	if len(r.fringes) != 0 || len(r.fillRules) != 1 || r.fillRules[0] != EvenOdd {
+		t.Errorf("FillRuleRenderer should receive the fill rule, but RenderFill() %v and RenderFillRule() %v", r.fringes, r.fillRules)
+	}
+	c.Delete()
+
+	s := &stubRenderer{}
+	c, _ = NewContextWithRenderer(s, 0)
+	fill(c)
+	if len(s.fringes) != 1 {
+		t.Errorf("renderer without FillRuleRenderer should receive RenderFill(), but %v", s.fringes)
+	}
+	if c.renderFillRule() != NonZero {
+		t.Error("renderer without FillRuleRenderer should fill with NonZero")
+	}
+	c.Delete()
+}
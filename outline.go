@@ -0,0 +1,84 @@
+package nanovgo
+
+import (
+	"math"
+)
+
+// StrokeOutline returns the outline of the current path stroked with the current stroke width, stroke align, line cap,
+// line join, miter limit and dash pattern as a new path. The outline is in the current coordinate system, so filling it by
+// FillPath() with the same transform covers the same area as Stroke(). Overlapped parts of the stroke are merged
+// into one outline and the sub-paths have Solid or Hole winding as same as Path.Combine().
+func (c *Context) StrokeOutline() *Path {
+	state := c.getState()
+	scale := state.xform.getAverageScale()
+	commands := strokeOutlineCommands(c.commands, state.strokeWidth*scale, state.strokeAlign, state.lineCap, state.lineJoin,
+		state.miterLimit, state.dash[:state.dashCount], state.dashOffset, scale, c.renderFillRule(), c.tessTol, c.distTol)
+	transformCommands(commands, state.xform.Inverse())
+	result := NewPath()
+	result.commands = commands
+	result.commandX, result.commandY = lastCommandPoint(commands)
+	return result
+}
+
+// StrokeOutline returns the outline of the path stroked with the style as a new path. The width and the dash
+// pattern of the style are in the local coordinates of the path. Curves are flattened with the tolerance for
+// the device pixel ratio 1.0.
+func (p *Path) StrokeOutline(style *StrokeStyle) *Path {
+	var dash [nvgMaxLineDash]float32
+	count := normalizeDash(&dash, style.Dash)
+	result := NewPath()
+	result.commands = strokeOutlineCommands(p.commands, style.Width, style.Align, style.LineCap, style.LineJoin,
+		style.MiterLimit, dash[:count], style.DashOffset, 1.0, NonZero, 0.25, 0.01)
+	result.commandX, result.commandY = lastCommandPoint(result.commands)
+	return result
+}
+
+// strokeOutlineCommands expands the stroke without anti-aliasing fringe and merges the triangles of the strips.
+// The fill rule decides the directions of the sub-paths for the stroke align as same as flattenPaths().
+func strokeOutlineCommands(commands []float32, width float32, align StrokeAlign, lineCap, lineJoin LineCap, miterLimit float32,
+	dash []float32, dashOffset, dashScale float32, fillRule FillRule, tessTol, distTol float32) []float32 {
+	if width <= 0.0 {
+		return nil
+	}
+	var cache nvgPathCache
+	cache.flattenPaths(commands, tessTol, distTol, fillRule)
+	if len(dash) > 0 {
+		var src nvgPathCache
+		cache.dashPaths(&src, dash, dashOffset, dashScale, lineCap, distTol)
+	}
+	// A lone point has no direction to stroke.
+	paths := cache.paths[:0]
+	for _, path := range cache.paths {
+		if path.count > 1 {
+			paths = append(paths, path)
+		}
+	}
+	cache.paths = paths
+	cache.expandStroke(width*0.5, strokeAlignOffset(align, width), lineCap, lineJoin, miterLimit, 0.0, tessTol)
+	return mergeStrokes(&cache, distTol)
+}
+
+// mergeStrokes merges the triangles of the stroke strips expanded without anti-aliasing fringe into the outline.
+func mergeStrokes(cache *nvgPathCache, distTol float32) []float32 {
+	var triangles []pathOpPolygon
+	for i := range cache.paths {
+		strokes := cache.paths[i].strokes
+		for j := 0; j+2 < len(strokes); j++ {
+			v0, v1, v2 := &strokes[j], &strokes[j+1], &strokes[j+2]
+			area := (v1.x-v0.x)*(v2.y-v0.y) - (v2.x-v0.x)*(v1.y-v0.y)
+			if math.Abs(float64(area)) < 1e-9 {
+				continue
+			}
+			// Triangles of the strip are in the alternate directions. Make them same for the non-zero fill rule.
+			if area < 0.0 {
+				v1, v2 = v2, v1
+			}
+			triangles = append(triangles, newPathOpPolygon([]pathOpPoint{
+				{float64(v0.x), float64(v0.y)},
+				{float64(v1.x), float64(v1.y)},
+				{float64(v2.x), float64(v2.y)},
+			}))
+		}
+	}
+	return combinePolygons(triangles, nil, PathUnion, distTol)
+}
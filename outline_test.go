@@ -0,0 +1,112 @@
+package nanovgo
+
+import (
+	"testing"
+)
+
+func TestStrokeOutline(t *testing.T) {
+	c := newHitTestContext()
+	c.Scale(2, 2)
+	c.BeginPath()
+	c.Rect(10, 10, 20, 20)
+	c.SetStrokeWidth(4)
+	outline := c.StrokeOutline()
+
+	// The outline is in the local coordinates.
+	if bounds := outline.Bounds(); bounds != [4]float32{8, 8, 32, 32} {
+		t.Errorf("bounds of the outline should be [8 8 32 32], but %v", bounds)
+	}
+	paths, points := countSubPaths(outline.commands)
+	if paths != 2 || points != 8 {
+		t.Errorf("outline of the rect should be two rects, but %d sub-paths with %d points", paths, points)
+	}
+
+	h := newHitTestContext()
+	h.BeginPath()
+	h.appendCommand(append([]float32(nil), outline.commands...))
+	for _, testCase := range []struct {
+		x, y     float32
+		expected bool
+	}{
+		{9, 20, true},
+		{20, 20, false},
+		{31, 31, true},
+		{33, 20, false},
+	} {
+		if h.IsPointInFill(testCase.x, testCase.y) != testCase.expected {
+			t.Errorf("IsPointInFill(%v, %v) should be %v", testCase.x, testCase.y, testCase.expected)
+		}
+	}
+}
+
+func TestStrokeOutlineAlign(t *testing.T) {
+	c := newHitTestContext()
+	c.BeginPath()
+	c.Rect(10, 10, 20, 20)
+	c.SetStrokeWidth(4)
+
+	c.SetStrokeAlign(Inside)
+	if bounds := c.StrokeOutline().Bounds(); bounds != [4]float32{10, 10, 30, 30} {
+		t.Errorf("bounds of the inside stroke should be [10 10 30 30], but %v", bounds)
+	}
+	c.SetStrokeAlign(Outside)
+	if bounds := c.StrokeOutline().Bounds(); bounds != [4]float32{6, 6, 34, 34} {
+		t.Errorf("bounds of the outside stroke should be [6 6 34 34], but %v", bounds)
+	}
+
+	// Dashes of the closed path are aligned too.
+	p := NewPath()
+	p.Rect(10, 10, 20, 20)
+	dashed := p.StrokeOutline(&StrokeStyle{Width: 4, Align: Inside, LineCap: Butt, LineJoin: Miter, MiterLimit: 10, Dash: []float32{3, 3}})
+	if bounds := dashed.Bounds(); bounds[0] < 10 || bounds[1] < 10 || bounds[2] > 30 || bounds[3] > 30 {
+		t.Errorf("dashes of the inside stroke should be inside of the rect, but %v", bounds)
+	}
+}
+
+func TestPathStrokeOutline(t *testing.T) {
+	p := NewPath()
+	p.MoveTo(0, 0)
+	p.LineTo(20, 0)
+	p.LineTo(20, 20)
+
+	outline := p.StrokeOutline(&StrokeStyle{Width: 4, LineCap: Round, LineJoin: Miter, MiterLimit: 10})
+	h := newHitTestContext()
+	h.BeginPath()
+	h.appendCommand(append([]float32(nil), outline.commands...))
+	for _, testCase := range []struct {
+		x, y     float32
+		expected bool
+	}{
+		{-1.5, 0, true}, // round cap
+		{-1.5, 1.5, false},
+		{21.5, -1.5, true}, // miter join
+		{10, 1.5, true},
+		{10, 2.5, false},
+		{18.5, 10, true},
+	} {
+		if h.IsPointInFill(testCase.x, testCase.y) != testCase.expected {
+			t.Errorf("IsPointInFill(%v, %v) should be %v", testCase.x, testCase.y, testCase.expected)
+		}
+	}
+
+	dashed := p.StrokeOutline(&StrokeStyle{Width: 2, LineJoin: Bevel, Dash: []float32{5, 5}})
+	if paths, _ := countSubPaths(dashed.commands); paths != 4 {
+		t.Errorf("dashed outline should have 4 dashes, but %d", paths)
+	}
+}
+
+func TestStrokeOutlineLongPolyline(t *testing.T) {
+	// zigzag which overlaps the previous segments at every joint
+	p := NewPath()
+	p.MoveTo(0, 0)
+	for i := 1; i < 4000; i++ {
+		p.LineTo(float32(i), float32(i%2)*10)
+	}
+	outline := p.StrokeOutline(&StrokeStyle{Width: 2, LineCap: Butt, LineJoin: Bevel})
+	if paths, _ := countSubPaths(outline.commands); paths != 1 {
+		t.Errorf("outline of the zigzag should be one sub-path without holes, but %d sub-paths", paths)
+	}
+	if bounds := outline.Bounds(); bounds[0] >= 0 || bounds[1] >= 0 || bounds[2] <= 3999 || bounds[3] <= 10 {
+		t.Errorf("outline should cover the zigzag, but %v", bounds)
+	}
+}
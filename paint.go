@@ -102,3 +102,38 @@ func ImagePattern(cx, cy, w, h, angle float32, img int, alpha float32) Paint {
 		outerColor: color,
 	}
 }
+
+// Xform returns the transform of the paint. Gradients and image patterns are evaluated in the paint space.
+func (p *Paint) Xform() TransformMatrix {
+	return p.xform
+}
+
+// Extent returns the half size of the box gradient or the size of the image pattern.
+func (p *Paint) Extent() [2]float32 {
+	return p.extent
+}
+
+// Radius returns the corner radius of the box gradient.
+func (p *Paint) Radius() float32 {
+	return p.radius
+}
+
+// Feather returns the blur width of the gradient.
+func (p *Paint) Feather() float32 {
+	return p.feather
+}
+
+// InnerColor returns the inner (start) color of the gradient, or the solid color.
+func (p *Paint) InnerColor() Color {
+	return p.innerColor
+}
+
+// OuterColor returns the outer (end) color of the gradient.
+func (p *Paint) OuterColor() Color {
+	return p.outerColor
+}
+
+// Image returns the image handle of the image pattern, or 0.
+func (p *Paint) Image() int {
+	return p.image
+}
@@ -0,0 +1,503 @@
+package nanovgo
+
+// Path is a reusable path built once and drawn many times by Context.FillPath() and Context.StrokePath().
+//
+// The path is defined in its local coordinates and transformed by the current transform of the context when it is drawn.
+// It caches the flattened points and the expanded vertexes of the last fill and stroke. The cache is reused while
+// the path and the render parameters are unchanged and the transform is only moved or rotated, so static shapes
+// are not tessellated again every frame. Changing the scale, skew, stroke width, line cap, line join or miter limit
+// or dash pattern tessellates the path again.
+//
+// A Path should not be used by multiple goroutines at the same time.
+type Path struct {
+	commands []float32
+	commandX float32
+	commandY float32
+	fill     pathTessellation
+	stroke   pathTessellation
+
+	devicePxRatio float32 // device pixel ratio for the tolerance of the arcs, 0 means 1.0
+}
+
+// NewPath creates an empty path.
+func NewPath() *Path {
+	return &Path{}
+}
+
+// SetDevicePixelRatio sets the device pixel ratio of the screen to draw the path as same as Context.BeginFrame().
+// The tolerance to find the degenerate arcs of ArcTo() and EllipticalArcTo() becomes smaller on high DPI screens.
+// The default ratio is 1.0.
+func (p *Path) SetDevicePixelRatio(ratio float32) {
+	p.devicePxRatio = ratio
+}
+
+// distTol returns the distance tolerance as same as Context for the device pixel ratio.
+func (p *Path) distTol() float32 {
+	if p.devicePxRatio <= 0 {
+		return 0.01
+	}
+	return 0.01 / p.devicePxRatio
+}
+
+// Reset clears the sub-paths and the tessellation cache.
+func (p *Path) Reset() {
+	p.commands = p.commands[:0]
+	p.invalidate()
+}
+
+// MoveTo starts new sub-path with specified point as first point.
+func (p *Path) MoveTo(x, y float32) {
+	p.appendCommand([]float32{float32(nvgMOVETO), x, y})
+}
+
+// LineTo adds line segment from the last point in the path to the specified point.
+func (p *Path) LineTo(x, y float32) {
+	p.appendCommand([]float32{float32(nvgLINETO), x, y})
+}
+
+// BezierTo adds cubic bezier segment from last point in the path via two control points to the specified point.
+func (p *Path) BezierTo(c1x, c1y, c2x, c2y, x, y float32) {
+	p.appendCommand([]float32{float32(nvgBEZIERTO), c1x, c1y, c2x, c2y, x, y})
+}
+
+// QuadTo adds quadratic bezier segment from last point in the path via a control point to the specified point.
+func (p *Path) QuadTo(cx, cy, x, y float32) {
+	p.appendCommand(quadToCommands(p.commandX, p.commandY, cx, cy, x, y))
+}
+
+// Arc creates new circle arc shaped sub-path. See Context.Arc().
+func (p *Path) Arc(cx, cy, r, a0, a1 float32, dir Direction) {
+	move := nvgMOVETO
+	if len(p.commands) > 0 {
+		move = nvgLINETO
+	}
+	p.appendCommand(arcCommands(move, cx, cy, r, a0, a1, dir))
+}
+
+// ArcTo adds an arc segment at the corner defined by the last path point, and two specified points.
+func (p *Path) ArcTo(x1, y1, x2, y2, radius float32) {
+	if len(p.commands) == 0 {
+		return
+	}
+	p.appendCommand(arcToCommands(p.commandX, p.commandY, x1, y1, x2, y2, radius, p.distTol()))
+}
+
+// EllipticalArcTo adds an elliptical arc segment from the last path point to the specified point.
+// See Context.EllipticalArcTo().
+func (p *Path) EllipticalArcTo(rx, ry, xAxisRotation float32, largeArc, sweep bool, x, y float32) {
+	if len(p.commands) == 0 {
+		return
+	}
+	if commands := ellipticalArcToCommands(p.commandX, p.commandY, rx, ry, xAxisRotation, largeArc, sweep, x, y, p.distTol()); commands != nil {
+		p.appendCommand(commands)
+	}
+}
+
+// EllipseArc creates new ellipse arc shaped sub-path. See Context.EllipseArc().
+func (p *Path) EllipseArc(cx, cy, rx, ry, rotation, a0, a1 float32, dir Direction) {
+	move := nvgMOVETO
+	if len(p.commands) > 0 {
+		move = nvgLINETO
+	}
+	p.appendCommand(ellipseArcCommands(move, cx, cy, rx, ry, rotation, a0, a1, dir))
+}
+
+// Rect creates new rectangle shaped sub-path.
+func (p *Path) Rect(x, y, w, h float32) {
+	p.appendCommand(rectCommands(x, y, w, h))
+}
+
+// RoundedRect creates new rounded rectangle shaped sub-path.
+func (p *Path) RoundedRect(x, y, w, h, r float32) {
+	p.appendCommand(roundedRectCommands(x, y, w, h, r))
+}
+
+// Ellipse creates new ellipse shaped sub-path.
+func (p *Path) Ellipse(cx, cy, rx, ry float32) {
+	p.appendCommand(ellipseCommands(cx, cy, rx, ry))
+}
+
+// Circle creates new circle shaped sub-path.
+func (p *Path) Circle(cx, cy, r float32) {
+	p.Ellipse(cx, cy, r, r)
+}
+
+// ClosePath closes current sub-path with a line segment.
+func (p *Path) ClosePath() {
+	p.appendCommand([]float32{float32(nvgCLOSE)})
+}
+
+// PathWinding sets the current sub-path winding, see Winding.
+func (p *Path) PathWinding(winding Winding) {
+	p.appendCommand([]float32{float32(nvgWINDING), float32(winding)})
+}
+
+// Bounds returns the bounding box [minX, minY, maxX, maxY] of the path in its local coordinates.
+// Control points of the curves are included, so the box may be a little larger than the shape.
+func (p *Path) Bounds() [4]float32 {
+	bounds := [4]float32{1e6, 1e6, -1e6, -1e6}
+	addPoint := func(x, y float32) {
+		bounds = [4]float32{minF(bounds[0], x), minF(bounds[1], y), maxF(bounds[2], x), maxF(bounds[3], y)}
+	}
+	for i := 0; i < len(p.commands); {
+		switch nvgCommands(p.commands[i]) {
+		case nvgMOVETO, nvgLINETO:
+			addPoint(p.commands[i+1], p.commands[i+2])
+			i += 3
+		case nvgBEZIERTO:
+			addPoint(p.commands[i+1], p.commands[i+2])
+			addPoint(p.commands[i+3], p.commands[i+4])
+			addPoint(p.commands[i+5], p.commands[i+6])
+			i += 7
+		case nvgWINDING:
+			i += 2
+		default:
+			i++
+		}
+	}
+	if bounds[0] > bounds[2] {
+		return [4]float32{}
+	}
+	return bounds
+}
+
+func (p *Path) appendCommand(vals []float32) {
+	if nvgCommands(vals[0]) != nvgCLOSE && nvgCommands(vals[0]) != nvgWINDING {
+		p.commandX = vals[len(vals)-2]
+		p.commandY = vals[len(vals)-1]
+	}
+	p.commands = append(p.commands, vals...)
+	p.invalidate()
+}
+
+func (p *Path) invalidate() {
+	p.fill.valid = false
+	p.stroke.valid = false
+}
+
+// FillPath fills the path with current fill style. The current path of the context is not changed.
+func (c *Context) FillPath(p *Path) {
+	xform := c.getState().xform
+	if c.recording != nil || c.isVectorRenderer() {
+		c.drawPathCommands(p, xform, c.Fill)
+		return
+	}
+	key := tessellationKey{
+		context:     c,
+		fringeWidth: c.fringeWidth,
+		tessTol:     c.tessTol,
+		fillRule:    c.renderFillRule(),
+	}
+	paths, bounds := p.fill.tessellate(p.commands, xform, key, c.expandFill)
+	c.renderFill(paths, bounds)
+}
+
+// StrokePath draws the path with current stroke style. The current path of the context is not changed.
+func (c *Context) StrokePath(p *Path) {
+	state := c.getState()
+	if c.recording != nil || c.isVectorRenderer() {
+		c.drawPathCommands(p, state.xform, c.Stroke)
+		return
+	}
+	strokePaint, strokeWidth := c.strokeParams()
+	key := tessellationKey{
+		context:     c,
+		fringeWidth: c.fringeWidth,
+		tessTol:     c.tessTol,
+		fillRule:    c.renderFillRule(),
+		strokeWidth: strokeWidth,
+		strokeAlign: state.strokeAlign,
+		lineCap:     state.lineCap,
+		lineJoin:    state.lineJoin,
+		miterLimit:  state.miterLimit,
+		dash:        state.dash,
+		dashCount:   state.dashCount,
+		dashOffset:  state.dashOffset,
+	}
+	paths, _ := p.stroke.tessellate(p.commands, state.xform, key, func(cache *nvgPathCache) {
+		c.expandStroke(cache, strokeWidth)
+	})
+	c.renderStroke(&strokePaint, strokeWidth, paths)
+}
+
+func (c *Context) isVectorRenderer() bool {
+	_, ok := c.params.(VectorRenderer)
+	return ok
+}
+
+// drawPathCommands draws the path by the Context's path API without the tessellation cache. The current path
+// is swapped with the scratch buffers of the context, which are reused by the next call.
+func (c *Context) drawPathCommands(p *Path, xform TransformMatrix, draw func()) {
+	c.pathCommands = append(c.pathCommands[:0], p.commands...)
+	transformCommands(c.pathCommands, xform)
+	c.commands, c.pathCommands = c.pathCommands, c.commands
+	c.cache, c.pathCache = c.pathCache, c.cache
+	c.cache.clearPathCache()
+	draw()
+	c.commands, c.pathCommands = c.pathCommands, c.commands
+	c.cache, c.pathCache = c.pathCache, c.cache
+}
+
+// tessellationKey is the render parameters that change the expanded vertexes.
+type tessellationKey struct {
+	context     *Context
+	fringeWidth float32
+	tessTol     float32
+	fillRule    FillRule
+	strokeWidth float32
+	strokeAlign StrokeAlign
+	lineCap     LineCap
+	lineJoin    LineCap
+	miterLimit  float32
+	dash        [nvgMaxLineDash]float32
+	dashCount   int
+	dashOffset  float32
+}
+
+// pathTessellation is the cached result of flattenPaths() and expandFill() or expandStroke() in the window coordinates.
+type pathTessellation struct {
+	valid    bool
+	key      tessellationKey
+	xform    TransformMatrix
+	cache    nvgPathCache
+	commands []float32
+
+	// moved copy of the cache
+	paths    []RenderPath
+	vertexes []Vertex
+}
+
+// tessellate returns the expanded paths and bounds of the commands transformed by xform.
+func (t *pathTessellation) tessellate(commands []float32, xform TransformMatrix, key tessellationKey, expand func(cache *nvgPathCache)) ([]RenderPath, [4]float32) {
+	if t.valid && t.key == key {
+		if move, ok := rigidMotion(t.xform, xform); ok {
+			if move == IdentityMatrix() {
+				return t.cache.paths, t.cache.bounds
+			}
+			return t.move(move)
+		}
+	}
+	t.commands = append(t.commands[:0], commands...)
+	transformCommands(t.commands, xform)
+	t.cache.clearPathCache()
+	t.cache.flattenPaths(t.commands, key.context.tessTol, key.context.distTol, key.fillRule)
+	expand(&t.cache)
+	t.valid = true
+	t.key = key
+	t.xform = xform
+	return t.cache.paths, t.cache.bounds
+}
+
+// move returns the cached paths moved by the rigid motion m.
+func (t *pathTessellation) move(m TransformMatrix) ([]RenderPath, [4]float32) {
+	count := 0
+	for i := range t.cache.paths {
+		count += len(t.cache.paths[i].fills) + len(t.cache.paths[i].strokes)
+	}
+	if cap(t.vertexes) < count {
+		t.vertexes = make([]Vertex, count)
+	}
+	t.vertexes = t.vertexes[:0]
+	t.paths = append(t.paths[:0], t.cache.paths...)
+	for i := range t.paths {
+		path := &t.paths[i]
+		path.fills = t.moveVertexes(path.fills, m)
+		path.strokes = t.moveVertexes(path.strokes, m)
+	}
+
+	b := t.cache.bounds
+	bounds := [4]float32{1e6, 1e6, -1e6, -1e6}
+	for _, corner := range [4][2]float32{{b[0], b[1]}, {b[2], b[1]}, {b[2], b[3]}, {b[0], b[3]}} {
+		x, y := m.TransformPoint(corner[0], corner[1])
+		bounds = [4]float32{minF(bounds[0], x), minF(bounds[1], y), maxF(bounds[2], x), maxF(bounds[3], y)}
+	}
+	return t.paths, bounds
+}
+
+func (t *pathTessellation) moveVertexes(src []Vertex, m TransformMatrix) []Vertex {
+	offset := len(t.vertexes)
+	for _, v := range src {
+		v.x, v.y = m.TransformPoint(v.x, v.y)
+		t.vertexes = append(t.vertexes, v)
+	}
+	return t.vertexes[offset:len(t.vertexes):len(t.vertexes)]
+}
+
+// rigidMotion returns the transform from the old transform to the new one if it only moves and rotates the shape.
+func rigidMotion(old, new TransformMatrix) (TransformMatrix, bool) {
+	if old == new {
+		return IdentityMatrix(), true
+	}
+	m := old.Inverse().Multiply(new)
+	const eps = 1e-4
+	if absF(m[0]*m[0]+m[1]*m[1]-1) > eps || absF(m[2]*m[2]+m[3]*m[3]-1) > eps ||
+		absF(m[0]*m[2]+m[1]*m[3]) > eps || m[0]*m[3]-m[1]*m[2] < 0 {
+		return m, false
+	}
+	return m, true
+}
+
+func quadToCommands(x0, y0, cx, cy, x, y float32) []float32 {
+	return []float32{float32(nvgBEZIERTO),
+		x0 + 2.0/3.0*(cx-x0), y0 + 2.0/3.0*(cy-y0),
+		x + 2.0/3.0*(cx-x), y + 2.0/3.0*(cy-y),
+		x, y,
+	}
+}
+
+func arcCommands(move nvgCommands, cx, cy, r, a0, a1 float32, dir Direction) []float32 {
+	return ellipseArcCommands(move, cx, cy, r, r, 0, a0, a1, dir)
+}
+
+// ellipseArcCommands makes the arc of the ellipse whose x-axis is rotated by rotation. The angles are
+// the parametric angles of the ellipse before rotation.
+func ellipseArcCommands(move nvgCommands, cx, cy, rx, ry, rotation, a0, a1 float32, dir Direction) []float32 {
+	// Clamp angles
+	da := a1 - a0
+	if dir == Clockwise {
+		if absF(da) >= PI*2 {
+			da = PI * 2
+		} else {
+			for da < 0.0 {
+				da += PI * 2
+			}
+		}
+	} else {
+		if absF(da) >= PI*2 {
+			da = -PI * 2
+		} else {
+			for da > 0.0 {
+				da -= PI * 2
+			}
+		}
+	}
+	// Split arc into max 90 degree segments.
+	nDivs := clampI(int(absF(da)/(PI*0.5)+0.5), 1, 5)
+	hda := da / float32(nDivs) / 2.0
+	sin, cos := sinCosF(hda)
+	kappa := absF(4.0 / 3.0 * (1.0 - cos) / sin)
+
+	if dir == CounterClockwise {
+		kappa = -kappa
+	}
+	values := make([]float32, 0, 3+5*7+100)
+	var px, py, pTanX, pTanY float32
+	rotSin, rotCos := sinCosF(rotation)
+
+	for i := 0; i <= nDivs; i++ {
+		a := a0 + da*float32(i)/float32(nDivs)
+		dy, dx := sinCosF(a)
+		// Point and tangent on the ellipse before rotation
+		ex := dx * rx
+		ey := dy * ry
+		etX := -dy * rx * kappa
+		etY := dx * ry * kappa
+		x := cx + ex*rotCos - ey*rotSin
+		y := cy + ex*rotSin + ey*rotCos
+		tanX := etX*rotCos - etY*rotSin
+		tanY := etX*rotSin + etY*rotCos
+		if i == 0 {
+			values = append(values, float32(move), x, y)
+		} else {
+			values = append(values, float32(nvgBEZIERTO), px+pTanX, py+pTanY, x-tanX, y-tanY, x, y)
+		}
+		px = x
+		py = y
+		pTanX = tanX
+		pTanY = tanY
+	}
+	return values
+}
+
+// ellipticalArcToCommands makes the elliptical arc from (x0, y0) to (x, y). It returns nil if the end point is
+// same as the start point, and makes a line segment if the radius is too small.
+func ellipticalArcToCommands(x0, y0, rx, ry, rotation float32, largeArc, sweep bool, x, y, distTol float32) []float32 {
+	if ptEquals(x0, y0, x, y, distTol) {
+		return nil
+	}
+	if absF(rx) < distTol || absF(ry) < distTol {
+		return []float32{float32(nvgLINETO), x, y}
+	}
+	return ellipticalArcCommands(x0, y0, rx, ry, rotation, largeArc, sweep, x, y)
+}
+
+func arcToCommands(x0, y0, x1, y1, x2, y2, radius, distTol float32) []float32 {
+	// Handle degenerate cases.
+	if ptEquals(x0, y0, x1, y1, distTol) ||
+		ptEquals(x1, y1, x2, y2, distTol) ||
+		distPtSeg(x1, y1, x0, y0, x2, y2) < distTol*distTol ||
+		radius < distTol {
+		return []float32{float32(nvgLINETO), x1, y1}
+	}
+
+	// Calculate tangential circle to lines (x0,y0)-(x1,y1) and (x1,y1)-(x2,y2).
+	dx0 := x0 - x1
+	dy0 := y0 - y1
+	dx1 := x2 - x1
+	dy1 := y2 - y1
+	_, dx0, dy0 = normalize(dx0, dy0)
+	_, dx1, dy1 = normalize(dx1, dy1)
+	a := acosF(dx0*dx1 + dy0*dy1)
+	d := radius / tanF(a/2.0)
+
+	if d > 10000.0 {
+		return []float32{float32(nvgLINETO), x1, y1}
+	}
+	var cx, cy, a0, a1 float32
+	var dir Direction
+	if cross(dx0, dy0, dx1, dy1) > 0.0 {
+		cx = x1 + dx0*d + dy0*radius
+		cy = y1 + dy0*d + -dx0*radius
+		a0 = atan2F(dx0, -dy0)
+		a1 = atan2F(-dx1, dy1)
+		dir = Clockwise
+	} else {
+		cx = x1 + dx0*d + -dy0*radius
+		cy = y1 + dy0*d + dx0*radius
+		a0 = atan2F(-dx0, dy0)
+		a1 = atan2F(dx1, -dy1)
+		dir = CounterClockwise
+	}
+	return arcCommands(nvgLINETO, cx, cy, radius, a0, a1, dir)
+}
+
+func rectCommands(x, y, w, h float32) []float32 {
+	return []float32{
+		float32(nvgMOVETO), x, y,
+		float32(nvgLINETO), x, y + h,
+		float32(nvgLINETO), x + w, y + h,
+		float32(nvgLINETO), x + w, y,
+		float32(nvgCLOSE),
+	}
+}
+
+func roundedRectCommands(x, y, w, h, r float32) []float32 {
+	if r < 0.1 {
+		return rectCommands(x, y, w, h)
+	}
+	rx := minF(r, absF(w)*0.5) * signF(w)
+	ry := minF(r, absF(h)*0.5) * signF(h)
+	return []float32{
+		float32(nvgMOVETO), x, y + ry,
+		float32(nvgLINETO), x, y + h - ry,
+		float32(nvgBEZIERTO), x, y + h - ry*(1-Kappa90), x + rx*(1-Kappa90), y + h, x + rx, y + h,
+		float32(nvgLINETO), x + w - rx, y + h,
+		float32(nvgBEZIERTO), x + w - rx*(1-Kappa90), y + h, x + w, y + h - ry*(1-Kappa90), x + w, y + h - ry,
+		float32(nvgLINETO), x + w, y + ry,
+		float32(nvgBEZIERTO), x + w, y + ry*(1-Kappa90), x + w - rx*(1-Kappa90), y, x + w - rx, y,
+		float32(nvgLINETO), x + rx, y,
+		float32(nvgBEZIERTO), x + rx*(1-Kappa90), y, x, y + ry*(1-Kappa90), x, y + ry,
+		float32(nvgCLOSE),
+	}
+}
+
+func ellipseCommands(cx, cy, rx, ry float32) []float32 {
+	return []float32{
+		float32(nvgMOVETO), cx - rx, cy,
+		float32(nvgBEZIERTO), cx - rx, cy + ry*Kappa90, cx - rx*Kappa90, cy + ry, cx, cy + ry,
+		float32(nvgBEZIERTO), cx + rx*Kappa90, cy + ry, cx + rx, cy + ry*Kappa90, cx + rx, cy,
+		float32(nvgBEZIERTO), cx + rx, cy - ry*Kappa90, cx + rx*Kappa90, cy - ry, cx, cy - ry,
+		float32(nvgBEZIERTO), cx - rx*Kappa90, cy - ry, cx - rx, cy - ry*Kappa90, cx - rx, cy,
+		float32(nvgCLOSE),
+	}
+}
@@ -0,0 +1,218 @@
+package nanovgo
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func buildSamplePath(p interface {
+	MoveTo(x, y float32)
+	LineTo(x, y float32)
+	QuadTo(cx, cy, x, y float32)
+	ArcTo(x1, y1, x2, y2, radius float32)
+	Circle(cx, cy, r float32)
+	ClosePath()
+}) {
+	p.MoveTo(2, 2)
+	p.LineTo(14, 2)
+	p.ArcTo(14, 14, 2, 14, 4)
+	p.QuadTo(8, 18, 2, 14)
+	p.ClosePath()
+	p.Circle(24, 24, 5)
+}
+
+func drawPathSample(c *Context, path *Path, x, y float32) {
+	c.Save()
+	c.Translate(x, y)
+	c.SetFillColor(RGBA(255, 0, 0, 255))
+	c.SetStrokeColor(RGBA(0, 0, 255, 255))
+	c.SetStrokeWidth(1.5)
+	if path != nil {
+		c.FillPath(path)
+		c.StrokePath(path)
+	} else {
+		c.BeginPath()
+		buildSamplePath(c)
+		c.Fill()
+		c.Stroke()
+	}
+	c.Restore()
+}
+
+func TestPathSameAsContextPath(t *testing.T) {
+	direct := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	c1, _ := NewSoftwareContext(direct, AntiAlias)
+	defer c1.Delete()
+	c1.BeginFrame(64, 64, 1.0)
+	drawPathSample(c1, nil, 0, 0)
+	drawPathSample(c1, nil, 30, 30)
+	c1.EndFrame()
+
+	cached := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	c2, _ := NewSoftwareContext(cached, AntiAlias)
+	defer c2.Delete()
+	path := NewPath()
+	buildSamplePath(path)
+	c2.BeginFrame(64, 64, 1.0)
+	c2.BeginPath()
+	c2.MoveTo(1, 1)
+	drawPathSample(c2, path, 0, 0)
+	drawPathSample(c2, path, 30, 30)
+	if len(c2.commands) != 3 {
+		t.Error("FillPath() and StrokePath() should keep the current path of the context")
+	}
+	c2.EndFrame()
+
+	if !bytes.Equal(direct.Pix, cached.Pix) {
+		t.Error("drawing Path should be same as drawing the current path")
+	}
+}
+
+func TestPathTessellationCache(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+	path := NewPath()
+	path.Rect(0, 0, 10, 10)
+
+	c.BeginFrame(64, 64, 1.0)
+	c.FillPath(path)
+	points := &path.fill.cache.points[0]
+	c.Translate(20, 0)
+	c.Rotate(PI / 4)
+	c.FillPath(path)
+	if !path.fill.valid || &path.fill.cache.points[0] != points || path.fill.xform != IdentityMatrix() {
+		t.Error("moved and rotated path should reuse the tessellation")
+	}
+	c.Scale(2, 2)
+	c.FillPath(path)
+	if path.fill.xform == IdentityMatrix() {
+		t.Error("scaled path should be tessellated again")
+	}
+	xform := path.fill.xform
+	path.LineTo(5, 20)
+	if path.fill.valid {
+		t.Error("modifying path should invalidate the tessellation")
+	}
+	c.FillPath(path)
+	if !path.fill.valid || path.fill.xform != xform {
+		t.Error("modified path should be tessellated again")
+	}
+
+	c.ResetTransform()
+	c.SetStrokeWidth(2)
+	c.StrokePath(path)
+	key := path.stroke.key
+	c.SetStrokeWidth(4)
+	c.StrokePath(path)
+	if path.stroke.key == key {
+		t.Error("changing stroke width should tessellate the path again")
+	}
+	c.EndFrame()
+}
+
+func TestPathEllipseArc(t *testing.T) {
+	for _, testCase := range []struct {
+		rotation         float32
+		startX, startY   float32
+		middleX, middleY float32
+		endX, endY       float32
+	}{
+		{0, 20, 0, 0, 10, -20, 0},
+		{PI / 2, 0, 20, -10, 0, 0, -20},
+	} {
+		path := NewPath()
+		path.EllipseArc(0, 0, 20, 10, testCase.rotation, 0, PI, Clockwise)
+		measure := path.Measure()
+		for i, expected := range [][2]float32{
+			{testCase.startX, testCase.startY},
+			{testCase.middleX, testCase.middleY},
+			{testCase.endX, testCase.endY},
+		} {
+			x, y, _ := measure.PointAt(measure.Length() * float32(i) / 2)
+			if absF(x-expected[0]) > 0.1 || absF(y-expected[1]) > 0.1 {
+				t.Errorf("rotation %v: point %d should be %v, but (%v, %v)", testCase.rotation, i, expected, x, y)
+			}
+		}
+		// Half of the perimeter by Ramanujan's approximation
+		expected := PI * (3*30 - sqrtF(70*50)) / 2
+		if absF(measure.Length()-expected) > 0.1 {
+			t.Errorf("rotation %v: length should be %v, but %v", testCase.rotation, expected, measure.Length())
+		}
+	}
+}
+
+func TestPathEllipticalArcTo(t *testing.T) {
+	path := NewPath()
+	path.MoveTo(0, 0)
+	path.EllipticalArcTo(20, 10, 0, false, true, 40, 0)
+	measure := path.Measure()
+	// Positive sweep is clockwise on screen, so the arc goes through negative y.
+	if x, y, _ := measure.PointAt(measure.Length() / 2); absF(x-20) > 0.1 || absF(y+10) > 0.1 {
+		t.Errorf("arc should go through (20, -10), but (%v, %v)", x, y)
+	}
+	if path.commandX != 40 || path.commandY != 0 {
+		t.Errorf("arc should end at (40, 0), but (%v, %v)", path.commandX, path.commandY)
+	}
+
+	// Radii are scaled up to reach the end point.
+	path.Reset()
+	path.MoveTo(0, 0)
+	path.EllipticalArcTo(5, 5, 0, true, false, 0, 20)
+	if bounds := path.Measure().SubPath(0, 1e6).Bounds(); absF(bounds[0]+10) > 0.1 || absF(bounds[2]) > 0.1 {
+		t.Errorf("arc should be a half circle at left side, but bounds %v", bounds)
+	}
+
+	// Degenerated arcs
+	path.Reset()
+	path.MoveTo(0, 0)
+	path.EllipticalArcTo(10, 10, 0, false, true, 0, 0)
+	path.EllipticalArcTo(0, 10, 0, false, true, 10, 0)
+	if paths, points := countSubPaths(path.commands); paths != 1 || points != 2 {
+		t.Errorf("degenerated arcs should be ignored or replaced by a line, but %d sub-paths with %d points", paths, points)
+	}
+}
+
+func TestPathArcToTolerance(t *testing.T) {
+	// The corner is smaller than the tolerance at the device pixel ratio 1.0.
+	for _, testCase := range []struct {
+		ratio float32
+		arc   bool
+	}{
+		{0, false},
+		{1, false},
+		{4, true},
+	} {
+		path := NewPath()
+		path.SetDevicePixelRatio(testCase.ratio)
+		path.MoveTo(0, 0)
+		path.ArcTo(0.008, 0, 0.008, 0.008, 0.004)
+		if arc := len(path.commands) > 6; arc != testCase.arc {
+			t.Errorf("ratio %v: ArcTo() should add the arc %v, but commands %v", testCase.ratio, testCase.arc, path.commands)
+		}
+	}
+}
+
+func TestFillPathScratchCache(t *testing.T) {
+	c, _ := NewSoftwareContext(image.NewRGBA(image.Rect(0, 0, 32, 32)), AntiAlias)
+	defer c.Delete()
+	path := NewPath()
+	path.Rect(4, 4, 8, 8)
+
+	c.BeginFrame(32, 32, 1.0)
+	c.BeginPath()
+	c.Circle(16, 16, 8)
+	c.BeginRecording()
+	c.FillPath(path)
+	points := &c.pathCache.points[0]
+	c.StrokePath(path)
+	if &c.pathCache.points[0] != points {
+		t.Error("scratch cache should be reused by FillPath() and StrokePath()")
+	}
+	list := c.EndRecording()
+	if list.Len() != 2 || len(c.commands) != 3+7*4+1 {
+		t.Errorf("path should be recorded without changing the current path, but %d calls and %d commands", list.Len(), len(c.commands))
+	}
+	c.EndFrame()
+}
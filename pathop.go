@@ -0,0 +1,527 @@
+package nanovgo
+
+import (
+	"math"
+	"sort"
+)
+
+// Combine returns a new path made by the boolean operation between the path and other. Both paths are in the same
+// local coordinates and their insides are decided by the non-zero fill rule and PathWinding() as same as Fill().
+//
+// Curves are flattened with the tolerance for the device pixel ratio 1.0, so the result has only line segments.
+// Its sub-paths are closed outlines with Solid winding and holes with Hole winding, so it can be filled, stroked
+// and combined again.
+func (p *Path) Combine(other *Path, op PathOp) *Path {
+	result := NewPath()
+	result.commands = combineCommands(p.commands, other.commands, op, 0.25, 0.01)
+	result.commandX, result.commandY = lastCommandPoint(result.commands)
+	return result
+}
+
+// CombinePath replaces the current path with the result of the boolean operation between the current path and
+// the path transformed by the current transform. See Path.Combine().
+func (c *Context) CombinePath(other *Path, op PathOp) {
+	xform := c.getState().xform
+	commands := append([]float32(nil), other.commands...)
+	transformCommands(commands, xform)
+	c.commands = combineCommands(c.commands, commands, op, c.tessTol, c.distTol)
+	c.cache.clearPathCache()
+	x, y := lastCommandPoint(c.commands)
+	c.commandX, c.commandY = xform.Inverse().TransformPoint(x, y)
+}
+
+func (op PathOp) contains(insideA, insideB bool) bool {
+	switch op {
+	case PathUnion:
+		return insideA || insideB
+	case PathIntersect:
+		return insideA && insideB
+	case PathDifference:
+		return insideA && !insideB
+	case PathXor:
+		return insideA != insideB
+	}
+	return false
+}
+
+type pathOpPoint struct {
+	x, y float64
+}
+
+// pathOpPolygon is a closed polygon.
+type pathOpPolygon struct {
+	points []pathOpPoint
+}
+
+func newPathOpPolygon(points []pathOpPoint) pathOpPolygon {
+	return pathOpPolygon{points: points}
+}
+
+type pathOpSegment struct {
+	p0, p1 pathOpPoint
+	params []float64 // Positions of the intersections on the segment.
+}
+
+// bounds returns the bounding box of the segment expanded by tol.
+func (s *pathOpSegment) bounds(tol float64) [4]float64 {
+	return [4]float64{
+		math.Min(s.p0.x, s.p1.x) - tol,
+		math.Min(s.p0.y, s.p1.y) - tol,
+		math.Max(s.p0.x, s.p1.x) + tol,
+		math.Max(s.p0.y, s.p1.y) + tol,
+	}
+}
+
+func combineCommands(a, b []float32, op PathOp, tessTol, distTol float32) []float32 {
+	return combinePolygons(flattenPolygons(a, tessTol, distTol), flattenPolygons(b, tessTol, distTol), op, distTol)
+}
+
+// combinePolygons splits the edges of both polygons at all intersections and keeps the edges that have
+// the inside of the result only on one side. The kept edges are directed to have the inside on the left
+// on screen and linked into closed loops.
+func combinePolygons(polygonsA, polygonsB []pathOpPolygon, op PathOp, distTol float32) []float32 {
+	snapTol := float64(distTol) * 0.1
+
+	var segments []pathOpSegment
+	for _, polygons := range [][]pathOpPolygon{polygonsA, polygonsB} {
+		for _, polygon := range polygons {
+			points := polygon.points
+			for i := range points {
+				segments = append(segments, pathOpSegment{p0: points[i], p1: points[(i+1)%len(points)]})
+			}
+		}
+	}
+	// Self-intersections are also split to decide the inside of each piece. The segments are sorted to link
+	// the result from the left.
+	sort.Slice(segments, func(i, j int) bool {
+		return math.Min(segments[i].p0.x, segments[i].p1.x) < math.Min(segments[j].p0.x, segments[j].p1.x)
+	})
+	boxes := make([][4]float64, len(segments))
+	for i := range segments {
+		boxes[i] = segments[i].bounds(snapTol)
+	}
+	newPathOpGrid(boxes).pairs(func(i, j int) {
+		splitSegments(&segments[i], &segments[j], snapTol)
+	})
+
+	// Split edges into the pieces between the vertexes and keep the pieces on the border of the result.
+	// Both sides of each piece are tested at its middle point on the original edge, so the test is not
+	// affected by moving the vertexes. Overlapped pieces merged into the same vertexes are tested on every
+	// original edge, and they are kept only if all tests agree on the direction.
+	indexA, indexB := newPathOpIndex(polygonsA), newPathOpIndex(polygonsB)
+	inside := func(x, y float64) bool {
+		return op.contains(indexA.winding(x, y) != 0, indexB.winding(x, y) != 0)
+	}
+	offset := math.Max(pathOpSideOffset, snapTol*2.0)
+	vertexes := pathOpVertexes{tol: snapTol, grid: make(map[[2]int64][]int)}
+	var pieces []pathOpPiece
+	known := make(map[[2]int]int)
+	for i := range segments {
+		s := &segments[i]
+		dx, dy := s.p1.x-s.p0.x, s.p1.y-s.p0.y
+		l := math.Hypot(dx, dy)
+		if l == 0.0 {
+			continue
+		}
+		nx, ny := -dy/l*offset, dx/l*offset
+		s.params = append(s.params, 0.0, 1.0)
+		sort.Float64s(s.params)
+		prev, prevT := -1, 0.0
+		for _, t := range s.params {
+			v := vertexes.find(s.p0.x+dx*t, s.p0.y+dy*t)
+			if prev >= 0 && prev != v {
+				key := [2]int{prev, v}
+				if prev > v {
+					key = [2]int{v, prev}
+				}
+				mt := (prevT + t) * 0.5
+				mx, my := s.p0.x+dx*mt, s.p0.y+dy*mt
+				side0, side1 := inside(mx+nx, my+ny), inside(mx-nx, my-ny)
+				e := [2]int{prev, v}
+				if side0 {
+					e = [2]int{v, prev}
+				}
+				index, ok := known[key]
+				if !ok {
+					known[key] = len(pieces)
+					pieces = append(pieces, pathOpPiece{edge: e, border: side0 != side1})
+				} else if side0 == side1 || pieces[index].edge != e {
+					pieces[index].border = false
+				}
+			}
+			prev, prevT = v, t
+		}
+	}
+	var kept [][2]int
+	outgoing := make(map[int][]int)
+	for _, piece := range pieces {
+		if piece.border {
+			outgoing[piece.edge[0]] = append(outgoing[piece.edge[0]], len(kept))
+			kept = append(kept, piece.edge)
+		}
+	}
+
+	// Link the edges into loops.
+	var commands []float32
+	used := make([]bool, len(kept))
+	for i := range kept {
+		if used[i] {
+			continue
+		}
+		var loop []pathOpPoint
+		for e := i; e >= 0 && !used[e]; {
+			used[e] = true
+			loop = append(loop, vertexes.points[kept[e][0]])
+			e = nextPathOpEdge(kept, outgoing, used, vertexes.points, e)
+		}
+		loop = simplifyLoop(loop, snapTol)
+		if len(loop) < 3 {
+			continue
+		}
+		var area float64
+		for j := range loop {
+			p0, p1 := loop[j], loop[(j+1)%len(loop)]
+			area += p1.x*p0.y - p0.x*p1.y
+		}
+		for j, pt := range loop {
+			command := nvgLINETO
+			if j == 0 {
+				command = nvgMOVETO
+			}
+			commands = append(commands, float32(command), float32(pt.x), float32(pt.y))
+		}
+		// Same sign as polyArea(). Solid paths have positive area.
+		winding := Solid
+		if area < 0.0 {
+			winding = Hole
+		}
+		commands = append(commands, float32(nvgCLOSE), float32(nvgWINDING), float32(winding))
+	}
+	return commands
+}
+
+// nextPathOpEdge returns the unused edge from the end of the edge e. If there are multiple edges, the edge turns
+// most to the inside is selected to keep the loops touching at the vertex separated.
+func nextPathOpEdge(edges [][2]int, outgoing map[int][]int, used []bool, points []pathOpPoint, e int) int {
+	from, to := points[edges[e][0]], points[edges[e][1]]
+	dx, dy := to.x-from.x, to.y-from.y
+	next := -1
+	var minAngle float64
+	for _, candidate := range outgoing[edges[e][1]] {
+		if used[candidate] {
+			continue
+		}
+		end := points[edges[candidate][1]]
+		ndx, ndy := end.x-to.x, end.y-to.y
+		angle := math.Atan2(dx*ndy-dy*ndx, dx*ndx+dy*ndy)
+		if next < 0 || angle < minAngle {
+			next, minAngle = candidate, angle
+		}
+	}
+	return next
+}
+
+// simplifyLoop removes the points on the straight lines made by splitting the edges.
+func simplifyLoop(loop []pathOpPoint, tol float64) []pathOpPoint {
+	for removed := true; removed && len(loop) >= 3; {
+		removed = false
+		for i := 0; i < len(loop) && len(loop) >= 3; i++ {
+			p0, p1, p2 := loop[(i+len(loop)-1)%len(loop)], loop[i], loop[(i+1)%len(loop)]
+			dx0, dy0 := p1.x-p0.x, p1.y-p0.y
+			dx1, dy1 := p2.x-p1.x, p2.y-p1.y
+			l := math.Hypot(p2.x-p0.x, p2.y-p0.y)
+			if math.Abs(dx0*dy1-dy0*dx1) <= tol*l && dx0*dx1+dy0*dy1 >= 0.0 {
+				loop = append(loop[:i], loop[i+1:]...)
+				removed = true
+				i--
+			}
+		}
+	}
+	return loop
+}
+
+// pathOpSideOffset is the minimum distance from the edge to the points to test the both sides of the edge.
+// The distance is also at least twice of the snap tolerance to test outside of the edges merged into the same
+// vertexes.
+const pathOpSideOffset = 1e-6
+
+// pathOpPiece is a piece of the edges between two vertexes. edge is directed to have the inside on the left.
+type pathOpPiece struct {
+	edge   [2]int
+	border bool
+}
+
+// splitSegments records the intersection of two segments. The end points on the other segment also split it,
+// so overlapped segments are split into the same pieces.
+func splitSegments(s, u *pathOpSegment, tol float64) {
+	if math.Max(s.p0.x, s.p1.x)+tol < math.Min(u.p0.x, u.p1.x) || math.Max(u.p0.x, u.p1.x)+tol < math.Min(s.p0.x, s.p1.x) ||
+		math.Max(s.p0.y, s.p1.y)+tol < math.Min(u.p0.y, u.p1.y) || math.Max(u.p0.y, u.p1.y)+tol < math.Min(s.p0.y, s.p1.y) {
+		return
+	}
+	d1x, d1y := s.p1.x-s.p0.x, s.p1.y-s.p0.y
+	d2x, d2y := u.p1.x-u.p0.x, u.p1.y-u.p0.y
+	l1, l2 := math.Hypot(d1x, d1y), math.Hypot(d2x, d2y)
+	if l1 == 0.0 || l2 == 0.0 {
+		return
+	}
+	s.splitAt(u.p0, l1, tol)
+	s.splitAt(u.p1, l1, tol)
+	u.splitAt(s.p0, l2, tol)
+	u.splitAt(s.p1, l2, tol)
+
+	// Crossing of the segments. Almost parallel segments are split only by the end points.
+	denom := d1x*d2y - d1y*d2x
+	if math.Abs(denom) <= 1e-6*l1*l2 {
+		return
+	}
+	ex, ey := u.p0.x-s.p0.x, u.p0.y-s.p0.y
+	t := (ex*d2y - ey*d2x) / denom
+	v := (ex*d1y - ey*d1x) / denom
+	if t > 0.0 && t < 1.0 && v > 0.0 && v < 1.0 {
+		s.params = append(s.params, t)
+		u.params = append(u.params, v)
+	}
+}
+
+// splitAt splits the segment at the point if the point is on the segment.
+func (s *pathOpSegment) splitAt(p pathOpPoint, l, tol float64) {
+	dx, dy := s.p1.x-s.p0.x, s.p1.y-s.p0.y
+	t := ((p.x-s.p0.x)*dx + (p.y-s.p0.y)*dy) / (l * l)
+	if t <= 0.0 || t >= 1.0 {
+		return
+	}
+	if math.Abs((p.x-s.p0.x)*dy-(p.y-s.p0.y)*dx)/l <= tol {
+		s.params = append(s.params, t)
+	}
+}
+
+// pathOpVertexes merges the points closer than tol into one vertex.
+type pathOpVertexes struct {
+	tol    float64
+	points []pathOpPoint
+	grid   map[[2]int64][]int
+}
+
+func (v *pathOpVertexes) find(x, y float64) int {
+	kx, ky := int64(math.Floor(x/v.tol)), int64(math.Floor(y/v.tol))
+	for i := kx - 1; i <= kx+1; i++ {
+		for j := ky - 1; j <= ky+1; j++ {
+			for _, index := range v.grid[[2]int64{i, j}] {
+				p := v.points[index]
+				if (p.x-x)*(p.x-x)+(p.y-y)*(p.y-y) <= v.tol*v.tol {
+					return index
+				}
+			}
+		}
+	}
+	index := len(v.points)
+	v.points = append(v.points, pathOpPoint{x, y})
+	key := [2]int64{kx, ky}
+	v.grid[key] = append(v.grid[key], index)
+	return index
+}
+
+// flattenPolygons flattens the path commands into closed polygons with the enforced windings.
+func flattenPolygons(commands []float32, tessTol, distTol float32) []pathOpPolygon {
+	var cache nvgPathCache
+	cache.flattenPaths(commands, tessTol, distTol, NonZero)
+	var polygons []pathOpPolygon
+	for i := range cache.paths {
+		path := &cache.paths[i]
+		if path.count < 3 {
+			continue
+		}
+		polygon := make([]pathOpPoint, path.count)
+		for j, point := range cache.points[path.first : path.first+path.count] {
+			polygon[j] = pathOpPoint{float64(point.x), float64(point.y)}
+		}
+		polygons = append(polygons, newPathOpPolygon(polygon))
+	}
+	return polygons
+}
+
+// pathOpIndex finds the edges of the polygons around the point by the grid of the edges.
+type pathOpIndex struct {
+	edges []pathOpSegment
+	grid  *pathOpGrid
+}
+
+func newPathOpIndex(polygons []pathOpPolygon) *pathOpIndex {
+	idx := &pathOpIndex{}
+	for _, polygon := range polygons {
+		points := polygon.points
+		for i := range points {
+			idx.edges = append(idx.edges, pathOpSegment{p0: points[i], p1: points[(i+1)%len(points)]})
+		}
+	}
+	boxes := make([][4]float64, len(idx.edges))
+	for i := range idx.edges {
+		boxes[i] = idx.edges[i].bounds(0.0)
+	}
+	idx.grid = newPathOpGrid(boxes)
+	return idx
+}
+
+// winding returns the winding number of the polygons around the point. It counts the edges crossing the ray
+// from the point in the cells along the ray. The ray is cast to +x, or to +y if the grid is wider than tall.
+// An edge in multiple cells is counted only in the cell of the crossing.
+func (idx *pathOpIndex) winding(x, y float64) int {
+	g := idx.grid
+	if len(g.cells) == 0 {
+		return 0
+	}
+	cx, cy := g.cell(x, y)
+	// The vertical ray is same as the horizontal ray with the swapped axes, which flip the winding.
+	vertical := g.rows < g.cols
+	count, px, py := g.cols-cx, x, y
+	if vertical {
+		count, px, py = g.rows-cy, y, x
+	}
+	winding := 0
+	for k := 0; k < count; k++ {
+		cell := cy*g.cols + cx + k
+		if vertical {
+			cell = (cy+k)*g.cols + cx
+		}
+		for _, i := range g.cells[cell] {
+			p0, p1 := idx.edges[i].p0, idx.edges[i].p1
+			if vertical {
+				p0, p1 = pathOpPoint{p0.y, p0.x}, pathOpPoint{p1.y, p1.x}
+			}
+			side := (p1.x-p0.x)*(py-p0.y) - (px-p0.x)*(p1.y-p0.y)
+			dir := 0
+			if p0.y <= py {
+				if p1.y > py && side > 0.0 {
+					dir = 1
+				}
+			} else if p1.y <= py && side < 0.0 {
+				dir = -1
+			}
+			if dir == 0 {
+				continue
+			}
+			crossing := p0.x + (p1.x-p0.x)*(py-p0.y)/(p1.y-p0.y)
+			var at int
+			if vertical {
+				_, at = g.cell(x, crossing)
+				at -= cy
+			} else {
+				at, _ = g.cell(crossing, y)
+				at -= cx
+			}
+			if maxI(at, 0) == k {
+				winding += dir
+			}
+		}
+	}
+	if vertical {
+		return -winding
+	}
+	return winding
+}
+
+// pathOpGrid is a uniform grid of the bounding boxes (minX, minY, maxX, maxY). Each cell keeps the indexes of
+// the boxes overlapping it.
+type pathOpGrid struct {
+	boxes      [][4]float64
+	minX, minY float64
+	size       float64
+	cols, rows int
+	cells      [][]int
+}
+
+// newPathOpGrid makes the grid with the cells as large as the average box. The cells are enlarged to keep
+// the number of the cells proportional to the number of the boxes.
+func newPathOpGrid(boxes [][4]float64) *pathOpGrid {
+	g := &pathOpGrid{boxes: boxes}
+	if len(boxes) == 0 {
+		return g
+	}
+	bounds := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	var total float64
+	for _, b := range boxes {
+		bounds = [4]float64{math.Min(bounds[0], b[0]), math.Min(bounds[1], b[1]), math.Max(bounds[2], b[2]), math.Max(bounds[3], b[3])}
+		total += math.Max(b[2]-b[0], b[3]-b[1])
+	}
+	g.minX, g.minY = bounds[0], bounds[1]
+	g.size = total / float64(len(boxes))
+	if g.size <= 0.0 {
+		g.size = 1.0
+	}
+	for {
+		g.cols = int((bounds[2]-bounds[0])/g.size) + 1
+		g.rows = int((bounds[3]-bounds[1])/g.size) + 1
+		if g.cols*g.rows <= len(boxes)*4+16 {
+			break
+		}
+		g.size *= 2.0
+	}
+	g.cells = make([][]int, g.cols*g.rows)
+	for i, b := range boxes {
+		x0, y0, x1, y1 := g.cellRange(b)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				g.cells[y*g.cols+x] = append(g.cells[y*g.cols+x], i)
+			}
+		}
+	}
+	return g
+}
+
+func (g *pathOpGrid) cell(x, y float64) (int, int) {
+	cx := int(math.Floor((x - g.minX) / g.size))
+	cy := int(math.Floor((y - g.minY) / g.size))
+	return maxI(0, minI(cx, g.cols-1)), maxI(0, minI(cy, g.rows-1))
+}
+
+func (g *pathOpGrid) cellRange(b [4]float64) (x0, y0, x1, y1 int) {
+	x0, y0 = g.cell(b[0], b[1])
+	x1, y1 = g.cell(b[2], b[3])
+	return
+}
+
+// pairs calls f once for each pair of the overlapping boxes (i < j).
+func (g *pathOpGrid) pairs(f func(i, j int)) {
+	for i, b := range g.boxes {
+		x0, y0, x1, y1 := g.cellRange(b)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				for _, j := range g.cells[y*g.cols+x] {
+					if j <= i {
+						continue
+					}
+					u := g.boxes[j]
+					if b[2] < u[0] || u[2] < b[0] || b[3] < u[1] || u[3] < b[1] {
+						continue
+					}
+					// The pair is visited only in the first cell of the overlap.
+					ux0, uy0, _, _ := g.cellRange(u)
+					if x == maxI(x0, ux0) && y == maxI(y0, uy0) {
+						f(i, j)
+					}
+				}
+			}
+		}
+	}
+}
+
+// lastCommandPoint returns the start point of the last sub-path.
+func lastCommandPoint(commands []float32) (float32, float32) {
+	var x, y float32
+	for i := 0; i < len(commands); {
+		switch nvgCommands(commands[i]) {
+		case nvgMOVETO:
+			x, y = commands[i+1], commands[i+2]
+			i += 3
+		case nvgLINETO:
+			i += 3
+		case nvgBEZIERTO:
+			i += 7
+		case nvgWINDING:
+			i += 2
+		default:
+			i++
+		}
+	}
+	return x, y
+}
@@ -0,0 +1,225 @@
+package nanovgo
+
+import (
+	"math"
+	"testing"
+)
+
+func countSubPaths(commands []float32) (paths, points int) {
+	for i := 0; i < len(commands); {
+		switch nvgCommands(commands[i]) {
+		case nvgMOVETO:
+			paths++
+			points++
+			i += 3
+		case nvgLINETO:
+			points++
+			i += 3
+		case nvgBEZIERTO:
+			i += 7
+		case nvgWINDING:
+			i += 2
+		default:
+			i++
+		}
+	}
+	return
+}
+
+func TestCombinePath(t *testing.T) {
+	other := NewPath()
+	other.Rect(10, 10, 20, 20)
+
+	for _, testCase := range []struct {
+		op              PathOp
+		paths, points   int
+		inside, outside [][2]float32
+	}{
+		{PathUnion, 1, 8, [][2]float32{{5, 5}, {15, 15}, {25, 25}}, [][2]float32{{25, 5}, {5, 25}}},
+		{PathIntersect, 1, 4, [][2]float32{{15, 15}}, [][2]float32{{5, 5}, {25, 25}}},
+		{PathDifference, 1, 6, [][2]float32{{5, 5}, {15, 5}}, [][2]float32{{15, 15}, {25, 25}}},
+		{PathXor, 2, 12, [][2]float32{{5, 5}, {25, 25}}, [][2]float32{{15, 15}, {25, 5}}},
+	} {
+		c := newHitTestContext()
+		c.BeginPath()
+		c.Rect(0, 0, 20, 20)
+		c.CombinePath(other, testCase.op)
+		paths, points := countSubPaths(c.commands)
+		if paths != testCase.paths || points != testCase.points {
+			t.Errorf("op %d should make %d sub-paths with %d points, but %d, %d", testCase.op, testCase.paths, testCase.points, paths, points)
+		}
+		for _, pt := range testCase.inside {
+			if !c.IsPointInFill(pt[0], pt[1]) {
+				t.Errorf("op %d: %v should be inside", testCase.op, pt)
+			}
+		}
+		for _, pt := range testCase.outside {
+			if c.IsPointInFill(pt[0], pt[1]) {
+				t.Errorf("op %d: %v should be outside", testCase.op, pt)
+			}
+		}
+	}
+}
+
+func TestPathCombineHole(t *testing.T) {
+	outer := NewPath()
+	outer.Rect(0, 0, 40, 40)
+	// The hole shares the top edge partially and the left edge is inside.
+	cut := NewPath()
+	cut.Circle(20, 20, 10)
+	cut.Rect(10, 0, 5, 5)
+
+	result := outer.Combine(cut, PathDifference)
+	paths, _ := countSubPaths(result.commands)
+	if paths != 2 {
+		t.Errorf("difference should have an outline and a hole, but %d sub-paths", paths)
+	}
+
+	c := newHitTestContext()
+	c.Translate(100, 0)
+	c.BeginPath()
+	c.Rect(0, 0, 1, 1)
+	c.CombinePath(result, PathUnion)
+	for _, testCase := range []struct {
+		x, y     float32
+		expected bool
+	}{
+		{100.5, 0.5, true},
+		{105, 35, true},
+		{120, 20, false}, // in the circle hole
+		{112, 2, false},  // in the notch
+		{108, 2, true},
+		{145, 20, false},
+	} {
+		if c.IsPointInFill(testCase.x, testCase.y) != testCase.expected {
+			t.Errorf("IsPointInFill(%v, %v) should be %v", testCase.x, testCase.y, testCase.expected)
+		}
+	}
+}
+
+// commandsArea returns the total signed area of the polygons. Holes have negative area.
+func commandsArea(commands []float32) float32 {
+	var area, x0, y0, startX, startY float32
+	for i := 0; i < len(commands); {
+		switch nvgCommands(commands[i]) {
+		case nvgMOVETO:
+			startX, startY = commands[i+1], commands[i+2]
+			x0, y0 = startX, startY
+			i += 3
+		case nvgLINETO:
+			x1, y1 := commands[i+1], commands[i+2]
+			area += (x1*y0 - x0*y1) * 0.5
+			x0, y0 = x1, y1
+			i += 3
+		case nvgCLOSE:
+			area += (startX*y0 - x0*startY) * 0.5
+			i++
+		case nvgWINDING:
+			i += 2
+		default:
+			i++
+		}
+	}
+	return area
+}
+
+func TestPathCombineNearlyCoincident(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		x, y          float32
+		op            PathOp
+		paths         int
+		area, areaTol float32
+	}{
+		{"union with a gap", 10.0005, 0, PathUnion, 1, 200, 0.05},
+		{"union with an overlap", 9.9995, 0, PathUnion, 1, 200, 0.05},
+		{"union with an offset edge", 10, 0.0005, PathUnion, 1, 200, 0.05},
+		{"intersect with a gap", 10.0005, 0, PathIntersect, 0, 0, 0.05},
+		{"difference with a gap", 10.0005, 0, PathDifference, 1, 100, 0.05},
+		{"difference with an overlap", 9.9995, 0, PathDifference, 1, 100, 0.05},
+		{"difference of a moved copy", 0.0005, 0.0005, PathDifference, 0, 0, 0.05},
+		{"xor of a moved copy", 0.0005, 0, PathXor, 0, 0, 0.05},
+	} {
+		a := NewPath()
+		a.Rect(0, 0, 10, 10)
+		b := NewPath()
+		b.Rect(testCase.x, testCase.y, 10, 10)
+		result := a.Combine(b, testCase.op)
+		paths, _ := countSubPaths(result.commands)
+		area := commandsArea(result.commands)
+		if paths != testCase.paths || absF(area-testCase.area) > testCase.areaTol {
+			t.Errorf("%s should make %d sub-paths with area %v, but %d sub-paths with area %v",
+				testCase.name, testCase.paths, testCase.area, paths, area)
+		}
+	}
+}
+
+func TestPathOpGrid(t *testing.T) {
+	var boxes [][4]float64
+	for i := 0; i < 200; i++ {
+		// small boxes on a diagonal, and a few long ones across them
+		x, y := float64(i%50)*3, float64(i%37)*4
+		w, h := float64(i%7)+1, float64(i%5)+1
+		if i%40 == 0 {
+			w = 150
+		}
+		boxes = append(boxes, [4]float64{x, y, x + w, y + h})
+	}
+	visited := make(map[[2]int]int)
+	newPathOpGrid(boxes).pairs(func(i, j int) {
+		visited[[2]int{i, j}]++
+	})
+	for i := range boxes {
+		for j := i + 1; j < len(boxes); j++ {
+			b, u := boxes[i], boxes[j]
+			overlap := b[0] <= u[2] && u[0] <= b[2] && b[1] <= u[3] && u[1] <= b[3]
+			if count := visited[[2]int{i, j}]; (overlap && count != 1) || (!overlap && count != 0) {
+				t.Errorf("pair %v %v should be visited once if they overlap, but %d times", b, u, count)
+			}
+		}
+	}
+}
+
+func TestPathOpIndexWinding(t *testing.T) {
+	// A star with many edges, the same star squashed horizontally and vertically, and a reversed square in it.
+	var star []pathOpPoint
+	for i := 0; i < 1000; i++ {
+		a := float64(i) * 2.0 * math.Pi / 1000.0
+		r := 50.0 + 30.0*math.Sin(a*17.0)
+		star = append(star, pathOpPoint{100.0 + math.Cos(a)*r, 100.0 + math.Sin(a)*r})
+	}
+	square := []pathOpPoint{{90, 90}, {90, 110}, {110, 110}, {110, 90}}
+	for _, scale := range [][2]float64{{1, 1}, {10, 0.1}, {0.1, 10}} {
+		var polygons []pathOpPolygon
+		for _, points := range [][]pathOpPoint{star, star, square} {
+			scaled := make([]pathOpPoint, len(points))
+			for i, p := range points {
+				scaled[i] = pathOpPoint{p.x * scale[0], p.y * scale[1]}
+			}
+			polygons = append(polygons, newPathOpPolygon(scaled))
+		}
+		idx := newPathOpIndex(polygons)
+		for i := 0; i < 2000; i++ {
+			x := float64(i%50)*4.1*scale[0] + 0.5
+			y := float64(i/50)*5.3*scale[1] + 0.5
+			expected := 0
+			for _, polygon := range polygons {
+				points := polygon.points
+				for j := range points {
+					p0, p1 := points[j], points[(j+1)%len(points)]
+					side := (p1.x-p0.x)*(y-p0.y) - (x-p0.x)*(p1.y-p0.y)
+					if p0.y <= y {
+						if p1.y > y && side > 0.0 {
+							expected++
+						}
+					} else if p1.y <= y && side < 0.0 {
+						expected--
+					}
+				}
+			}
+			if w := idx.winding(x, y); w != expected {
+				t.Errorf("winding at (%v, %v) with scale %v should be %d, but %d", x, y, scale, expected, w)
+			}
+		}
+	}
+}
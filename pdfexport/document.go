@@ -0,0 +1,74 @@
+package pdfexport
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// document keeps PDF objects. Object number is index + 1.
+type document struct {
+	objects [][]byte
+}
+
+func (d *document) add(body []byte) int {
+	d.objects = append(d.objects, body)
+	return len(d.objects)
+}
+
+func (d *document) reserve() int {
+	return d.add(nil)
+}
+
+func (d *document) set(id int, body []byte) {
+	d.objects[id-1] = body
+}
+
+func (d *document) clone() *document {
+	objects := make([][]byte, len(d.objects))
+	copy(objects, d.objects)
+	return &document{objects: objects}
+}
+
+// writeTo writes objects, cross-reference table and trailer.
+func (d *document) writeTo(w io.Writer, root int) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(body)
+		buf.WriteString("\nendobj\n")
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, root, xref)
+	return buf.WriteTo(w)
+}
+
+// stream makes stream object body compressed by FlateDecode. dict is additional entries of the stream dictionary.
+func stream(dict string, data []byte) []byte {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write(data)
+	writer.Close()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<< %s /Filter /FlateDecode /Length %d >>\nstream\n", dict, compressed.Len())
+	compressed.WriteTo(&buf)
+	buf.WriteString("\nendstream")
+	return buf.Bytes()
+}
+
+func ref(id int) string {
+	return strconv.Itoa(id) + " 0 R"
+}
+
+func ftoa(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
@@ -0,0 +1,337 @@
+package pdfexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/shibukawa/nanovgo/fontstashmini/truetype"
+)
+
+// font is TrueType font embedded as CIDFontType2 with Identity-H encoding. Character codes are glyph IDs.
+type font struct {
+	id         int // object number of Type0 font
+	resource   string
+	name       string
+	data       []byte
+	info       *truetype.FontInfo
+	unitsPerEm int
+	used       map[int]rune
+}
+
+func newFont(resource, name string, data []byte) (*font, error) {
+	info, err := truetype.InitFont(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := readTables(data)
+	if err != nil {
+		return nil, err
+	}
+	head := tables["head"]
+	if len(head) < 54 {
+		return nil, errors.New("pdfexport: invalid head table")
+	}
+	return &font{
+		resource:   resource,
+		name:       name,
+		data:       data,
+		info:       info,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		used:       map[int]rune{0: 0},
+	}, nil
+}
+
+func (f *font) glyphIndex(r rune) int {
+	gid := f.info.FindGlyphIndex(int(r))
+	if _, ok := f.used[gid]; !ok || f.used[gid] == 0 {
+		f.used[gid] = r
+	}
+	return gid
+}
+
+// advance returns glyph advance width in 1/1000 em like PDF /W array.
+func (f *font) advance(gid int) float32 {
+	advance, _ := f.info.GetGlyphHMetrics(gid)
+	return float32(int(float32(advance)*1000.0/float32(f.unitsPerEm) + 0.5))
+}
+
+func (f *font) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+// baseFontName returns subset font name with tag made from the used glyphs.
+func (f *font) baseFontName() string {
+	gids := f.sortedGlyphs()
+	var key bytes.Buffer
+	for _, gid := range gids {
+		binary.Write(&key, binary.BigEndian, uint16(gid))
+	}
+	sum := crc32.ChecksumIEEE(key.Bytes())
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = byte('A' + sum%26)
+		sum /= 26
+	}
+	var name bytes.Buffer
+	for _, c := range f.name {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' {
+			name.WriteRune(c)
+		}
+	}
+	if name.Len() == 0 {
+		name.WriteString("Font")
+	}
+	return string(tag) + "+" + name.String()
+}
+
+func (f *font) sortedGlyphs() []int {
+	gids := make([]int, 0, len(f.used))
+	for gid := range f.used {
+		gids = append(gids, gid)
+	}
+	sort.Ints(gids)
+	return gids
+}
+
+// writeObjects sets Type0 font object and adds its descendant objects to the document.
+func (f *font) writeObjects(doc *document) error {
+	subset, err := subsetFont(f.data, f.used)
+	if err != nil {
+		return err
+	}
+	baseFont := f.baseFontName()
+	fontFile := doc.add(stream(fmt.Sprintf("/Length1 %d", len(subset)), subset))
+
+	ascent, descent, _ := f.info.GetFontVMetrics()
+	x0, y0, x1, y1 := f.info.GetFontBoundingBox()
+	descriptor := doc.add([]byte(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %s >>",
+		baseFont, f.scale(x0), f.scale(y0), f.scale(x1), f.scale(y1), f.scale(ascent), f.scale(descent), f.scale(ascent), ref(fontFile))))
+
+	var widths bytes.Buffer
+	for _, gid := range f.sortedGlyphs() {
+		fmt.Fprintf(&widths, "%d [%s] ", gid, ftoa(f.advance(gid)))
+	}
+	cidFont := doc.add([]byte(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %s /W [%s] /CIDToGIDMap /Identity >>",
+		baseFont, ref(descriptor), widths.String())))
+	toUnicode := doc.add(stream("", f.toUnicode()))
+
+	doc.set(f.id, []byte(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%s] /ToUnicode %s >>",
+		baseFont, ref(cidFont), ref(toUnicode))))
+	return nil
+}
+
+// toUnicode makes CMap to extract text from glyph IDs.
+func (f *font) toUnicode() []byte {
+	var chars []int
+	for _, gid := range f.sortedGlyphs() {
+		if f.used[gid] != 0 {
+			chars = append(chars, gid)
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	buf.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	buf.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	buf.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for len(chars) > 0 {
+		count := len(chars)
+		if count > 100 {
+			count = 100
+		}
+		fmt.Fprintf(&buf, "%d beginbfchar\n", count)
+		for _, gid := range chars[:count] {
+			fmt.Fprintf(&buf, "<%04X> <", gid)
+			for _, u := range utf16.Encode([]rune{f.used[gid]}) {
+				fmt.Fprintf(&buf, "%04X", u)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+		chars = chars[count:]
+	}
+	buf.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return buf.Bytes()
+}
+
+func readTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errors.New("pdfexport: invalid font data")
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	tables := make(map[string][]byte)
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if record+16 > len(data) {
+			return nil, errors.New("pdfexport: invalid font table directory")
+		}
+		tag := string(data[record : record+4])
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset+length > len(data) {
+			return nil, errors.New("pdfexport: invalid font table " + tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// subsetFont makes TrueType font that keeps only the outlines of the used glyphs. Other glyphs become empty
+// so that glyph IDs are not changed.
+func subsetFont(data []byte, used map[int]rune) ([]byte, error) {
+	tables, err := readTables(data)
+	if err != nil {
+		return nil, err
+	}
+	head, loca, glyf, maxp := tables["head"], tables["loca"], tables["glyf"], tables["maxp"]
+	if head == nil || loca == nil || glyf == nil || maxp == nil {
+		return nil, errors.New("pdfexport: only TrueType outline fonts are supported")
+	}
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, errors.New("pdfexport: invalid head or maxp table")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	longFormat := binary.BigEndian.Uint16(head[50:]) != 0
+	entrySize := 2
+	if longFormat {
+		entrySize = 4
+	}
+	if len(loca) < (numGlyphs+1)*entrySize {
+		return nil, errors.New("pdfexport: invalid loca table")
+	}
+	offsets := make([]int, numGlyphs+1)
+	for i := range offsets {
+		if longFormat {
+			offsets[i] = int(binary.BigEndian.Uint32(loca[i*4:]))
+		} else {
+			offsets[i] = int(binary.BigEndian.Uint16(loca[i*2:])) * 2
+		}
+		if offsets[i] > len(glyf) || (i > 0 && offsets[i] < offsets[i-1]) {
+			return nil, fmt.Errorf("pdfexport: invalid glyph offset %d in loca table", i)
+		}
+	}
+
+	// Add components of composite glyphs.
+	keep := make(map[int]bool)
+	var visit func(gid int)
+	visit = func(gid int) {
+		if gid < 0 || gid >= numGlyphs || keep[gid] {
+			return
+		}
+		keep[gid] = true
+		glyph := glyf[offsets[gid]:offsets[gid+1]]
+		if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+			return
+		}
+		pos := 10
+		for pos+4 <= len(glyph) {
+			flags := binary.BigEndian.Uint16(glyph[pos:])
+			visit(int(binary.BigEndian.Uint16(glyph[pos+2:])))
+			pos += 4
+			if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+				pos += 4
+			} else {
+				pos += 2
+			}
+			if flags&0x0008 != 0 { // WE_HAVE_A_SCALE
+				pos += 2
+			} else if flags&0x0040 != 0 { // WE_HAVE_AN_X_AND_Y_SCALE
+				pos += 4
+			} else if flags&0x0080 != 0 { // WE_HAVE_A_TWO_BY_TWO
+				pos += 8
+			}
+			if flags&0x0020 == 0 { // MORE_COMPONENTS
+				break
+			}
+		}
+	}
+	for gid := range used {
+		visit(gid)
+	}
+
+	var newGlyf, newLoca bytes.Buffer
+	for gid := 0; gid <= numGlyphs; gid++ {
+		if longFormat {
+			binary.Write(&newLoca, binary.BigEndian, uint32(newGlyf.Len()))
+		} else {
+			binary.Write(&newLoca, binary.BigEndian, uint16(newGlyf.Len()/2))
+		}
+		if gid < numGlyphs && keep[gid] {
+			newGlyf.Write(glyf[offsets[gid]:offsets[gid+1]])
+			for newGlyf.Len()%2 != 0 {
+				newGlyf.WriteByte(0)
+			}
+		}
+	}
+
+	newHead := make([]byte, len(head))
+	copy(newHead, head)
+	binary.BigEndian.PutUint32(newHead[8:], 0) // checkSumAdjustment
+
+	output := map[string][]byte{
+		"head": newHead,
+		"loca": newLoca.Bytes(),
+		"glyf": newGlyf.Bytes(),
+		"maxp": maxp,
+	}
+	for _, tag := range []string{"cmap", "hhea", "hmtx", "cvt ", "fpgm", "prep"} {
+		if table, ok := tables[tag]; ok {
+			output[tag] = table
+		}
+	}
+	return buildFont(output), nil
+}
+
+func tableChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// buildFont assembles sfnt font file from the tables.
+func buildFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<uint(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := (1 << uint(entrySelector)) * 16
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []uint16{0x0001, 0x0000, uint16(numTables), uint16(searchRange), uint16(entrySelector), uint16(numTables*16 - searchRange)})
+	offset := 12 + numTables*16
+	headOffset := 0
+	for _, tag := range tags {
+		table := tables[tag]
+		if tag == "head" {
+			headOffset = offset
+		}
+		buf.WriteString(tag)
+		binary.Write(&buf, binary.BigEndian, []uint32{tableChecksum(table), uint32(offset), uint32(len(table))})
+		offset += (len(table) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		table := tables[tag]
+		buf.Write(table)
+		for i := len(table); i%4 != 0; i++ {
+			buf.WriteByte(0)
+		}
+	}
+	result := buf.Bytes()
+	binary.BigEndian.PutUint32(result[headOffset+8:], 0xB1B0AFBA-tableChecksum(result))
+	return result
+}
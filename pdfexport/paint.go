@@ -0,0 +1,377 @@
+package pdfexport
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/shibukawa/nanovgo"
+)
+
+type paintType int
+
+const (
+	solidPaint paintType = iota
+	linearGradientPaint
+	radialGradientPaint
+	boxGradientPaint
+	imagePaint
+)
+
+// paintKind guesses the kind of the paint from the parameters made by nanovgo.LinearGradient(),
+// nanovgo.RadialGradient(), nanovgo.BoxGradient() and nanovgo.ImagePattern().
+func paintKind(paint *nanovgo.Paint) paintType {
+	if paint.Image() != 0 {
+		return imagePaint
+	}
+	if paint.InnerColor() == paint.OuterColor() {
+		return solidPaint
+	}
+	extent := paint.Extent()
+	if extent[0] > 1e4 {
+		// LinearGradient uses a large box
+		return linearGradientPaint
+	}
+	if extent[0] == extent[1] && paint.Radius() == extent[0] {
+		return radialGradientPaint
+	}
+	return boxGradientPaint
+}
+
+// writePaint writes operators that select the paint for fill or stroke. b is the bounding box of the shape.
+func (r *Renderer) writePaint(paint *nanovgo.Paint, fill bool, b bounds) {
+	kind := paintKind(paint)
+	inner := paint.InnerColor()
+	outer := paint.OuterColor()
+	var pattern string
+	switch kind {
+	case solidPaint:
+		r.writeColor(inner, fill)
+		return
+	case imagePaint:
+		tex := r.findTexture(paint.Image())
+		if tex == nil {
+			r.writeColor(inner, fill)
+			return
+		}
+		pattern = r.imagePattern(tex, paint)
+		r.writeAlpha(inner.A)
+	default:
+		shading := r.shading(paint, kind, false, b)
+		pattern = r.shadingPattern(shading, paint.Xform().Multiply(r.pageMatrix()))
+		if inner.A == outer.A {
+			r.writeAlpha(inner.A)
+		} else {
+			r.writeSoftMask(paint, kind, b)
+		}
+	}
+	if fill {
+		fmt.Fprintf(&r.content, "/Pattern cs /%s scn\n", pattern)
+	} else {
+		fmt.Fprintf(&r.content, "/Pattern CS /%s SCN\n", pattern)
+	}
+}
+
+// pageMatrix returns the matrix from the window coordinates to the default coordinates of the page.
+func (r *Renderer) pageMatrix() nanovgo.TransformMatrix {
+	return nanovgo.TransformMatrix{1, 0, 0, -1, 0, float32(r.height)}
+}
+
+func (r *Renderer) writeColor(c nanovgo.Color, fill bool) {
+	r.writeAlpha(c.A)
+	op := "rg"
+	if !fill {
+		op = "RG"
+	}
+	fmt.Fprintf(&r.content, "%s %s %s %s\n", ftoa(clampF(c.R, 0, 1)), ftoa(clampF(c.G, 0, 1)), ftoa(clampF(c.B, 0, 1)), op)
+}
+
+// writeAlpha sets constant alpha of both fill and stroke via ExtGState.
+func (r *Renderer) writeAlpha(alpha float32) {
+	if alpha >= 1.0 {
+		return
+	}
+	alpha = clampF(alpha, 0, 1)
+	name, ok := r.alphaStates[alpha]
+	if !ok {
+		name = r.newName("GS")
+		r.alphaStates[alpha] = name
+		r.extGStates[name] = r.doc.add([]byte(fmt.Sprintf("<< /Type /ExtGState /ca %s /CA %s >>", ftoa(alpha), ftoa(alpha))))
+	}
+	fmt.Fprintf(&r.content, "/%s gs\n", name)
+}
+
+// writeSoftMask sets the alpha of the gradient as luminosity soft mask. The mask form is painted in the window
+// coordinates, so the pattern in it doesn't need the page matrix.
+func (r *Renderer) writeSoftMask(paint *nanovgo.Paint, kind paintType, b bounds) {
+	shading := r.shading(paint, kind, true, b)
+	pattern := r.doc.add([]byte(fmt.Sprintf("<< /PatternType 2 /Shading %s /Matrix [%s] >>", ref(shading), matrix(paint.Xform()))))
+	content := fmt.Sprintf("/Pattern cs /P scn 0 0 %d %d re f", r.width, r.height)
+	form := r.doc.add(stream(fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %d %d] /Group << /S /Transparency /CS /DeviceGray >> /Resources << /Pattern << /P %s >> >>",
+		r.width, r.height, ref(pattern)), []byte(content)))
+	name := r.newName("GS")
+	r.extGStates[name] = r.doc.add([]byte(fmt.Sprintf("<< /Type /ExtGState /SMask << /Type /Mask /S /Luminosity /G %s >> >>", ref(form))))
+	fmt.Fprintf(&r.content, "/%s gs\n", name)
+}
+
+func (r *Renderer) shadingPattern(shading int, xform nanovgo.TransformMatrix) string {
+	name := r.newName("P")
+	r.patterns[name] = r.doc.add([]byte(fmt.Sprintf("<< /PatternType 2 /Shading %s /Matrix [%s] >>", ref(shading), matrix(xform))))
+	return name
+}
+
+// shading adds shading object of the gradient in the paint space. If alpha is true, the shading has the alpha
+// of the gradient in DeviceGray color space instead of the color.
+func (r *Renderer) shading(paint *nanovgo.Paint, kind paintType, alpha bool, b bounds) int {
+	colorSpace := "/DeviceRGB"
+	if alpha {
+		colorSpace = "/DeviceGray"
+	}
+	extent := paint.Extent()
+	feather := paint.Feather()
+	switch kind {
+	case linearGradientPaint:
+		// Gradient runs along y axis of the paint space from extent-feather/2 to extent+feather/2.
+		return r.doc.add([]byte(fmt.Sprintf("<< /ShadingType 2 /ColorSpace %s /Coords [0 %s 0 %s] /Function %s /Extend [true true] >>",
+			colorSpace, ftoa(extent[1]-feather*0.5), ftoa(extent[1]+feather*0.5), gradientFunction(paint, alpha))))
+	case radialGradientPaint:
+		return r.doc.add([]byte(fmt.Sprintf("<< /ShadingType 3 /ColorSpace %s /Coords [0 0 %s 0 0 %s] /Function %s /Extend [true true] >>",
+			colorSpace, ftoa(maxF(extent[0]-feather*0.5, 0)), ftoa(extent[0]+feather*0.5), gradientFunction(paint, alpha))))
+	}
+	return r.boxShading(paint, colorSpace, alpha, b)
+}
+
+// gradientFunction returns exponential interpolation function between the inner color and the outer color.
+func gradientFunction(paint *nanovgo.Paint, alpha bool) string {
+	inner := paint.InnerColor()
+	outer := paint.OuterColor()
+	if alpha {
+		return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", ftoa(clampF(inner.A, 0, 1)), ftoa(clampF(outer.A, 0, 1)))
+	}
+	// GL backend interpolates premultiplied colors. When one side is transparent, the color doesn't change.
+	if inner.A == 0 {
+		inner = outer
+	} else if outer.A == 0 {
+		outer = inner
+	}
+	return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", rgb(inner), rgb(outer))
+}
+
+func rgb(c nanovgo.Color) string {
+	return fmt.Sprintf("%s %s %s", ftoa(clampF(c.R, 0, 1)), ftoa(clampF(c.G, 0, 1)), ftoa(clampF(c.B, 0, 1)))
+}
+
+// boxShading adds function-based shading which samples the box gradient in the paint space area
+// that covers the shape.
+func (r *Renderer) boxShading(paint *nanovgo.Paint, colorSpace string, alpha bool, b bounds) int {
+	inv := paint.Xform().Inverse()
+	domain := newBounds()
+	for _, p := range [4][2]float32{{b[0], b[1]}, {b[2], b[1]}, {b[2], b[3]}, {b[0], b[3]}} {
+		domain.add(inv.TransformPoint(p[0], p[1]))
+	}
+	nx := clampI(int(domain[2]-domain[0])+1, 2, 256)
+	ny := clampI(int(domain[3]-domain[1])+1, 2, 256)
+
+	extent := paint.Extent()
+	radius := paint.Radius()
+	feather := paint.Feather()
+	inner := paint.InnerColor()
+	outer := paint.OuterColor()
+	components := 3
+	if alpha {
+		components = 1
+	}
+	samples := make([]byte, 0, nx*ny*components)
+	for j := 0; j < ny; j++ {
+		y := domain[1] + (domain[3]-domain[1])*float32(j)/float32(ny-1)
+		for i := 0; i < nx; i++ {
+			x := domain[0] + (domain[2]-domain[0])*float32(i)/float32(nx-1)
+			t := clampF((sdroundrect(x, y, extent[0], extent[1], radius)+feather*0.5)/feather, 0, 1)
+			a := inner.A*(1-t) + outer.A*t
+			if alpha {
+				samples = append(samples, toByte(a))
+				continue
+			}
+			// Interpolate premultiplied colors like GL backend and divide by alpha.
+			cr := inner.R*inner.A*(1-t) + outer.R*outer.A*t
+			cg := inner.G*inner.A*(1-t) + outer.G*outer.A*t
+			cb := inner.B*inner.A*(1-t) + outer.B*outer.A*t
+			if a > 0 {
+				cr, cg, cb = cr/a, cg/a, cb/a
+			}
+			samples = append(samples, toByte(cr), toByte(cg), toByte(cb))
+		}
+	}
+	dom := fmt.Sprintf("%s %s %s %s", ftoa(domain[0]), ftoa(domain[2]), ftoa(domain[1]), ftoa(domain[3]))
+	rangeArray := "0 1 0 1 0 1"
+	if alpha {
+		rangeArray = "0 1"
+	}
+	function := r.doc.add(stream(fmt.Sprintf("/FunctionType 0 /Domain [%s] /Range [%s] /Size [%d %d] /BitsPerSample 8", dom, rangeArray, nx, ny), samples))
+	return r.doc.add([]byte(fmt.Sprintf("<< /ShadingType 1 /ColorSpace %s /Domain [%s] /Function %s >>", colorSpace, dom, ref(function))))
+}
+
+func sdroundrect(ptX, ptY, extX, extY, rad float32) float32 {
+	dx := absF(ptX) - (extX - rad)
+	dy := absF(ptY) - (extY - rad)
+	ox := maxF(dx, 0.0)
+	oy := maxF(dy, 0.0)
+	return minF(maxF(dx, dy), 0.0) + float32(math.Sqrt(float64(ox*ox+oy*oy))) - rad
+}
+
+// imagePattern adds tiling pattern that repeats the image of the texture.
+func (r *Renderer) imagePattern(tex *texture, paint *nanovgo.Paint) string {
+	if tex.object == 0 {
+		tex.object = tex.writeImage(&r.doc)
+	}
+	extent := paint.Extent()
+	xform := paint.Xform()
+	if tex.flags&nanovgo.ImageFlippy != 0 {
+		xform = nanovgo.ScaleMatrix(1.0, -1.0).Multiply(xform)
+	}
+	// The image space is the unit square and its first row is at the top.
+	content := fmt.Sprintf("q %s 0 0 %s 0 %s cm /Im Do Q", ftoa(extent[0]), ftoa(-extent[1]), ftoa(extent[1]))
+	name := r.newName("P")
+	r.patterns[name] = r.doc.add(stream(fmt.Sprintf("/Type /Pattern /PatternType 1 /PaintType 1 /TilingType 1 /BBox [0 0 %s %s] /XStep %s /YStep %s /Matrix [%s] /Resources << /XObject << /Im %s >> >>",
+		ftoa(extent[0]), ftoa(extent[1]), ftoa(extent[0]), ftoa(extent[1]), matrix(xform.Multiply(r.pageMatrix())), ref(tex.object)), []byte(content)))
+	return name
+}
+
+type texture struct {
+	id            int
+	width, height int
+	texType       nanovgo.TextureType
+	flags         nanovgo.ImageFlags
+	data          []byte
+	object        int // object number of the image XObject
+}
+
+func (r *Renderer) findTexture(id int) *texture {
+	for _, tex := range r.textures {
+		if tex.id == id {
+			return tex
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) allocTexture() *texture {
+	var tex *texture
+	for _, t := range r.textures {
+		if t.id == 0 {
+			tex = t
+			break
+		}
+	}
+	if tex == nil {
+		tex = &texture{}
+		r.textures = append(r.textures, tex)
+	}
+	r.textureID++
+	tex.id = r.textureID
+	tex.object = 0
+	return tex
+}
+
+// update copies whole rows of the image like GL backend.
+func (t *texture) update(y, h int, data []byte) {
+	stride := t.width
+	if t.texType == nanovgo.TextureRGBA {
+		stride *= 4
+	}
+	start := y * stride
+	end := (y + h) * stride
+	if end > len(data) {
+		end = len(data)
+	}
+	if start < end {
+		copy(t.data[start:end], data[start:end])
+	}
+	t.object = 0
+}
+
+// writeImage adds image XObject of the texture. Alpha channel is stored as the soft mask image.
+func (t *texture) writeImage(doc *document) int {
+	count := t.width * t.height
+	colors := make([]byte, count*3)
+	alphas := make([]byte, count)
+	opaque := true
+	for i := 0; i < count; i++ {
+		if t.texType != nanovgo.TextureRGBA {
+			colors[i*3], colors[i*3+1], colors[i*3+2] = 255, 255, 255
+			alphas[i] = t.data[i]
+		} else {
+			a := t.data[i*4+3]
+			for c := 0; c < 3; c++ {
+				v := t.data[i*4+c]
+				if t.flags&nanovgo.ImagePreMultiplied != 0 && a > 0 && a < 255 {
+					v = uint8(minI(int(v)*255/int(a), 255))
+				}
+				colors[i*3+c] = v
+			}
+			alphas[i] = a
+		}
+		if alphas[i] != 255 {
+			opaque = false
+		}
+	}
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", t.width, t.height)
+	smask := ""
+	if !opaque {
+		smask = " /SMask " + ref(doc.add(stream(dict+" /ColorSpace /DeviceGray", alphas)))
+	}
+	return doc.add(stream(dict+" /ColorSpace /DeviceRGB /Interpolate true"+smask, colors))
+}
+
+func sortedNames(entries map[string]int) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func toByte(v float32) uint8 {
+	if v <= 0.0 {
+		return 0
+	}
+	if v >= 1.0 {
+		return 255
+	}
+	return uint8(v*255.0 + 0.5)
+}
+
+func clampF(a, min, max float32) float32 {
+	if a < min {
+		return min
+	}
+	if a > max {
+		return max
+	}
+	return a
+}
+
+func clampI(a, min, max int) int {
+	if a < min {
+		return min
+	}
+	if a > max {
+		return max
+	}
+	return a
+}
+
+func minI(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func absF(a float32) float32 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
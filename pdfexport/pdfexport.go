@@ -0,0 +1,495 @@
+// Package pdfexport provides NanoVGo backend that writes PDF documents.
+//
+// Each frame between Context.BeginFrame() and Context.EndFrame() becomes a page. Paths are written as PDF path
+// operators without tessellation, linear and radial gradients become shading patterns, box gradients become
+// sampled function shadings and image patterns become tiling patterns. Text is written as PDF text with
+// the subset of the TrueType font embedded, so the text in the document can be selected and searched.
+package pdfexport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/shibukawa/nanovgo"
+)
+
+// NewContext makes new NanoVGo context that writes PDF document. Each frame becomes a page and the document
+// is available via Renderer.WriteTo().
+func NewContext(flags nanovgo.CreateFlags) (*nanovgo.Context, *Renderer, error) {
+	r := NewRenderer()
+	ctx, err := nanovgo.NewContextWithRenderer(r, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, r, nil
+}
+
+type page struct {
+	width, height int
+	content       int
+}
+
+// Renderer is nanovgo.VectorRenderer which builds PDF document.
+type Renderer struct {
+	width, height int
+	textures      []*texture
+	textureID     int
+	lastName      int
+	doc           document
+	pages         []page
+	content       bytes.Buffer
+	extGStates    map[string]int
+	patterns      map[string]int
+	alphaStates   map[float32]string
+	fonts         map[string]*font
+	fontErr       error // The first error of the fonts that can't be embedded.
+}
+
+// NewRenderer creates Renderer. Pass it to nanovgo.NewContextWithRenderer().
+func NewRenderer() *Renderer {
+	r := &Renderer{}
+	r.reset()
+	return r
+}
+
+// PageCount returns the number of the finished pages.
+func (r *Renderer) PageCount() int {
+	return len(r.pages)
+}
+
+// WriteTo writes the PDF document that contains the finished pages. It fails if text was drawn with the font
+// that can't be embedded.
+func (r *Renderer) WriteTo(w io.Writer) (int64, error) {
+	if len(r.pages) == 0 {
+		return 0, errors.New("pdfexport: document has no pages")
+	}
+	if r.fontErr != nil {
+		return 0, r.fontErr
+	}
+	doc := r.doc.clone()
+	fonts := make(map[string]int)
+	for _, f := range r.fonts {
+		if f == nil {
+			continue
+		}
+		if err := f.writeObjects(doc); err != nil {
+			return 0, err
+		}
+		fonts[f.resource] = f.id
+	}
+	var resources bytes.Buffer
+	resources.WriteString("<< /ProcSet [/PDF /Text /ImageB /ImageC]")
+	writeResourceDict(&resources, "ExtGState", r.extGStates)
+	writeResourceDict(&resources, "Pattern", r.patterns)
+	writeResourceDict(&resources, "Font", fonts)
+	resources.WriteString(" >>")
+	resourcesID := doc.add(resources.Bytes())
+
+	pagesID := doc.reserve()
+	var kids bytes.Buffer
+	for _, p := range r.pages {
+		id := doc.add([]byte(fmt.Sprintf("<< /Type /Page /Parent %s /MediaBox [0 0 %d %d] /Resources %s /Contents %s >>",
+			ref(pagesID), p.width, p.height, ref(resourcesID), ref(p.content))))
+		fmt.Fprintf(&kids, "%s ", ref(id))
+	}
+	doc.set(pagesID, []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(r.pages))))
+	catalogID := doc.add([]byte(fmt.Sprintf("<< /Type /Catalog /Pages %s >>", ref(pagesID))))
+	return doc.writeTo(w, catalogID)
+}
+
+func writeResourceDict(buf *bytes.Buffer, kind string, entries map[string]int) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, " /%s <<", kind)
+	for _, name := range sortedNames(entries) {
+		fmt.Fprintf(buf, " /%s %s", name, ref(entries[name]))
+	}
+	buf.WriteString(" >>")
+}
+
+func (r *Renderer) newName(prefix string) string {
+	r.lastName++
+	return prefix + strconv.Itoa(r.lastName)
+}
+
+func (r *Renderer) reset() {
+	r.lastName = 0
+	r.doc = document{}
+	r.pages = nil
+	r.content.Reset()
+	r.extGStates = make(map[string]int)
+	r.patterns = make(map[string]int)
+	r.alphaStates = make(map[float32]string)
+	r.fonts = make(map[string]*font)
+	r.fontErr = nil
+	for _, tex := range r.textures {
+		tex.object = 0
+	}
+}
+
+// EdgeAntiAlias returns false. PDF viewers anti-alias paths by themselves.
+func (r *Renderer) EdgeAntiAlias() bool {
+	return false
+}
+
+// RenderCreate initializes the renderer.
+func (r *Renderer) RenderCreate() error {
+	r.reset()
+	return nil
+}
+
+// RenderCreateTexture keeps the image data to embed it into the document.
+func (r *Renderer) RenderCreateTexture(texType nanovgo.TextureType, w, h int, flags nanovgo.ImageFlags, data []byte) int {
+	tex := r.allocTexture()
+	tex.width = w
+	tex.height = h
+	tex.texType = texType
+	tex.flags = flags
+	bpp := 1
+	if texType == nanovgo.TextureRGBA {
+		bpp = 4
+	}
+	tex.data = make([]byte, w*h*bpp)
+	copy(tex.data, data)
+	return tex.id
+}
+
+// RenderDeleteTexture deletes the image data.
+func (r *Renderer) RenderDeleteTexture(image int) error {
+	tex := r.findTexture(image)
+	if tex == nil || tex.flags&nanovgo.ImageNoDelete != 0 {
+		return errors.New("invalid texture in pdfexport.RenderDeleteTexture")
+	}
+	tex.id = 0
+	tex.data = nil
+	tex.object = 0
+	return nil
+}
+
+// RenderUpdateTexture updates rows of the image data.
+func (r *Renderer) RenderUpdateTexture(image, x, y, w, h int, data []byte) error {
+	tex := r.findTexture(image)
+	if tex == nil {
+		return errors.New("invalid texture in pdfexport.RenderUpdateTexture")
+	}
+	tex.update(y, h, data)
+	return nil
+}
+
+// RenderGetTextureSize returns the size of the image.
+func (r *Renderer) RenderGetTextureSize(image int) (int, int, error) {
+	tex := r.findTexture(image)
+	if tex == nil {
+		return -1, -1, errors.New("invalid texture in pdfexport.RenderGetTextureSize")
+	}
+	return tex.width, tex.height, nil
+}
+
+// RenderViewport starts new page.
+func (r *Renderer) RenderViewport(width, height int) {
+	r.width = width
+	r.height = height
+	r.content.Reset()
+	// PDF's origin is the bottom-left corner. Flip y axis to use the window coordinates.
+	fmt.Fprintf(&r.content, "1 0 0 -1 0 %d cm\n", height)
+}
+
+// RenderCancel discards the current page.
+func (r *Renderer) RenderCancel() {
+	r.content.Reset()
+}
+
+// RenderFlush finishes the current page.
+func (r *Renderer) RenderFlush() {
+	if r.content.Len() == 0 {
+		return
+	}
+	id := r.doc.add(stream("", r.content.Bytes()))
+	r.pages = append(r.pages, page{width: r.width, height: r.height, content: id})
+	r.content.Reset()
+}
+
+// RenderFill is not used. Context calls RenderFillPath() instead.
+func (r *Renderer) RenderFill(paint *nanovgo.Paint, scissor *nanovgo.Scissor, fringe float32, bounds [4]float32, paths []nanovgo.RenderPath) {
+}
+
+// RenderStroke is not used. Context calls RenderStrokePath() instead.
+func (r *Renderer) RenderStroke(paint *nanovgo.Paint, scissor *nanovgo.Scissor, fringe float32, strokeWidth float32, paths []nanovgo.RenderPath) {
+}
+
+// RenderTriangles writes triangles as a path.
+func (r *Renderer) RenderTriangles(paint *nanovgo.Paint, scissor *nanovgo.Scissor, vertexes []nanovgo.Vertex) {
+	var path bytes.Buffer
+	b := newBounds()
+	for i := 0; i+2 < len(vertexes); i += 3 {
+		writeTriangle(&path, &b, &vertexes[i], &vertexes[i+1], &vertexes[i+2])
+	}
+	r.fillShape(paint, scissor, path.Bytes(), b, nanovgo.NonZero)
+}
+
+// RenderTriangleStrip writes triangle strip as a path.
+func (r *Renderer) RenderTriangleStrip(paint *nanovgo.Paint, scissor *nanovgo.Scissor, vertexes []nanovgo.Vertex) {
+	var path bytes.Buffer
+	b := newBounds()
+	for i := 0; i+2 < len(vertexes); i++ {
+		if i%2 == 0 {
+			writeTriangle(&path, &b, &vertexes[i], &vertexes[i+1], &vertexes[i+2])
+		} else {
+			writeTriangle(&path, &b, &vertexes[i+1], &vertexes[i], &vertexes[i+2])
+		}
+	}
+	r.fillShape(paint, scissor, path.Bytes(), b, nanovgo.NonZero)
+}
+
+// RenderDelete releases all images. The finished pages are kept to write them after deleting the context.
+func (r *Renderer) RenderDelete() {
+	r.textures = nil
+}
+
+// RenderFillPath writes the path with fill operator.
+func (r *Renderer) RenderFillPath(paint *nanovgo.Paint, scissor *nanovgo.Scissor, commands []nanovgo.PathCommand, fillRule nanovgo.FillRule) {
+	path, b := pathData(commands)
+	r.fillShape(paint, scissor, path, b, fillRule)
+}
+
+// RenderStrokePath writes the path with stroke operator.
+func (r *Renderer) RenderStrokePath(paint *nanovgo.Paint, scissor *nanovgo.Scissor, commands []nanovgo.PathCommand, style *nanovgo.StrokeStyle) {
+	path, b := pathData(commands)
+	if len(path) == 0 {
+		return
+	}
+	margin := style.Width * 0.5
+	if style.LineJoin == nanovgo.Miter {
+		margin *= maxF(style.MiterLimit, 1.0)
+	} else if style.LineCap == nanovgo.Square {
+		margin *= 1.5
+	}
+	b.expand(margin)
+
+	r.content.WriteString("q\n")
+	r.writeClip(scissor)
+	r.writePaint(paint, false, b)
+	fmt.Fprintf(&r.content, "%s w %d J %d j %s M\n", ftoa(style.Width), lineCapStyle(style.LineCap), lineJoinStyle(style.LineJoin), ftoa(maxF(style.MiterLimit, 1.0)))
+	if len(style.Dash) > 0 {
+		r.content.WriteString("[")
+		for i, length := range style.Dash {
+			if i > 0 {
+				r.content.WriteString(" ")
+			}
+			r.content.WriteString(ftoa(length))
+		}
+		fmt.Fprintf(&r.content, "] %s d\n", ftoa(style.DashOffset))
+	}
+	r.content.Write(path)
+	r.content.WriteString("S\nQ\n")
+}
+
+// RenderText writes the text with the embedded font.
+func (r *Renderer) RenderText(paint *nanovgo.Paint, scissor *nanovgo.Scissor, text *nanovgo.TextRun) {
+	if len(text.FontData) == 0 || len(text.Runes) == 0 || text.FontSize <= 0 {
+		return
+	}
+	f, ok := r.fonts[text.FontName]
+	if !ok {
+		var err error
+		f, err = newFont(r.newName("F"), text.FontName, text.FontData)
+		if err == nil {
+			f.id = r.doc.reserve()
+		} else if r.fontErr == nil {
+			// The text can't be written without the font. WriteTo() reports it.
+			r.fontErr = fmt.Errorf("pdfexport: can't embed font %s: %v", text.FontName, err)
+		}
+		// Remember the font that can't be embedded too, not to parse it again.
+		r.fonts[text.FontName] = f
+	}
+	if f == nil {
+		return
+	}
+
+	// Text space of PDF is y-up. Flip it to the text space of NanoVGo and move the origin to the first glyph.
+	tm := nanovgo.ScaleMatrix(1.0, -1.0).Multiply(nanovgo.TranslateMatrix(text.X[0], text.Y)).Multiply(text.Xform)
+
+	r.content.WriteString("q\n")
+	r.writeClip(scissor)
+	if paintKind(paint) == boxGradientPaint {
+		// Box gradient can't be applied to text, use the inner color instead.
+		r.writeColor(paint.InnerColor(), true)
+	} else {
+		r.writePaint(paint, true, textBounds(text))
+	}
+	fmt.Fprintf(&r.content, "BT\n/%s %s Tf\n%s Tm\n[", f.resource, ftoa(text.FontSize), matrix(tm))
+	for i, c := range text.Runes {
+		gid := f.glyphIndex(c)
+		fmt.Fprintf(&r.content, "<%04X>", gid)
+		if i+1 < len(text.Runes) {
+			adjust := f.advance(gid) - (text.X[i+1]-text.X[i])*1000.0/text.FontSize
+			if adjust > 0.01 || adjust < -0.01 {
+				fmt.Fprintf(&r.content, " %s ", ftoa(adjust))
+			}
+		}
+	}
+	r.content.WriteString("] TJ\nET\nQ\n")
+}
+
+func (r *Renderer) fillShape(paint *nanovgo.Paint, scissor *nanovgo.Scissor, path []byte, b bounds, fillRule nanovgo.FillRule) {
+	if len(path) == 0 {
+		return
+	}
+	r.content.WriteString("q\n")
+	r.writeClip(scissor)
+	r.writePaint(paint, true, b)
+	r.content.Write(path)
+	if fillRule == nanovgo.EvenOdd {
+		r.content.WriteString("f*\nQ\n")
+	} else {
+		r.content.WriteString("f\nQ\n")
+	}
+}
+
+// writeClip writes clipping paths of the scissor and the clip paths.
+func (r *Renderer) writeClip(scissor *nanovgo.Scissor) {
+	for clip := scissor.Clip(); clip != nil; clip = clip.Parent() {
+		path, _ := pathData(clip.Commands())
+		if len(path) == 0 {
+			// Empty clip path hides everything.
+			r.content.WriteString("0 0 0 0 re W n\n")
+			continue
+		}
+		r.content.Write(path)
+		if clip.FillRule() == nanovgo.EvenOdd {
+			r.content.WriteString("W* n\n")
+		} else {
+			r.content.WriteString("W n\n")
+		}
+	}
+	extent := scissor.Extent()
+	if extent[0] < -0.5 || extent[1] < -0.5 {
+		return
+	}
+	xform := scissor.Xform()
+	corners := [4][2]float32{
+		{-extent[0], -extent[1]},
+		{extent[0], -extent[1]},
+		{extent[0], extent[1]},
+		{-extent[0], extent[1]},
+	}
+	for i, corner := range corners {
+		x, y := xform.TransformPoint(corner[0], corner[1])
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&r.content, "%s %s %s\n", ftoa(x), ftoa(y), op)
+	}
+	r.content.WriteString("h W n\n")
+}
+
+// bounds is bounding box of the shape in the window coordinates.
+type bounds [4]float32
+
+func newBounds() bounds {
+	return bounds{1e6, 1e6, -1e6, -1e6}
+}
+
+func (b *bounds) add(x, y float32) {
+	b[0] = minF(b[0], x)
+	b[1] = minF(b[1], y)
+	b[2] = maxF(b[2], x)
+	b[3] = maxF(b[3], y)
+}
+
+func (b *bounds) expand(margin float32) {
+	b[0] -= margin
+	b[1] -= margin
+	b[2] += margin
+	b[3] += margin
+}
+
+func textBounds(text *nanovgo.TextRun) bounds {
+	b := newBounds()
+	x0 := text.X[0] - text.FontSize
+	x1 := text.X[len(text.X)-1] + text.FontSize*2
+	y0 := text.Y - text.FontSize*2
+	y1 := text.Y + text.FontSize
+	for _, p := range [4][2]float32{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+		b.add(text.Xform.TransformPoint(p[0], p[1]))
+	}
+	return b
+}
+
+func writeTriangle(path *bytes.Buffer, b *bounds, v0, v1, v2 *nanovgo.Vertex) {
+	x0, y0 := v0.Pos()
+	x1, y1 := v1.Pos()
+	x2, y2 := v2.Pos()
+	b.add(x0, y0)
+	b.add(x1, y1)
+	b.add(x2, y2)
+	fmt.Fprintf(path, "%s %s m %s %s l %s %s l h\n", ftoa(x0), ftoa(y0), ftoa(x1), ftoa(y1), ftoa(x2), ftoa(y2))
+}
+
+// pathData converts path commands into PDF path operators and returns its bounding box.
+func pathData(commands []nanovgo.PathCommand) ([]byte, bounds) {
+	var path bytes.Buffer
+	b := newBounds()
+	for i := range commands {
+		p := &commands[i].Points
+		switch commands[i].Type {
+		case nanovgo.PathMoveTo:
+			fmt.Fprintf(&path, "%s %s m\n", ftoa(p[0]), ftoa(p[1]))
+			b.add(p[0], p[1])
+		case nanovgo.PathLineTo:
+			fmt.Fprintf(&path, "%s %s l\n", ftoa(p[0]), ftoa(p[1]))
+			b.add(p[0], p[1])
+		case nanovgo.PathBezierTo:
+			fmt.Fprintf(&path, "%s %s %s %s %s %s c\n", ftoa(p[0]), ftoa(p[1]), ftoa(p[2]), ftoa(p[3]), ftoa(p[4]), ftoa(p[5]))
+			b.add(p[0], p[1])
+			b.add(p[2], p[3])
+			b.add(p[4], p[5])
+		case nanovgo.PathClose:
+			path.WriteString("h\n")
+		}
+	}
+	return path.Bytes(), b
+}
+
+func lineCapStyle(lineCap nanovgo.LineCap) int {
+	switch lineCap {
+	case nanovgo.Round:
+		return 1
+	case nanovgo.Square:
+		return 2
+	}
+	return 0
+}
+
+func lineJoinStyle(lineJoin nanovgo.LineCap) int {
+	switch lineJoin {
+	case nanovgo.Round:
+		return 1
+	case nanovgo.Bevel:
+		return 2
+	}
+	return 0
+}
+
+func matrix(t nanovgo.TransformMatrix) string {
+	return fmt.Sprintf("%s %s %s %s %s %s", ftoa(t[0]), ftoa(t[1]), ftoa(t[2]), ftoa(t[3]), ftoa(t[4]), ftoa(t[5]))
+}
+
+func maxF(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minF(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,185 @@
+package pdfexport
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/shibukawa/nanovgo"
+	"github.com/shibukawa/nanovgo/fontstashmini/truetype"
+)
+
+func TestExportPages(t *testing.T) {
+	ctx, r, err := NewContext(0)
+	if err != nil {
+		t.Fatalf("NewContext() should succeed, but %v", err)
+	}
+	defer ctx.Delete()
+
+	ctx.BeginFrame(200, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 80, 40)
+	ctx.SetFillPaint(nanovgo.LinearGradient(10, 10, 10, 50, nanovgo.RGBA(255, 0, 0, 255), nanovgo.RGBA(0, 0, 255, 0)))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	ctx.BeginFrame(300, 150, 1.0)
+	ctx.Scissor(100, 0, 50, 100)
+	ctx.BeginPath()
+	ctx.Circle(120, 50, 30)
+	ctx.SetStrokeColor(nanovgo.RGBA(0, 128, 0, 128))
+	ctx.SetStrokeWidth(4)
+	ctx.Stroke()
+	ctx.ResetScissor()
+	ctx.BeginPath()
+	ctx.RoundedRect(10, 10, 60, 40, 5)
+	ctx.SetFillPaint(nanovgo.BoxGradient(10, 10, 60, 40, 5, 10, nanovgo.RGBA(0, 0, 0, 255), nanovgo.RGBA(255, 255, 255, 255)))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	if r.PageCount() != 2 {
+		t.Fatalf("document should have 2 pages, but %d", r.PageCount())
+	}
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() should succeed, but %v", err)
+	}
+	pdf := buf.String()
+	for _, expected := range []string{
+		"%PDF-1.4",
+		"/Type /Pages",
+		"/Count 2",
+		"/MediaBox [0 0 200 100]",
+		"/MediaBox [0 0 300 150]",
+		"/ShadingType 2",
+		"/S /Luminosity",
+		"/ShadingType 1",
+		"/ca 0.5019608",
+		"%%EOF",
+	} {
+		if !strings.Contains(pdf, expected) {
+			t.Errorf("PDF document should contain %s", expected)
+		}
+	}
+}
+
+func TestExportCancel(t *testing.T) {
+	ctx, r, err := NewContext(0)
+	if err != nil {
+		t.Fatalf("NewContext() should succeed, but %v", err)
+	}
+	defer ctx.Delete()
+
+	ctx.BeginFrame(100, 100, 1.0)
+	ctx.CancelFrame()
+	if r.PageCount() != 0 {
+		t.Errorf("canceled frame should not be a page, but %d pages", r.PageCount())
+	}
+	if _, err := r.WriteTo(&bytes.Buffer{}); err == nil {
+		t.Error("WriteTo() should fail without pages")
+	}
+}
+
+func TestExportText(t *testing.T) {
+	data, err := ioutil.ReadFile("../sample/Roboto-Regular.ttf")
+	if err != nil {
+		t.Skip("sample font is not available")
+	}
+	ctx, r, err := NewContext(0)
+	if err != nil {
+		t.Fatalf("NewContext() should succeed, but %v", err)
+	}
+	defer ctx.Delete()
+	ctx.CreateFontFromMemory("sans", data, 0)
+
+	ctx.BeginFrame(200, 100, 1.0)
+	ctx.SetFontFace("sans")
+	ctx.SetFontSize(20)
+	ctx.SetFillColor(nanovgo.RGBA(0, 0, 0, 255))
+	ctx.Text(10, 50, "Hello")
+	ctx.EndFrame()
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() should succeed, but %v", err)
+	}
+	pdf := buf.String()
+	for _, expected := range []string{
+		"/Subtype /Type0",
+		"/CIDToGIDMap /Identity",
+		"/FontFile2",
+		"/ToUnicode",
+	} {
+		if !strings.Contains(pdf, expected) {
+			t.Errorf("PDF document should contain %s", expected)
+		}
+	}
+
+	f := r.fonts["sans"]
+	used := f.glyphIndex('H')
+	unused := f.glyphIndex('Z')
+	delete(f.used, unused)
+	subset, err := subsetFont(data, f.used)
+	if err != nil {
+		t.Fatalf("subsetFont() should succeed, but %v", err)
+	}
+	if len(subset) >= len(data) {
+		t.Errorf("subset font should be smaller than the original, but %d >= %d", len(subset), len(data))
+	}
+	info, err := truetype.InitFont(subset, 0)
+	if err != nil {
+		t.Fatalf("subset font should be valid, but %v", err)
+	}
+	if info.FindGlyphIndex('H') != used {
+		t.Errorf("subset font should keep glyph IDs")
+	}
+	if len(info.GetGlyphShape(used)) == 0 {
+		t.Errorf("subset font should keep the outline of the used glyph")
+	}
+	if len(info.GetGlyphShape(unused)) != 0 {
+		t.Errorf("subset font should remove the outline of the unused glyph")
+	}
+}
+
+func TestSubsetFontInvalid(t *testing.T) {
+	head := make([]byte, 54)
+	maxp := []byte{0, 1, 0, 0, 0, 2} // 2 glyphs
+	for _, testCase := range []struct {
+		name   string
+		tables map[string][]byte
+	}{
+		{"short maxp", map[string][]byte{"head": head, "maxp": maxp[:4], "loca": {0, 0, 0, 1, 0, 2}, "glyf": {0, 0, 0, 0}}},
+		{"short head", map[string][]byte{"head": head[:50], "maxp": maxp, "loca": {0, 0, 0, 1, 0, 2}, "glyf": {0, 0, 0, 0}}},
+		{"short loca", map[string][]byte{"head": head, "maxp": maxp, "loca": {0, 0, 0, 1}, "glyf": {0, 0, 0, 0}}},
+		{"offset out of glyf", map[string][]byte{"head": head, "maxp": maxp, "loca": {0, 0, 0, 1, 0, 8}, "glyf": {0, 0, 0, 0}}},
+		{"decreasing offsets", map[string][]byte{"head": head, "maxp": maxp, "loca": {0, 0, 0, 2, 0, 1}, "glyf": {0, 0, 0, 0}}},
+	} {
+		if _, err := subsetFont(buildFont(testCase.tables), map[int]rune{0: 0, 1: 'A'}); err == nil {
+			t.Errorf("subsetFont() should fail for %s", testCase.name)
+		}
+	}
+}
+
+func TestExportBrokenFont(t *testing.T) {
+	ctx, r, err := NewContext(0)
+	if err != nil {
+		t.Fatalf("NewContext() should succeed, but %v", err)
+	}
+	defer ctx.Delete()
+
+	ctx.BeginFrame(200, 100, 1.0)
+	ctx.EndFrame()
+	r.RenderText(&nanovgo.Paint{}, &nanovgo.Scissor{}, &nanovgo.TextRun{
+		Runes:    []rune("Hi"),
+		X:        []float32{10, 20},
+		Y:        50,
+		Xform:    nanovgo.IdentityMatrix(),
+		FontName: "broken",
+		FontSize: 20,
+		FontData: []byte("not a font"),
+	})
+	if _, err := r.WriteTo(ioutil.Discard); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("WriteTo() should report the font that can't be embedded, but %v", err)
+	}
+}
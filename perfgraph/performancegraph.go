@@ -24,6 +24,9 @@ type PerfGraph struct {
 	values   [nvgGraphHistoryCount]float32
 	head     int
 
+	stat         Stat
+	isStatsGraph bool
+
 	startTime      time.Time
 	lastUpdateTime time.Time
 }
@@ -61,6 +64,11 @@ func (pg *PerfGraph) RenderGraph(ctx *nanovgo.Context, x, y float32) {
 	ctx.SetFillColor(backgroundColor)
 	ctx.Fill()
 
+	if pg.isStatsGraph {
+		pg.renderStatsGraph(ctx, x, y, w, h)
+		return
+	}
+
 	ctx.BeginPath()
 	ctx.MoveTo(x, y+h)
 	for i := 0; i < nvgGraphHistoryCount; i++ {
@@ -0,0 +1,119 @@
+package perfgraph
+
+import (
+	"fmt"
+	"github.com/shibukawa/nanovgo"
+)
+
+// Stat is a counter of nanovgo.FrameStats plotted by the graph created by NewStatsGraph()
+type Stat int
+
+const (
+	StatDrawCalls Stat = iota
+	StatFillTriangles
+	StatStrokeTriangles
+	StatTextTriangles
+	StatTriangles // Sum of fill, stroke and text triangles
+	StatPaths
+	StatPoints
+	StatTextureUploads
+	StatVertexBytes
+	StatUniformBlocks
+)
+
+var statUnits = [...]string{
+	StatDrawCalls:       "calls",
+	StatFillTriangles:   "tris",
+	StatStrokeTriangles: "tris",
+	StatTextTriangles:   "tris",
+	StatTriangles:       "tris",
+	StatPaths:           "paths",
+	StatPoints:          "points",
+	StatTextureUploads:  "uploads",
+	StatVertexBytes:     "bytes",
+	StatUniformBlocks:   "blocks",
+}
+
+// Value returns the counter value in stats
+func (s Stat) Value(stats nanovgo.FrameStats) float32 {
+	switch s {
+	case StatDrawCalls:
+		return float32(stats.DrawCalls)
+	case StatFillTriangles:
+		return float32(stats.FillTriangles)
+	case StatStrokeTriangles:
+		return float32(stats.StrokeTriangles)
+	case StatTextTriangles:
+		return float32(stats.TextTriangles)
+	case StatTriangles:
+		return float32(stats.FillTriangles + stats.StrokeTriangles + stats.TextTriangles)
+	case StatPaths:
+		return float32(stats.Paths)
+	case StatPoints:
+		return float32(stats.Points)
+	case StatTextureUploads:
+		return float32(stats.TextureUploads)
+	case StatVertexBytes:
+		return float32(stats.VertexBytes)
+	case StatUniformBlocks:
+		return float32(stats.UniformBlocks)
+	}
+	return 0
+}
+
+// NewStatsGraph creates PerfGraph instance that plots the counter of nanovgo.FrameStats instead of FPS
+func NewStatsGraph(name, fontFace string, stat Stat) *PerfGraph {
+	pg := NewPerfGraph(name, fontFace)
+	pg.stat = stat
+	pg.isStatsGraph = true
+	return pg
+}
+
+// UpdateStats adds the counter value of the frame to the graph. Call it after EndFrame()
+func (pg *PerfGraph) UpdateStats(stats nanovgo.FrameStats) {
+	pg.head = (pg.head + 1) % nvgGraphHistoryCount
+	pg.values[pg.head] = pg.stat.Value(stats)
+}
+
+func (pg *PerfGraph) renderStatsGraph(ctx *nanovgo.Context, x, y, w, h float32) {
+	var maxValue float32 = 1
+	for _, value := range pg.values {
+		if value > maxValue {
+			maxValue = value
+		}
+	}
+	ctx.BeginPath()
+	ctx.MoveTo(x, y+h)
+	for i := 0; i < nvgGraphHistoryCount; i++ {
+		v := pg.values[(pg.head+i+1)%nvgGraphHistoryCount]
+		vx := x + float32(i)/float32(nvgGraphHistoryCount-1)*w
+		vy := y + h - ((v / maxValue) * h)
+		ctx.LineTo(vx, vy)
+	}
+	ctx.LineTo(x+w, y+h)
+	ctx.SetFillColor(graphColor)
+	ctx.Fill()
+
+	ctx.SetFontFace(pg.fontFace)
+
+	if len(pg.name) > 0 {
+		ctx.SetFontSize(14.0)
+		ctx.SetTextAlign(nanovgo.AlignLeft | nanovgo.AlignTop)
+		ctx.SetFillColor(titleTextColor)
+		ctx.Text(x+3, y+1, pg.name)
+	}
+
+	unit := ""
+	if int(pg.stat) >= 0 && int(pg.stat) < len(statUnits) {
+		unit = statUnits[pg.stat]
+	}
+	ctx.SetFontSize(18.0)
+	ctx.SetTextAlign(nanovgo.AlignRight | nanovgo.AlignTop)
+	ctx.SetFillColor(fpsTextColor)
+	ctx.Text(x+w-3, y+1, fmt.Sprintf("%.0f %s", pg.values[pg.head], unit))
+
+	ctx.SetFontSize(15.0)
+	ctx.SetTextAlign(nanovgo.AlignRight | nanovgo.AlignBottom)
+	ctx.SetFillColor(averageTextColor)
+	ctx.Text(x+w-3, y+h+1, fmt.Sprintf("max %.0f", maxValue))
+}
@@ -17,6 +17,12 @@ var shaderHeader = `
 #define UNIFORMARRAY_SIZE 11
 `
 
+var shaderHeaderGL3 = `
+#version 150 core
+#define NANOVG_GL3 1
+#define USE_UNIFORMBUFFER 1
+`
+
 func prepareTextureBuffer(data []byte, w, h, bpp int) []byte {
 	return data
 }
@@ -14,6 +14,12 @@ var shaderHeader string = `
 #define UNIFORMARRAY_SIZE 11
 `
 
+var shaderHeaderGL3 string = `
+#version 300 es
+#define NANOVG_GL3 1
+#define USE_UNIFORMBUFFER 1
+`
+
 func prepareTextureBuffer(data []byte, w, h, bpp int) []byte {
 	// gl.TexImage2D on WebGL doesn't allow nil as input
 	if data == nil {
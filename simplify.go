@@ -0,0 +1,95 @@
+package nanovgo
+
+// Polyline creates new sub-path through the points given as x, y pairs, like MoveTo() followed by LineTo()s.
+// The points are simplified in the device space by Douglas-Peucker algorithm before they are added to the path,
+// and the points closer than the tessellation tolerance (a quarter of a device pixel) to the simplified line are
+// removed. It makes plotting large data like time series much faster without visible change.
+//
+//	vg.BeginPath()
+//	vg.Polyline(samples)
+//	vg.Stroke()
+func (c *Context) Polyline(points []float32) {
+	count := len(points) / 2
+	if count == 0 {
+		return
+	}
+	xform := c.getState().xform
+	transformed := make([]float32, count*2)
+	for i := 0; i < count; i++ {
+		transformed[i*2], transformed[i*2+1] = xform.TransformPoint(points[i*2], points[i*2+1])
+	}
+	keep := simplifyPolyline(transformed, c.tessTol)
+	commands := make([]float32, 0, len(keep)*3)
+	for i, index := range keep {
+		command := nvgLINETO
+		if i == 0 {
+			command = nvgMOVETO
+		}
+		commands = append(commands, float32(command), transformed[index*2], transformed[index*2+1])
+	}
+	// The commands are already transformed.
+	c.commands = append(c.commands, commands...)
+	c.commandX = points[count*2-2]
+	c.commandY = points[count*2-1]
+}
+
+// simplifyPolyline returns the indexes of the points kept by Douglas-Peucker algorithm. The removed points are
+// within tol from the line through the kept points.
+func simplifyPolyline(points []float32, tol float32) []int {
+	count := len(points) / 2
+	if count <= 2 {
+		indexes := make([]int, count)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes
+	}
+	keep := make([]bool, count)
+	keep[0] = true
+	keep[count-1] = true
+	tol2 := tol * tol
+
+	// Ranges to simplify. It uses the stack instead of the recursion for the long polylines.
+	stack := [][2]int{{0, count - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		maxDist := tol2
+		index := -1
+		for i := first + 1; i < last; i++ {
+			d := distPtLine(points[i*2], points[i*2+1], points[first*2], points[first*2+1], points[last*2], points[last*2+1])
+			if d > maxDist {
+				maxDist = d
+				index = i
+			}
+		}
+		if index >= 0 {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	var indexes []int
+	for i, k := range keep {
+		if k {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// distPtLine returns the squared distance from the point (x, y) to the segment (px, py)-(qx, qy).
+func distPtLine(x, y, px, py, qx, qy float32) float32 {
+	pqx := qx - px
+	pqy := qy - py
+	dx := x - px
+	dy := y - py
+	d := pqx*pqx + pqy*pqy
+	var t float32
+	if d > 0 {
+		t = clampF((pqx*dx+pqy*dy)/d, 0.0, 1.0)
+	}
+	dx = px + t*pqx - x
+	dy = py + t*pqy - y
+	return dx*dx + dy*dy
+}
@@ -0,0 +1,90 @@
+package nanovgo
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func sineSamples(count int) []float32 {
+	points := make([]float32, 0, count*2)
+	for i := 0; i < count; i++ {
+		x := float32(i) * 60 / float32(count-1)
+		points = append(points, x, 14+10*float32(math.Sin(float64(x)*0.3)))
+	}
+	return points
+}
+
+func TestPolyline(t *testing.T) {
+	c, _ := NewSoftwareContext(image.NewRGBA(image.Rect(0, 0, 128, 64)), AntiAlias)
+	defer c.Delete()
+	c.BeginFrame(128, 64, 1.0)
+	c.Scale(2, 2)
+
+	points := sineSamples(10000)
+	c.BeginPath()
+	c.Polyline(points)
+	count := len(c.commands) / 3
+	if count < 10 || count > 500 {
+		t.Errorf("polyline should be simplified to a few points, but %d", count)
+	}
+	if nvgCommands(c.commands[0]) != nvgMOVETO || nvgCommands(c.commands[3]) != nvgLINETO {
+		t.Error("polyline should start with MoveTo and continue with LineTo")
+	}
+	if !nearlyEqual(c.commands[1], 0) || !nearlyEqual(c.commands[len(c.commands)-2], 120) {
+		t.Errorf("end points should be kept in the device space, but %v and %v", c.commands[1], c.commands[len(c.commands)-2])
+	}
+	// All points are within the tolerance from the simplified line.
+	for i := 0; i < len(points); i += 2 {
+		x, y := points[i]*2, points[i+1]*2
+		minDist := float32(math.MaxFloat32)
+		for j := 3; j < len(c.commands); j += 3 {
+			minDist = minF(minDist, distPtLine(x, y, c.commands[j-2], c.commands[j-1], c.commands[j+1], c.commands[j+2]))
+		}
+		if minDist > c.tessTol*c.tessTol*1.01 {
+			t.Fatalf("point (%v, %v) should be within the tolerance, but %v", x, y, sqrtF(minDist))
+		}
+	}
+	if c.commandX != 60 {
+		t.Errorf("last point should be kept in the local coordinates, but %v", c.commandX)
+	}
+
+	c.BeginPath()
+	c.Polyline([]float32{0, 0, 1, 1, 2, 2, 3, 3, 10, 10})
+	if len(c.commands) != 6 {
+		t.Errorf("straight polyline should be 2 points, but %v", c.commands)
+	}
+	c.EndFrame()
+}
+
+func TestPolylineStroke(t *testing.T) {
+	draw := func(polyline bool) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+		c, _ := NewSoftwareContext(img, AntiAlias)
+		defer c.Delete()
+		c.BeginFrame(64, 32, 1.0)
+		c.SetStrokeColor(RGBA(0, 0, 0, 255))
+		c.SetStrokeWidth(2)
+		c.BeginPath()
+		points := sineSamples(3000)
+		if polyline {
+			c.Polyline(points)
+		} else {
+			c.MoveTo(points[0], points[1])
+			for i := 2; i < len(points); i += 2 {
+				c.LineTo(points[i], points[i+1])
+			}
+		}
+		c.Stroke()
+		c.EndFrame()
+		return img
+	}
+	original := draw(false)
+	simplified := draw(true)
+	// The coverage of the edge pixels can change by the tolerance (a quarter pixel) as same as the flattened curves.
+	for i := range original.Pix {
+		if d := int(original.Pix[i]) - int(simplified.Pix[i]); d > 80 || d < -80 {
+			t.Fatalf("simplified polyline should look same as the original, but pixel %d differs by %d", i/4, d)
+		}
+	}
+}
@@ -0,0 +1,554 @@
+package nanovgo
+
+import (
+	"errors"
+	"image"
+)
+
+// NewSoftwareContext makes new NanoVGo context that rasterizes into the specified image without OpenGL.
+// The window size passed to Context.BeginFrame() is stretched to the bounds of the image, so the device
+// pixel ratio should be image width / window width like the GL backend. The image is not cleared by
+// NanoVGo; clear it before drawing a new frame as you would call glClear() on GL backends.
+func NewSoftwareContext(img *image.RGBA, flags CreateFlags) (*Context, error) {
+	if img == nil {
+		return nil, errors.New("nanovgo: software context needs a target image")
+	}
+	params := &softParams{
+		isEdgeAntiAlias: (flags & AntiAlias) != 0,
+		context: &softContext{
+			flags:  flags,
+			target: img,
+		},
+	}
+	return createInternal(params, flags)
+}
+
+type softTexture struct {
+	id            int
+	width, height int
+	texType       TextureType
+	flags         ImageFlags
+	data          []byte
+}
+
+type softPath struct {
+	fills   []Vertex
+	strokes []Vertex
+}
+
+type softCall struct {
+	callType  glnvgCallType
+	image     int
+	paths     []softPath
+	triangles []Vertex
+	uniforms  []glFragUniforms
+	fillRule  FillRule
+	clipped   bool // The call is clipped by the clip region. glnvgCLIP call intersects the current region.
+}
+
+type softContext struct {
+	target    *image.RGBA
+	view      [2]float32
+	textures  []*softTexture
+	textureID int
+	flags     CreateFlags
+	calls     []softCall
+	stencil   []uint8
+}
+
+func (c *softContext) findTexture(id int) *softTexture {
+	for _, texture := range c.textures {
+		if texture.id == id {
+			return texture
+		}
+	}
+	return nil
+}
+
+func (c *softContext) allocTexture() *softTexture {
+	var tex *softTexture
+	for _, texture := range c.textures {
+		if texture.id == 0 {
+			tex = texture
+			break
+		}
+	}
+	if tex == nil {
+		tex = &softTexture{}
+		c.textures = append(c.textures, tex)
+	}
+	c.textureID++
+	tex.id = c.textureID
+	return tex
+}
+
+func (c *softContext) convertPaint(frag *glFragUniforms, paint *Paint, scissor *Scissor, width, fringe, strokeThr float32) error {
+	var texType TextureType
+	var texFlags ImageFlags
+	if paint.image != 0 {
+		tex := c.findTexture(paint.image)
+		if tex == nil {
+			return errors.New("invalid texture in softParams.convertPaint")
+		}
+		texType = tex.texType
+		texFlags = tex.flags
+	}
+	frag.convertPaint(paint, scissor, width, fringe, strokeThr, texType, texFlags)
+	return nil
+}
+
+// clipStencil returns the stencil reference and mask bits to test the clip region.
+func (call *softCall) clipStencil() (uint8, uint8) {
+	if call.clipped {
+		return glnvgClipBit, glnvgClipBit
+	}
+	return 0, 0
+}
+
+// clipPipeline returns the pipeline to draw the color of the calls drawn without stencil.
+func (call *softCall) clipPipeline() softPipeline {
+	pipe := softPipeline{
+		colorWrite: true,
+		cullFace:   true,
+	}
+	if call.clipped {
+		pipe.stencilTest = true
+		pipe.stencilFunc = softEqual
+		pipe.stencilRef = glnvgClipBit
+		pipe.stencilMask = glnvgClipBit
+	}
+	return pipe
+}
+
+func (c *softContext) fill(call *softCall) {
+	clipRef, clipMask := call.clipStencil()
+
+	// Draw shapes
+	pipe := softPipeline{
+		stencilTest:      true,
+		stencilFunc:      softAlways,
+		stencilWriteMask: glnvgStencilBits,
+		stencilPassFront: softIncrWrap,
+		stencilPassBack:  softDecrWrap,
+	}
+	if call.clipped {
+		// Count the winding only in the clip region
+		pipe.stencilFunc = softEqual
+		pipe.stencilRef = clipRef
+		pipe.stencilMask = clipMask
+	}
+	if call.fillRule == EvenOdd {
+		pipe.stencilPassFront = softInvert
+		pipe.stencilPassBack = softInvert
+	}
+	for i := range call.paths {
+		c.draw(softTriangleFan, call.paths[i].fills, &call.uniforms[0], 0, &pipe)
+	}
+
+	// Draw anti-aliased pixels
+	pipe.colorWrite = true
+	pipe.cullFace = true
+	if c.flags&AntiAlias != 0 {
+		pipe.stencilFunc = softEqual
+		pipe.stencilRef = clipRef
+		pipe.stencilMask = glnvgStencilBits | clipMask
+		pipe.stencilPassFront = softKeep
+		pipe.stencilPassBack = softKeep
+		// Draw fringes
+		for i := range call.paths {
+			c.draw(softTriangleStrip, call.paths[i].strokes, &call.uniforms[1], call.image, &pipe)
+		}
+	}
+
+	// Draw fill
+	pipe.stencilFunc = softNotEqual
+	pipe.stencilRef = 0
+	pipe.stencilMask = glnvgStencilBits
+	pipe.stencilFail = softZero
+	pipe.stencilPassFront = softZero
+	pipe.stencilPassBack = softZero
+	c.draw(softTriangles, call.triangles, &call.uniforms[1], call.image, &pipe)
+}
+
+func (c *softContext) convexFill(call *softCall) {
+	pipe := call.clipPipeline()
+	for i := range call.paths {
+		c.draw(softTriangleFan, call.paths[i].fills, &call.uniforms[0], call.image, &pipe)
+	}
+	if c.flags&AntiAlias != 0 {
+		for i := range call.paths {
+			c.draw(softTriangleStrip, call.paths[i].strokes, &call.uniforms[0], call.image, &pipe)
+		}
+	}
+}
+
+func (c *softContext) stroke(call *softCall) {
+	if c.flags&StencilStrokes != 0 {
+		clipRef, clipMask := call.clipStencil()
+		// Fill the stroke base without overlap
+		pipe := softPipeline{
+			colorWrite:       true,
+			cullFace:         true,
+			stencilTest:      true,
+			stencilFunc:      softEqual,
+			stencilRef:       clipRef,
+			stencilMask:      glnvgStencilBits | clipMask,
+			stencilWriteMask: glnvgStencilBits,
+			stencilPassFront: softIncr,
+			stencilPassBack:  softIncr,
+		}
+		for i := range call.paths {
+			c.draw(softTriangleStrip, call.paths[i].strokes, &call.uniforms[1], call.image, &pipe)
+		}
+
+		// Draw anti-aliased pixels.
+		pipe.stencilPassFront = softKeep
+		pipe.stencilPassBack = softKeep
+		for i := range call.paths {
+			c.draw(softTriangleStrip, call.paths[i].strokes, &call.uniforms[0], call.image, &pipe)
+		}
+
+		// Clear stencil buffer.
+		pipe.colorWrite = false
+		pipe.stencilFunc = softAlways
+		pipe.stencilFail = softZero
+		pipe.stencilPassFront = softZero
+		pipe.stencilPassBack = softZero
+		for i := range call.paths {
+			c.draw(softTriangleStrip, call.paths[i].strokes, &call.uniforms[0], call.image, &pipe)
+		}
+	} else {
+		pipe := call.clipPipeline()
+		for i := range call.paths {
+			c.draw(softTriangleStrip, call.paths[i].strokes, &call.uniforms[0], call.image, &pipe)
+		}
+	}
+}
+
+func (c *softContext) triangles(call *softCall) {
+	pipe := call.clipPipeline()
+	c.draw(softTriangles, call.triangles, &call.uniforms[0], call.image, &pipe)
+}
+
+func (c *softContext) triangleStrip(call *softCall) {
+	pipe := call.clipPipeline()
+	c.draw(softTriangleStrip, call.triangles, &call.uniforms[0], call.image, &pipe)
+}
+
+func (c *softContext) clip(call *softCall) {
+	viewport := call.triangles[:6]
+	bounds := call.triangles[6:]
+
+	if !call.clipped {
+		// Set the clip bit on the whole viewport
+		pipe := softPipeline{
+			stencilTest:      true,
+			stencilFunc:      softAlways,
+			stencilRef:       glnvgClipBit,
+			stencilWriteMask: glnvgClipBit,
+			stencilPassFront: softReplace,
+			stencilPassBack:  softReplace,
+		}
+		c.draw(softTriangles, viewport, &call.uniforms[0], 0, &pipe)
+	}
+
+	// Count the winding of the clip path
+	pipe := softPipeline{
+		stencilTest:      true,
+		stencilFunc:      softAlways,
+		stencilWriteMask: glnvgStencilBits,
+		stencilPassFront: softIncrWrap,
+		stencilPassBack:  softDecrWrap,
+	}
+	if call.fillRule == EvenOdd {
+		pipe.stencilPassFront = softInvert
+		pipe.stencilPassBack = softInvert
+	}
+	for i := range call.paths {
+		c.draw(softTriangleFan, call.paths[i].fills, &call.uniforms[0], 0, &pipe)
+	}
+
+	// Clear the clip bit out of the clip path
+	pipe = softPipeline{
+		cullFace:         true,
+		stencilTest:      true,
+		stencilFunc:      softEqual,
+		stencilMask:      glnvgStencilBits,
+		stencilWriteMask: glnvgClipBit,
+		stencilPassFront: softZero,
+		stencilPassBack:  softZero,
+	}
+	c.draw(softTriangles, viewport, &call.uniforms[0], 0, &pipe)
+
+	// Clear the winding
+	pipe = softPipeline{
+		cullFace:         true,
+		stencilTest:      true,
+		stencilFunc:      softAlways,
+		stencilWriteMask: glnvgStencilBits,
+		stencilPassFront: softZero,
+		stencilPassBack:  softZero,
+	}
+	c.draw(softTriangles, bounds, &call.uniforms[0], 0, &pipe)
+}
+
+type softParams struct {
+	isEdgeAntiAlias bool
+	clipPath        *ClipPath // clip path of the last recorded call
+	context         *softContext
+}
+
+func (p *softParams) EdgeAntiAlias() bool {
+	return p.isEdgeAntiAlias
+}
+
+func (p *softParams) RenderCreate() error {
+	return nil
+}
+
+func (p *softParams) RenderCreateTexture(texType TextureType, w, h int, flags ImageFlags, data []byte) int {
+	tex := p.context.allocTexture()
+	tex.width = w
+	tex.height = h
+	tex.texType = texType
+	tex.flags = flags
+
+	bpp := 1
+	if texType == TextureRGBA {
+		bpp = 4
+	}
+	tex.data = make([]byte, w*h*bpp)
+	copy(tex.data, data)
+	return tex.id
+}
+
+func (p *softParams) RenderDeleteTexture(id int) error {
+	tex := p.context.findTexture(id)
+	if tex != nil && (tex.flags&ImageNoDelete) == 0 {
+		tex.id = 0
+		tex.data = nil
+		return nil
+	}
+	return errors.New("invalid texture in softParams.deleteTexture")
+}
+
+func (p *softParams) RenderUpdateTexture(image, x, y, w, h int, data []byte) error {
+	tex := p.context.findTexture(image)
+	if tex == nil {
+		return errors.New("invalid texture in softParams.updateTexture")
+	}
+	// Same as GL backend, whole rows are updated from the full size image data.
+	stride := tex.width
+	if tex.texType == TextureRGBA {
+		stride *= 4
+	}
+	start := y * stride
+	end := (y + h) * stride
+	if end > len(data) {
+		end = len(data)
+	}
+	if start < end {
+		copy(tex.data[start:end], data[start:end])
+	}
+	return nil
+}
+
+func (p *softParams) RenderGetTextureSize(image int) (int, int, error) {
+	tex := p.context.findTexture(image)
+	if tex == nil {
+		return -1, -1, errors.New("invalid texture in softParams.getTextureSize")
+	}
+	return tex.width, tex.height, nil
+}
+
+func (p *softParams) RenderViewport(width, height int) {
+	p.context.view[0] = float32(width)
+	p.context.view[1] = float32(height)
+}
+
+func (p *softParams) RenderCancel() {
+	p.context.calls = p.context.calls[:0]
+	p.clipPath = nil
+}
+
+func (p *softParams) RenderFlush() {
+	c := p.context
+	if len(c.calls) > 0 && c.view[0] > 0 && c.view[1] > 0 {
+		size := c.target.Bounds().Size()
+		if len(c.stencil) < size.X*size.Y {
+			c.stencil = make([]uint8, size.X*size.Y)
+		} else {
+			for i := range c.stencil {
+				c.stencil[i] = 0
+			}
+		}
+		for i := range c.calls {
+			call := &c.calls[i]
+			switch call.callType {
+			case glnvgFILL:
+				c.fill(call)
+			case glnvgCONVEXFILL:
+				c.convexFill(call)
+			case glnvgSTROKE:
+				c.stroke(call)
+			case glnvgTRIANGLES:
+				c.triangles(call)
+			case glnvgTRIANGLESTRIP:
+				c.triangleStrip(call)
+			case glnvgCLIP:
+				c.clip(call)
+			}
+		}
+	}
+	c.calls = c.calls[:0]
+	p.clipPath = nil
+}
+
+func (p *softParams) RenderFill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath) {
+	p.RenderFillRule(paint, scissor, fringe, bounds, paths, NonZero)
+}
+
+func (p *softParams) RenderFillRule(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath, fillRule FillRule) {
+	c := p.context
+	call := softCall{
+		image:    paint.image,
+		paths:    make([]softPath, len(paths)),
+		fillRule: fillRule,
+		clipped:  p.useClip(scissor.clip),
+	}
+	if len(paths) == 1 && paths[0].convex {
+		call.callType = glnvgCONVEXFILL
+	} else {
+		call.callType = glnvgFILL
+	}
+	for i := range paths {
+		call.paths[i].fills = append([]Vertex(nil), paths[i].fills...)
+		call.paths[i].strokes = append([]Vertex(nil), paths[i].strokes...)
+	}
+
+	var paintFrag *glFragUniforms
+	if call.callType == glnvgFILL {
+		// Quad
+		call.triangles = softQuad(bounds)
+		call.uniforms = make([]glFragUniforms, 2)
+		// Simple shader for stencil
+		u0 := &call.uniforms[0]
+		u0.setStrokeThr(-1.0)
+		u0.setType(nsvgShaderSIMPLE)
+		paintFrag = &call.uniforms[1]
+	} else {
+		call.uniforms = make([]glFragUniforms, 1)
+		paintFrag = &call.uniforms[0]
+	}
+	// Fill shader
+	c.convertPaint(paintFrag, paint, scissor, fringe, fringe, -1.0)
+	c.calls = append(c.calls, call)
+}
+
+func (p *softParams) RenderStroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []RenderPath) {
+	c := p.context
+	call := softCall{
+		callType: glnvgSTROKE,
+		image:    paint.image,
+		paths:    make([]softPath, len(paths)),
+		clipped:  p.useClip(scissor.clip),
+	}
+	for i := range paths {
+		call.paths[i].strokes = append([]Vertex(nil), paths[i].strokes...)
+	}
+
+	// Fill shader
+	if c.flags&StencilStrokes != 0 {
+		call.uniforms = make([]glFragUniforms, 2)
+		c.convertPaint(&call.uniforms[0], paint, scissor, strokeWidth, fringe, -1.0)
+		c.convertPaint(&call.uniforms[1], paint, scissor, strokeWidth, fringe, -1.0-0.5/266.0)
+	} else {
+		call.uniforms = make([]glFragUniforms, 1)
+		c.convertPaint(&call.uniforms[0], paint, scissor, strokeWidth, fringe, -1.0)
+	}
+	c.calls = append(c.calls, call)
+}
+
+func (p *softParams) RenderTriangles(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+	p.appendTriangles(glnvgTRIANGLES, paint, scissor, vertexes)
+}
+
+func (p *softParams) RenderTriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+	p.appendTriangles(glnvgTRIANGLESTRIP, paint, scissor, vertexes)
+}
+
+func (p *softParams) appendTriangles(callType glnvgCallType, paint *Paint, scissor *Scissor, vertexes []Vertex) {
+	c := p.context
+	call := softCall{
+		callType:  callType,
+		image:     paint.image,
+		triangles: append([]Vertex(nil), vertexes...),
+		uniforms:  make([]glFragUniforms, 1),
+		clipped:   p.useClip(scissor.clip),
+	}
+	// Fill shader
+	f0 := &call.uniforms[0]
+	c.convertPaint(f0, paint, scissor, 1.0, 1.0, -1.0)
+	f0.setType(nsvgShaderIMG)
+	c.calls = append(c.calls, call)
+}
+
+// useClip adds the calls to draw the clip region if it is changed from the last call, and returns true if
+// the next call should be clipped. See glParams.useClip().
+func (p *softParams) useClip(clip *ClipPath) bool {
+	if clip == p.clipPath {
+		return clip != nil
+	}
+	var chain []*ClipPath
+	intersect := false
+	for parent := clip; parent != nil; parent = parent.parent {
+		if parent == p.clipPath {
+			intersect = true
+			break
+		}
+		chain = append(chain, parent)
+	}
+	c := p.context
+	for i := len(chain) - 1; i >= 0; i-- {
+		call := softCall{
+			callType:  glnvgCLIP,
+			paths:     make([]softPath, len(chain[i].paths)),
+			triangles: append(softQuad([4]float32{0, 0, c.view[0], c.view[1]}), softQuad(chain[i].bounds)...),
+			uniforms:  make([]glFragUniforms, 1),
+			fillRule:  chain[i].fillRule,
+			clipped:   intersect,
+		}
+		for j := range chain[i].paths {
+			call.paths[j].fills = chain[i].paths[j].fills
+		}
+		// Simple shader for stencil
+		u0 := &call.uniforms[0]
+		u0.setStrokeThr(-1.0)
+		u0.setType(nsvgShaderSIMPLE)
+		c.calls = append(c.calls, call)
+		intersect = true
+	}
+	p.clipPath = clip
+	return clip != nil
+}
+
+// softQuad returns two front facing triangles of the bounds (minX, minY, maxX, maxY).
+func softQuad(bounds [4]float32) []Vertex {
+	return []Vertex{
+		{bounds[0], bounds[3], 0.5, 1.0},
+		{bounds[2], bounds[3], 0.5, 1.0},
+		{bounds[2], bounds[1], 0.5, 1.0},
+		{bounds[0], bounds[3], 0.5, 1.0},
+		{bounds[2], bounds[1], 0.5, 1.0},
+		{bounds[0], bounds[1], 0.5, 1.0},
+	}
+}
+
+func (p *softParams) RenderDelete() {
+	c := p.context
+	for _, texture := range c.textures {
+		texture.id = 0
+		texture.data = nil
+	}
+	p.context = nil
+}
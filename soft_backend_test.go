@@ -0,0 +1,258 @@
+package nanovgo
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestSoftwareFillRect(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	c, err := NewSoftwareContext(img, AntiAlias)
+	if err != nil {
+		t.Fatalf("NewSoftwareContext() should succeed, but %v", err)
+	}
+	defer c.Delete()
+
+	c.BeginFrame(32, 32, 1.0)
+	c.BeginPath()
+	c.Rect(8, 8, 16, 16)
+	c.SetFillColor(RGBA(255, 0, 0, 255))
+	c.Fill()
+	c.EndFrame()
+
+	inside := img.RGBAAt(16, 16)
+	if inside.R != 255 || inside.G != 0 || inside.B != 0 || inside.A != 255 {
+		t.Errorf("pixel inside of the rect should be red, but %v", inside)
+	}
+	outside := img.RGBAAt(2, 2)
+	if outside.A != 0 {
+		t.Errorf("pixel outside of the rect should be transparent, but %v", outside)
+	}
+	edge := img.RGBAAt(8, 16)
+	if edge.R != 255 || edge.A != 255 {
+		t.Errorf("pixel on the rect edge should be covered, but %v", edge)
+	}
+}
+
+func TestSoftwareScissor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	c, err := NewSoftwareContext(img, AntiAlias)
+	if err != nil {
+		t.Fatalf("NewSoftwareContext() should succeed, but %v", err)
+	}
+	defer c.Delete()
+
+	c.BeginFrame(16, 16, 2.0)
+	c.Scissor(0, 0, 8, 16)
+	c.BeginPath()
+	c.Rect(0, 0, 16, 16)
+	c.SetFillColor(RGBA(0, 0, 255, 255))
+	c.Fill()
+	c.EndFrame()
+
+	if left := img.RGBAAt(4, 16); left.B != 255 || left.A != 255 {
+		t.Errorf("pixel inside of the scissor should be blue, but %v", left)
+	}
+	if right := img.RGBAAt(28, 16); right.A != 0 {
+		t.Errorf("pixel outside of the scissor should be transparent, but %v", right)
+	}
+}
+
+func TestSoftwareFillRule(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 96, 32))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+
+	c.BeginFrame(96, 32, 1.0)
+	c.SetFillColor(RGBA(0, 0, 0, 255))
+
+	// Pentagram centered at (16, 16). The center is surrounded twice.
+	star := func(cx, cy float32) {
+		c.BeginPath()
+		for i := 0; i < 5; i++ {
+			a := float64(i)*4*math.Pi/5 - math.Pi/2
+			x, y := cx+14*float32(math.Cos(a)), cy+14*float32(math.Sin(a))
+			if i == 0 {
+				c.MoveTo(x, y)
+			} else {
+				c.LineTo(x, y)
+			}
+		}
+		c.ClosePath()
+	}
+	star(16, 16)
+	c.Fill()
+	c.SetFillRule(EvenOdd)
+	star(48, 16)
+	c.Fill()
+
+	// Rings drawn in the opposite directions without PathWinding(). Both are made Solid with NonZero.
+	c.SetFillRule(NonZero)
+	c.BeginPath()
+	c.MoveTo(66, 2)
+	c.LineTo(66, 30)
+	c.LineTo(94, 30)
+	c.LineTo(94, 2)
+	c.ClosePath()
+	c.MoveTo(74, 10)
+	c.LineTo(86, 10)
+	c.LineTo(86, 22)
+	c.LineTo(74, 22)
+	c.ClosePath()
+	c.Fill()
+	c.EndFrame()
+
+	if img.RGBAAt(16, 16).A != 255 {
+		t.Error("center of the star should be filled with NonZero")
+	}
+	if img.RGBAAt(48, 16).A != 0 {
+		t.Error("center of the star should be empty with EvenOdd")
+	}
+	if img.RGBAAt(48, 5).A != 255 {
+		t.Error("point of the star should be filled with EvenOdd")
+	}
+	if img.RGBAAt(80, 16).A != 255 || img.RGBAAt(70, 16).A != 255 {
+		t.Error("inner ring without PathWinding() should be solid with NonZero")
+	}
+}
+
+func TestSoftwareFillFringe(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 12))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+
+	// clockwise rect, which is a hole in a counter-clockwise path
+	rect := func(x, y, w, h float32) {
+		c.MoveTo(x, y)
+		c.LineTo(x+w, y)
+		c.LineTo(x+w, y+h)
+		c.LineTo(x, y+h)
+		c.ClosePath()
+	}
+	c.BeginFrame(40, 12, 1.0)
+	c.SetFillColor(RGBA(0, 0, 0, 255))
+	// Two clockwise sub-paths without PathWinding()
+	c.BeginPath()
+	rect(2.5, 2, 6, 8)
+	rect(12.5, 2, 6, 8)
+	c.Fill()
+	// Clockwise ring with a counter-clockwise hole. EvenOdd keeps their directions.
+	c.SetFillRule(EvenOdd)
+	c.BeginPath()
+	rect(22.5, 2, 16, 8)
+	c.MoveTo(28.5, 4)
+	c.LineTo(28.5, 8)
+	c.LineTo(32.5, 8)
+	c.LineTo(32.5, 4)
+	c.ClosePath()
+	c.Fill()
+	c.EndFrame()
+
+	for x, alpha := range map[int]uint8{1: 0, 2: 128, 3: 255, 8: 128, 9: 0, 11: 0, 12: 128, 18: 128, 19: 0,
+		21: 0, 22: 128, 27: 255, 28: 128, 30: 0, 32: 128, 33: 255, 38: 128, 39: 0} {
+		if a := int(img.RGBAAt(x, 6).A); a < int(alpha)-2 || a > int(alpha)+2 {
+			t.Errorf("alpha at x=%d should be %d, but %d", x, alpha, a)
+		}
+	}
+}
+
+func TestSoftwareClip(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	c, _ := NewSoftwareContext(img, AntiAlias|StencilStrokes)
+	defer c.Delete()
+
+	c.BeginFrame(64, 32, 1.0)
+	c.SetFillColor(RGBA(255, 0, 0, 255))
+
+	// Circle clip intersected with the left half
+	c.Save()
+	c.BeginPath()
+	c.Circle(16, 16, 12)
+	c.Clip()
+	c.Save()
+	c.BeginPath()
+	c.Rect(0, 0, 16, 32)
+	c.Clip()
+	c.BeginPath()
+	c.Rect(0, 0, 32, 32)
+	c.Fill()
+	c.Restore()
+	// Stroke clipped by the circle only
+	c.SetStrokeColor(RGBA(0, 0, 255, 255))
+	c.SetStrokeWidth(4)
+	c.BeginPath()
+	c.MoveTo(0, 28)
+	c.LineTo(32, 28)
+	c.MoveTo(0, 16)
+	c.LineTo(32, 16)
+	c.Stroke()
+	c.Restore()
+
+	// Not clipped after Restore()
+	c.BeginPath()
+	c.Rect(40, 8, 16, 16)
+	c.Fill()
+	c.EndFrame()
+
+	for _, testCase := range []struct {
+		x, y    int
+		r, b, a uint8
+	}{
+		{10, 10, 255, 0, 255}, // in the circle and the left half
+		{22, 10, 0, 0, 0},     // out of the left half
+		{2, 2, 0, 0, 0},       // out of the circle
+		{24, 16, 0, 255, 255}, // stroke in the circle
+		{16, 28, 0, 0, 0},     // stroke out of the circle
+		{48, 16, 255, 0, 255},
+	} {
+		pixel := img.RGBAAt(testCase.x, testCase.y)
+		if pixel.R != testCase.r || pixel.B != testCase.b || pixel.A != testCase.a {
+			t.Errorf("pixel (%d, %d) should be %v, %v, %v, but %v", testCase.x, testCase.y, testCase.r, testCase.b, testCase.a, pixel)
+		}
+	}
+}
+
+func TestSoftwareStrokeAlign(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 96, 32))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+
+	c.BeginFrame(96, 32, 1.0)
+	c.SetStrokeColor(RGBA(0, 0, 255, 255))
+	c.SetStrokeWidth(4)
+	for i, align := range []StrokeAlign{Inside, Center, Outside} {
+		c.SetStrokeAlign(align)
+		c.BeginPath()
+		c.Rect(float32(i*32+8), 8, 16, 16)
+		c.Stroke()
+	}
+	c.EndFrame()
+
+	for _, testCase := range []struct {
+		x, y int
+		a    uint8
+	}{
+		{9, 16, 255}, // inside of [8, 24]
+		{11, 16, 255},
+		{6, 16, 0},
+		{13, 16, 0},
+		{6, 6, 0},
+		{38, 16, 255}, // center of [40, 56]
+		{41, 16, 255},
+		{36, 16, 0},
+		{43, 16, 0},
+		{69, 16, 255}, // outside of [72, 88]
+		{71, 16, 255},
+		{90, 16, 255},
+		{66, 16, 0},
+		{73, 16, 0},
+		{87, 16, 0},
+		{93, 16, 0},
+		{69, 5, 255}, // miter join
+	} {
+		if pixel := img.RGBAAt(testCase.x, testCase.y); pixel.A != testCase.a {
+			t.Errorf("alpha of pixel (%d, %d) should be %v, but %v", testCase.x, testCase.y, testCase.a, pixel)
+		}
+	}
+}
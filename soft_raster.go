@@ -0,0 +1,372 @@
+package nanovgo
+
+import (
+	"math"
+)
+
+// The software rasterizer emulates the fixed function pipeline and the fill shader of the GL backend
+// so that both backends produce the same pictures.
+
+type softPrimitive int
+
+const (
+	softTriangles softPrimitive = iota
+	softTriangleStrip
+	softTriangleFan
+)
+
+type softStencilFunc int
+
+const (
+	softAlways softStencilFunc = iota
+	softEqual
+	softNotEqual
+)
+
+type softStencilOp int
+
+const (
+	softKeep softStencilOp = iota
+	softZero
+	softIncr
+	softIncrWrap
+	softDecrWrap
+	softInvert
+	softReplace
+)
+
+type softPipeline struct {
+	colorWrite       bool
+	cullFace         bool
+	stencilTest      bool
+	stencilFunc      softStencilFunc
+	stencilRef       uint8
+	stencilMask      uint8 // mask of the stencil test
+	stencilWriteMask uint8
+	stencilFail      softStencilOp
+	stencilPassFront softStencilOp
+	stencilPassBack  softStencilOp
+}
+
+func (p *softPipeline) testStencil(value uint8) bool {
+	switch p.stencilFunc {
+	case softEqual:
+		return value&p.stencilMask == p.stencilRef&p.stencilMask
+	case softNotEqual:
+		return value&p.stencilMask != p.stencilRef&p.stencilMask
+	}
+	return true
+}
+
+func (p *softPipeline) applyStencilOp(op softStencilOp, value uint8) uint8 {
+	return value&^p.stencilWriteMask | applyStencilOp(op, value, p.stencilRef)&p.stencilWriteMask
+}
+
+func applyStencilOp(op softStencilOp, value, ref uint8) uint8 {
+	switch op {
+	case softZero:
+		return 0
+	case softIncr:
+		if value < 0xff {
+			return value + 1
+		}
+		return value
+	case softIncrWrap:
+		return value + 1
+	case softDecrWrap:
+		return value - 1
+	case softInvert:
+		return ^value
+	case softReplace:
+		return ref
+	}
+	return value
+}
+
+// softRaster holds the per draw call state to rasterize triangles.
+type softRaster struct {
+	context       *softContext
+	pipe          *softPipeline
+	frag          *glFragUniforms
+	texture       *softTexture
+	width         int
+	height        int
+	scaleX        float32
+	scaleY        float32
+	edgeAntiAlias bool
+}
+
+func (c *softContext) draw(mode softPrimitive, vertexes []Vertex, frag *glFragUniforms, image int, pipe *softPipeline) {
+	if len(vertexes) < 3 {
+		return
+	}
+	size := c.target.Bounds().Size()
+	r := &softRaster{
+		context:       c,
+		pipe:          pipe,
+		frag:          frag,
+		width:         size.X,
+		height:        size.Y,
+		scaleX:        float32(size.X) / c.view[0],
+		scaleY:        float32(size.Y) / c.view[1],
+		edgeAntiAlias: c.flags&AntiAlias != 0,
+	}
+	if image != 0 {
+		r.texture = c.findTexture(image)
+	}
+	switch mode {
+	case softTriangles:
+		for i := 0; i+2 < len(vertexes); i += 3 {
+			r.triangle(&vertexes[i], &vertexes[i+1], &vertexes[i+2])
+		}
+	case softTriangleStrip:
+		for i := 0; i+2 < len(vertexes); i++ {
+			// Odd triangles are flipped to keep the winding of the strip.
+			if i%2 == 0 {
+				r.triangle(&vertexes[i], &vertexes[i+1], &vertexes[i+2])
+			} else {
+				r.triangle(&vertexes[i+1], &vertexes[i], &vertexes[i+2])
+			}
+		}
+	case softTriangleFan:
+		for i := 1; i+1 < len(vertexes); i++ {
+			r.triangle(&vertexes[0], &vertexes[i], &vertexes[i+1])
+		}
+	}
+}
+
+// isTopLeftEdge decides the owner of the pixels just on the edge shared by two triangles.
+func isTopLeftEdge(dx, dy float64) bool {
+	return dy > 0 || (dy == 0 && dx < 0)
+}
+
+func (r *softRaster) triangle(v0, v1, v2 *Vertex) {
+	x0, y0 := float64(v0.x*r.scaleX), float64(v0.y*r.scaleY)
+	x1, y1 := float64(v1.x*r.scaleX), float64(v1.y*r.scaleY)
+	x2, y2 := float64(v2.x*r.scaleX), float64(v2.y*r.scaleY)
+
+	area := (x1-x0)*(y2-y0) - (x2-x0)*(y1-y0)
+	if area == 0 || math.IsNaN(area) {
+		return
+	}
+	// The view is flipped vertically in GL window coordinates,
+	// so counter-clockwise (front) triangles have negative area here.
+	front := area < 0
+	if r.pipe.cullFace && !front {
+		return
+	}
+	if area < 0 {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+		v1, v2 = v2, v1
+		area = -area
+	}
+
+	minX := int(math.Floor(math.Min(x0, math.Min(x1, x2)) - 0.5))
+	maxX := int(math.Ceil(math.Max(x0, math.Max(x1, x2)) - 0.5))
+	minY := int(math.Floor(math.Min(y0, math.Min(y1, y2)) - 0.5))
+	maxY := int(math.Ceil(math.Max(y0, math.Max(y1, y2)) - 0.5))
+	if minX < 0 {
+		minX = 0
+	}
+	if minY < 0 {
+		minY = 0
+	}
+	if maxX > r.width-1 {
+		maxX = r.width - 1
+	}
+	if maxY > r.height-1 {
+		maxY = r.height - 1
+	}
+
+	tl0 := isTopLeftEdge(x2-x1, y2-y1)
+	tl1 := isTopLeftEdge(x0-x2, y0-y2)
+	tl2 := isTopLeftEdge(x1-x0, y1-y0)
+	invArea := 1.0 / area
+
+	for py := minY; py <= maxY; py++ {
+		cy := float64(py) + 0.5
+		for px := minX; px <= maxX; px++ {
+			cx := float64(px) + 0.5
+			w0 := (x2-x1)*(cy-y1) - (y2-y1)*(cx-x1)
+			w1 := (x0-x2)*(cy-y2) - (y0-y2)*(cx-x2)
+			w2 := (x1-x0)*(cy-y0) - (y1-y0)*(cx-x0)
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			if (w0 == 0 && !tl0) || (w1 == 0 && !tl1) || (w2 == 0 && !tl2) {
+				continue
+			}
+			b0 := float32(w0 * invArea)
+			b1 := float32(w1 * invArea)
+			b2 := float32(w2 * invArea)
+			u := b0*v0.u + b1*v1.u + b2*v2.u
+			v := b0*v0.v + b1*v1.v + b2*v2.v
+			r.fragment(px, py, u, v, front)
+		}
+	}
+}
+
+func (r *softRaster) fragment(px, py int, u, v float32, front bool) {
+	fx := (float32(px) + 0.5) / r.scaleX
+	fy := (float32(py) + 0.5) / r.scaleY
+	color, discard := r.shade(fx, fy, u, v)
+	if discard {
+		return
+	}
+	pipe := r.pipe
+	if pipe.stencilTest {
+		stencil := &r.context.stencil[py*r.width+px]
+		if !pipe.testStencil(*stencil) {
+			*stencil = pipe.applyStencilOp(pipe.stencilFail, *stencil)
+			return
+		}
+		if front {
+			*stencil = pipe.applyStencilOp(pipe.stencilPassFront, *stencil)
+		} else {
+			*stencil = pipe.applyStencilOp(pipe.stencilPassBack, *stencil)
+		}
+	}
+	if pipe.colorWrite {
+		r.blend(px, py, color)
+	}
+}
+
+func (r *softRaster) blend(px, py int, color [4]float32) {
+	target := r.context.target
+	min := target.Bounds().Min
+	offset := target.PixOffset(min.X+px, min.Y+py)
+	pix := target.Pix[offset : offset+4 : offset+4]
+	srcA := clampF(color[3], 0.0, 1.0)
+	for i := 0; i < 4; i++ {
+		src := clampF(color[i], 0.0, 1.0)
+		dst := float32(pix[i]) / 255.0
+		pix[i] = uint8(clampF(src+dst*(1.0-srcA), 0.0, 1.0)*255.0 + 0.5)
+	}
+}
+
+func (r *softRaster) shade(fx, fy, u, v float32) ([4]float32, bool) {
+	frag := r.frag
+	scissor := r.scissorMask(fx, fy)
+	strokeAlpha := float32(1.0)
+	if r.edgeAntiAlias {
+		strokeAlpha = minF(1.0, (1.0-absF(u*2.0-1.0))*frag[40]) * minF(1.0, v)
+	}
+	var result [4]float32
+	switch int(frag[43]) {
+	case nsvgShaderFILLGRAD:
+		// Calculate gradient color using box gradient
+		ptX, ptY := transformMat3x4(frag[12:24], fx, fy)
+		feather := frag[39]
+		d := clampF((sdroundrect(ptX, ptY, frag[36], frag[37], frag[38])+feather*0.5)/feather, 0.0, 1.0)
+		alpha := strokeAlpha * scissor
+		for i := 0; i < 4; i++ {
+			result[i] = (frag[24+i]*(1.0-d) + frag[28+i]*d) * alpha
+		}
+	case nsvgShaderFILLIMG:
+		// Calculate color from texture
+		ptX, ptY := transformMat3x4(frag[12:24], fx, fy)
+		color := r.sample(ptX/frag[36], ptY/frag[37])
+		alpha := strokeAlpha * scissor
+		for i := 0; i < 4; i++ {
+			result[i] = color[i] * frag[24+i] * alpha
+		}
+	case nsvgShaderSIMPLE:
+		result = [4]float32{1, 1, 1, 1}
+	case nsvgShaderIMG:
+		color := r.sample(u, v)
+		for i := 0; i < 4; i++ {
+			result[i] = color[i] * scissor * frag[24+i]
+		}
+	}
+	if r.edgeAntiAlias && strokeAlpha < frag[41] {
+		return result, true
+	}
+	return result, false
+}
+
+func (r *softRaster) scissorMask(fx, fy float32) float32 {
+	frag := r.frag
+	scX, scY := transformMat3x4(frag[0:12], fx, fy)
+	scX = 0.5 - (absF(scX)-frag[32])*frag[34]
+	scY = 0.5 - (absF(scY)-frag[33])*frag[35]
+	return clampF(scX, 0.0, 1.0) * clampF(scY, 0.0, 1.0)
+}
+
+// sample fetches the texel with bilinear filter and converts it by texType like the fill shader.
+func (r *softRaster) sample(s, t float32) [4]float32 {
+	tex := r.texture
+	if tex == nil || tex.width == 0 || tex.height == 0 {
+		return [4]float32{0, 0, 0, 1}
+	}
+	x := s*float32(tex.width) - 0.5
+	y := t*float32(tex.height) - 0.5
+	fx := float32(math.Floor(float64(x)))
+	fy := float32(math.Floor(float64(y)))
+	ax := x - fx
+	ay := y - fy
+	x0 := wrapTexel(int(fx), tex.width, tex.flags&ImageRepeatX != 0)
+	x1 := wrapTexel(int(fx)+1, tex.width, tex.flags&ImageRepeatX != 0)
+	y0 := wrapTexel(int(fy), tex.height, tex.flags&ImageRepeatY != 0)
+	y1 := wrapTexel(int(fy)+1, tex.height, tex.flags&ImageRepeatY != 0)
+
+	c00 := tex.texel(x0, y0)
+	c10 := tex.texel(x1, y0)
+	c01 := tex.texel(x0, y1)
+	c11 := tex.texel(x1, y1)
+	var color [4]float32
+	for i := 0; i < 4; i++ {
+		top := c00[i]*(1.0-ax) + c10[i]*ax
+		bottom := c01[i]*(1.0-ax) + c11[i]*ax
+		color[i] = top*(1.0-ay) + bottom*ay
+	}
+	switch int(r.frag[42]) {
+	case 1:
+		color = [4]float32{color[0] * color[3], color[1] * color[3], color[2] * color[3], color[3]}
+	case 2:
+		color = [4]float32{color[0], color[0], color[0], color[0]}
+	}
+	return color
+}
+
+func (t *softTexture) texel(x, y int) [4]float32 {
+	if t.texType == TextureRGBA {
+		offset := (y*t.width + x) * 4
+		return [4]float32{
+			float32(t.data[offset]) / 255.0,
+			float32(t.data[offset+1]) / 255.0,
+			float32(t.data[offset+2]) / 255.0,
+			float32(t.data[offset+3]) / 255.0,
+		}
+	}
+	l := float32(t.data[y*t.width+x]) / 255.0
+	return [4]float32{l, l, l, 1.0}
+}
+
+func wrapTexel(i, size int, repeat bool) int {
+	if repeat {
+		i %= size
+		if i < 0 {
+			i += size
+		}
+		return i
+	}
+	if i < 0 {
+		return 0
+	}
+	if i >= size {
+		return size - 1
+	}
+	return i
+}
+
+func transformMat3x4(mat []float32, x, y float32) (float32, float32) {
+	return mat[0]*x + mat[4]*y + mat[8], mat[1]*x + mat[5]*y + mat[9]
+}
+
+func sdroundrect(ptX, ptY, extX, extY, rad float32) float32 {
+	dx := absF(ptX) - (extX - rad)
+	dy := absF(ptY) - (extY - rad)
+	ox := maxF(dx, 0.0)
+	oy := maxF(dy, 0.0)
+	return minF(maxF(dx, dy), 0.0) + sqrtF(ox*ox+oy*oy) - rad
+}
@@ -0,0 +1,51 @@
+package nanovgo
+
+// FrameStats is the rendering statistics of the current frame. The counters are reset by BeginFrame().
+type FrameStats struct {
+	DrawCalls       int // Draw calls issued by the renderer, or estimated from Fill(), Stroke() and text functions
+	FillTriangles   int
+	StrokeTriangles int
+	TextTriangles   int
+	Paths           int // Paths tessellated by Fill() and Stroke()
+	Points          int // Points of the tessellated paths
+	TextureUploads  int // Texture creations and updates with pixel data, including the font atlas
+	// VertexBytes and UniformBlocks are reported by the renderer when EndFrame() flushes the frame.
+	// They are 0 before EndFrame() or if the renderer doesn't implement FrameStatsReporter. After EndFrame(),
+	// DrawCalls is also reported by the renderer if it implements FrameStatsReporter, so it includes
+	// the savings of the batched calls.
+	VertexBytes   int
+	UniformBlocks int
+}
+
+// FrameStatsReporter is an optional interface of Renderer to report the statistics of the last flushed frame.
+type FrameStatsReporter interface {
+	// RenderFrameStats returns the bytes of vertex data and the count of uniform blocks uploaded and
+	// the count of draw calls issued by the last RenderFlush().
+	RenderFrameStats() (vertexBytes, uniformBlocks, drawCalls int)
+}
+
+// FrameStats returns the rendering statistics since the last BeginFrame(). Call it after EndFrame() to get
+// the complete statistics of the frame.
+func (c *Context) FrameStats() FrameStats {
+	stats := FrameStats{
+		DrawCalls:       c.drawCallCount,
+		FillTriangles:   c.fillTriCount,
+		StrokeTriangles: c.strokeTriCount,
+		TextTriangles:   c.textTriCount,
+		Paths:           c.pathCount,
+		Points:          c.pointCount,
+		TextureUploads:  c.textureUploads,
+	}
+	// The renderer reports the last flushed frame. Don't mix it with the counters of the current frame.
+	if reporter, ok := c.params.(FrameStatsReporter); ok && c.frameFlushed {
+		stats.VertexBytes, stats.UniformBlocks, stats.DrawCalls = reporter.RenderFrameStats()
+	}
+	return stats
+}
+
+func (c *Context) countPaths(paths []RenderPath) {
+	c.pathCount += len(paths)
+	for i := range paths {
+		c.pointCount += paths[i].count
+	}
+}
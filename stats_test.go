@@ -0,0 +1,70 @@
+package nanovgo
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFrameStats(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+
+	c.BeginFrame(32, 32, 1.0)
+	c.BeginPath()
+	c.Rect(4, 4, 8, 8)
+	c.Rect(16, 16, 8, 8)
+	c.SetFillColor(RGBA(255, 0, 0, 255))
+	c.Fill()
+	c.UpdateImage(c.CreateImageRGBA(2, 2, 0, make([]byte, 16)), make([]byte, 16))
+	c.EndFrame()
+
+	stats := c.FrameStats()
+	if stats.Paths != 2 || stats.Points != 8 {
+		t.Errorf("stats should count 2 paths and 8 points, but %d paths and %d points", stats.Paths, stats.Points)
+	}
+	if stats.DrawCalls != 4 || stats.FillTriangles == 0 {
+		t.Errorf("stats should count draw calls and triangles, but %+v", stats)
+	}
+	if stats.TextureUploads != 2 {
+		t.Errorf("stats should count 2 texture uploads, but %d", stats.TextureUploads)
+	}
+
+	c.BeginFrame(32, 32, 1.0)
+	if stats := c.FrameStats(); stats.DrawCalls != 0 || stats.Paths != 0 || stats.TextureUploads != 0 {
+		t.Errorf("BeginFrame() should reset stats, but %+v", stats)
+	}
+	c.EndFrame()
+}
+
+// reportingRenderer reports the statistics of the flushed frame like GL backend.
+type reportingRenderer struct {
+	stubRenderer
+}
+
+func (r *reportingRenderer) RenderFrameStats() (vertexBytes, uniformBlocks, drawCalls int) {
+	return 1024, 2, 3
+}
+
+func TestFrameStatsReporter(t *testing.T) {
+	c, _ := NewContextWithRenderer(&reportingRenderer{}, AntiAlias)
+	defer c.Delete()
+
+	c.BeginFrame(32, 32, 1.0)
+	for i := 0; i < 4; i++ {
+		c.BeginPath()
+		c.Rect(float32(i*8), 0, 4, 4)
+		c.Fill()
+	}
+	// The renderer reports nothing of the current frame until EndFrame().
+	if stats := c.FrameStats(); stats.DrawCalls != 8 || stats.VertexBytes != 0 || stats.UniformBlocks != 0 {
+		t.Errorf("stats should be counted by the context before EndFrame(), but %+v", stats)
+	}
+	c.EndFrame()
+
+	// The draw calls batched by the renderer are reported instead of the estimation.
+	stats := c.FrameStats()
+	if stats.DrawCalls != 3 || stats.VertexBytes != 1024 || stats.UniformBlocks != 2 {
+		t.Errorf("stats should be reported by the renderer, but %+v", stats)
+	}
+}
@@ -4,21 +4,62 @@ import (
 	"github.com/shibukawa/nanovgo/fontstashmini"
 )
 
-type nvgParams interface {
-	edgeAntiAlias() bool
-	renderCreate() error
-	renderCreateTexture(texType nvgTextureType, w, h int, flags ImageFlags, data []byte) int
-	renderDeleteTexture(image int) error
-	renderUpdateTexture(image, x, y, w, h int, data []byte) error
-	renderGetTextureSize(image int) (int, int, error)
-	renderViewport(width, height int)
-	renderCancel()
-	renderFlush()
-	renderFill(paint *Paint, scissor *nvgScissor, fringe float32, bounds [4]float32, paths []nvgPath)
-	renderStroke(paint *Paint, scissor *nvgScissor, fringe float32, strokeWidth float32, paths []nvgPath)
-	renderTriangles(paint *Paint, scissor *nvgScissor, vertexes []nvgVertex)
-	renderTriangleStrip(paint *Paint, scissor *nvgScissor, vertexes []nvgVertex)
-	renderDelete()
+// Renderer is the interface of rendering backends. NewContext() uses OpenGL backend and NewSoftwareContext() uses
+// software rasterizer. Other backends can be plugged in by NewContextWithRenderer().
+//
+// Vertexes are in the window coordinates passed to RenderViewport(). Slices passed to the render functions are
+// reused by Context after the call returns, so backends should copy them if they need them until RenderFlush().
+type Renderer interface {
+	// EdgeAntiAlias returns true if Context should generate anti-aliasing fringes.
+	EdgeAntiAlias() bool
+	// RenderCreate is called once when Context is created.
+	RenderCreate() error
+	// RenderCreateTexture creates texture and returns its image ID (0 means error).
+	RenderCreateTexture(texType TextureType, w, h int, flags ImageFlags, data []byte) int
+	// RenderDeleteTexture deletes the texture.
+	RenderDeleteTexture(image int) error
+	// RenderUpdateTexture updates the region of the texture. data is the whole image.
+	RenderUpdateTexture(image, x, y, w, h int, data []byte) error
+	// RenderGetTextureSize returns the size of the texture.
+	RenderGetTextureSize(image int) (int, int, error)
+	// RenderViewport is called from Context.BeginFrame().
+	RenderViewport(width, height int)
+	// RenderCancel is called from Context.CancelFrame().
+	RenderCancel()
+	// RenderFlush is called from Context.EndFrame().
+	RenderFlush()
+	// RenderFill fills the paths with NonZero fill rule. bounds is the bounding box of all paths (minX, minY, maxX, maxY).
+	RenderFill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath)
+	// RenderStroke draws the stroke strips of the paths.
+	RenderStroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []RenderPath)
+	// RenderTriangles draws triangles. It is used to draw text.
+	RenderTriangles(paint *Paint, scissor *Scissor, vertexes []Vertex)
+	// RenderTriangleStrip draws a triangle strip.
+	RenderTriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex)
+	// RenderDelete is called from Context.Delete().
+	RenderDelete()
+}
+
+// FillRuleRenderer is an optional interface of Renderer. If the renderer implements it,
+// Context.Fill() calls RenderFillRule() instead of RenderFill() with the fill rule set by SetFillRule().
+// Otherwise the paths are always filled with NonZero.
+type FillRuleRenderer interface {
+	// RenderFillRule fills the paths with the fill rule. The other parameters are same as RenderFill().
+	RenderFillRule(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath, fillRule FillRule)
+}
+
+// CreateFlagsReceiver is an optional interface of Renderer. If the renderer implements it,
+// NewContextWithRenderer() passes the create flags to it before RenderCreate().
+type CreateFlagsReceiver interface {
+	// RenderCreateFlags receives the flags passed to NewContextWithRenderer().
+	RenderCreateFlags(flags CreateFlags)
+}
+
+// TextureSizeLimiter is an optional interface of Renderer. If the renderer implements it,
+// Context.CreateImageRGBAE() returns ImageSizeError for the image larger than the limit.
+type TextureSizeLimiter interface {
+	// RenderMaxTextureSize returns the maximum width and height of the texture.
+	RenderMaxTextureSize() int
 }
 
 type nvgPoint struct {
@@ -29,42 +70,105 @@ type nvgPoint struct {
 	flags    nvgPointFlags
 }
 
-type nvgVertex struct {
+// Vertex is a vertex passed to Renderer. (x, y) is position and (u, v) is texture coordinate.
+// For fill and stroke vertexes, u and v are used for anti-aliasing fringe.
+type Vertex struct {
 	x, y, u, v float32
 }
 
-func (vtx *nvgVertex) set(x, y, u, v float32) {
+// Pos returns position of the vertex.
+func (vtx Vertex) Pos() (x, y float32) {
+	return vtx.x, vtx.y
+}
+
+// TexCoord returns texture coordinate of the vertex.
+func (vtx Vertex) TexCoord() (u, v float32) {
+	return vtx.u, vtx.v
+}
+
+func (vtx *Vertex) set(x, y, u, v float32) {
 	vtx.x = x
 	vtx.y = y
 	vtx.u = u
 	vtx.v = v
 }
 
-type nvgPath struct {
+// RenderPath is a tessellated path passed to Renderer.RenderFill() and Renderer.RenderStroke().
+type RenderPath struct {
 	first   int
 	count   int
 	closed  bool
+	aligned bool // a dash of the closed path, which is stroked with the stroke align as same as closed paths
 	nBevel  int
-	fills   []nvgVertex
-	strokes []nvgVertex
+	fills   []Vertex
+	strokes []Vertex
 	winding Winding
 	convex  bool
+
+	fringeReversed bool // the fringe is built on the other side, see orientFringes()
+}
+
+// Fills returns the triangle fan of the path's fill.
+func (p *RenderPath) Fills() []Vertex {
+	return p.fills
+}
+
+// Strokes returns the triangle strip of the stroke or the fill's anti-aliasing fringe.
+func (p *RenderPath) Strokes() []Vertex {
+	return p.strokes
+}
+
+// Closed returns true if the path is closed.
+func (p *RenderPath) Closed() bool {
+	return p.closed
+}
+
+// Convex returns true if the path is convex. Convex fill can be drawn without stencil buffer.
+func (p *RenderPath) Convex() bool {
+	return p.convex
+}
+
+// Winding returns winding of the path.
+func (p *RenderPath) Winding() Winding {
+	return p.winding
 }
 
-type nvgScissor struct {
+// Scissor is a transformed rectangle to clip rendering.
+type Scissor struct {
 	xform  TransformMatrix
 	extent [2]float32
+	clip   *ClipPath
+}
+
+// Xform returns the transform of the scissor rectangle. The rectangle is centered at the origin.
+func (s *Scissor) Xform() TransformMatrix {
+	return s.xform
+}
+
+// Extent returns the half size of the scissor rectangle. Negative values mean scissor is disabled.
+func (s *Scissor) Extent() [2]float32 {
+	return s.extent
+}
+
+// Clip returns the clip path set by Context.Clip(). It returns nil if the clip path is not set.
+func (s *Scissor) Clip() *ClipPath {
+	return s.clip
 }
 
 type nvgState struct {
 	fill, stroke  Paint
 	strokeWidth   float32
+	strokeAlign   StrokeAlign
 	miterLimit    float32
 	lineJoin      LineCap
 	lineCap       LineCap
+	dash          [nvgMaxLineDash]float32
+	dashCount     int
+	dashOffset    float32
+	fillRule      FillRule
 	alpha         float32
 	xform         TransformMatrix
-	scissor       nvgScissor
+	scissor       Scissor
 	fontSize      float32
 	letterSpacing float32
 	lineHeight    float32
@@ -77,9 +181,13 @@ func (s *nvgState) reset() {
 	s.fill.setPaintColor(RGBA(255, 255, 255, 255))
 	s.stroke.setPaintColor(RGBA(0, 0, 0, 255))
 	s.strokeWidth = 1.0
+	s.strokeAlign = Center
 	s.miterLimit = 10.0
 	s.lineCap = Butt
 	s.lineJoin = Miter
+	s.dashCount = 0
+	s.dashOffset = 0.0
+	s.fillRule = NonZero
 	s.alpha = 1.0
 	s.xform = IdentityMatrix()
 	s.scissor.xform = IdentityMatrix()
@@ -87,6 +195,7 @@ func (s *nvgState) reset() {
 	s.scissor.xform[3] = 0.0
 	s.scissor.extent[0] = -1.0
 	s.scissor.extent[1] = -1.0
+	s.scissor.clip = nil
 
 	s.fontSize = 16.0
 	s.letterSpacing = 0.0
@@ -101,15 +210,18 @@ func (s *nvgState) getFontScale() float32 {
 }
 
 type nvgPathCache struct {
-	points   []nvgPoint
-	paths    []nvgPath
-	vertexes []nvgVertex
-	bounds   [4]float32
+	points     []nvgPoint
+	paths      []RenderPath
+	vertexes   []Vertex
+	bounds     [4]float32
+	hasWinding bool      // PathWinding() is used in the paths.
+	fillRule   FillRule  // Fill rule of flattenPaths(), see enforcesWinding().
+	widths     []float32 // Half stroke widths of the points for the variable width stroke, or nil.
 }
 
-func (c *nvgPathCache) allocVertexes(n int) []nvgVertex {
+func (c *nvgPathCache) allocVertexes(n int) []Vertex {
 	offset := len(c.vertexes)
-	c.vertexes = append(c.vertexes, make([]nvgVertex, n)...)
+	c.vertexes = append(c.vertexes, make([]Vertex, n)...)
 	return c.vertexes[offset:]
 }
 
@@ -117,9 +229,11 @@ func (c *nvgPathCache) clearPathCache() {
 	c.points = c.points[:0]
 	c.paths = c.paths[:0]
 	c.vertexes = c.vertexes[:0]
+	c.hasWinding = false
+	c.widths = nil
 }
 
-func (c *nvgPathCache) lastPath() *nvgPath {
+func (c *nvgPathCache) lastPath() *RenderPath {
 	if len(c.paths) > 0 {
 		return &c.paths[len(c.paths)-1]
 	}
@@ -127,7 +241,7 @@ func (c *nvgPathCache) lastPath() *nvgPath {
 }
 
 func (c *nvgPathCache) addPath() {
-	c.paths = append(c.paths, nvgPath{first: len(c.points), winding: Solid})
+	c.paths = append(c.paths, RenderPath{first: len(c.points), winding: Solid})
 }
 
 func (c *nvgPathCache) lastPoint() *nvgPoint {
@@ -172,6 +286,7 @@ func (c *nvgPathCache) pathWinding(winding Winding) {
 	path := c.lastPath()
 	if path != nil {
 		path.winding = winding
+		c.hasWinding = true
 	}
 }
 
@@ -217,6 +332,13 @@ func (c *nvgPathCache) calculateJoins(w float32, lineJoin LineCap, miterLimit fl
 		p0 := &points[path.count-1]
 		p1 := &points[0]
 		nLeft := 0
+		nFlip := 0
+		lastDx := float32(0.0)
+		for j := path.count - 1; j >= 0 && lastDx == 0.0; j-- {
+			if absF(points[j].dx) > 0.000001 {
+				lastDx = points[j].dx
+			}
+		}
 		path.nBevel = 0
 		p1Index := 0
 
@@ -250,6 +372,15 @@ func (c *nvgPathCache) calculateJoins(w float32, lineJoin LineCap, miterLimit fl
 				p1.flags |= nvgPtLEFT
 			}
 
+			// Keep track of the horizontal direction changes. Self-intersecting paths like a star turn to
+			// the same direction at all corners, but change the direction more than twice.
+			if absF(p1.dx) > 0.000001 {
+				if lastDx*p1.dx < 0.0 {
+					nFlip++
+				}
+				lastDx = p1.dx
+			}
+
 			// Calculate if we should use bevel or miter for inner join.
 			limit := maxF(1.0, minF(p0.len, p1.len)*iw)
 			if dmr2*limit*limit < 1.0 {
@@ -273,15 +404,19 @@ func (c *nvgPathCache) calculateJoins(w float32, lineJoin LineCap, miterLimit fl
 				p1 = &points[p1Index]
 			}
 		}
-		path.convex = (nLeft == path.count)
+		path.convex = (nLeft == path.count && nFlip <= 2)
 	}
 }
 
-func (c *nvgPathCache) expandStroke(w float32, lineCap, lineJoin LineCap, miterLimit, fringeWidth, tessTol float32) {
+// expandStroke expands the paths into the stroke strips of the half width w. The closed paths and their dashes are
+// shifted by offset to the left, which is the inside of Solid paths, to align the stroke. If the cache has
+// the widths of the points, they are used instead of w and w should be the maximum of them.
+func (c *nvgPathCache) expandStroke(w, offset float32, lineCap, lineJoin LineCap, miterLimit, fringeWidth, tessTol float32) {
 	aa := fringeWidth
+	maxW := w + absF(offset)
 	// Calculate divisions per half circle.
-	nCap := curveDivs(w, PI, tessTol)
-	c.calculateJoins(w, lineJoin, miterLimit)
+	nCap := curveDivs(maxW, PI, tessTol)
+	c.calculateJoins(maxW, lineJoin, miterLimit)
 
 	// Calculate max vertex usage.
 	countVertex := 0
@@ -333,26 +468,31 @@ func (c *nvgPathCache) expandStroke(w float32, lineCap, lineJoin LineCap, miterL
 			dx := p1.x - p0.x
 			dy := p1.y - p0.y
 			_, dx, dy = normalize(dx, dy)
+			// Caps are centered on the shifted stroke.
+			lw, rw, _ := c.strokeWidths(path, 0, w, offset)
+			cw := (lw + rw) * 0.5
+			p := shiftPoint(p0, dx, dy, (lw-rw)*0.5)
 			switch lineCap {
 			case Butt:
-				index = buttCapStart(dst, index, p0, dx, dy, w, -aa*0.5, aa)
+				index = buttCapStart(dst, index, &p, dx, dy, cw, -aa*0.5, aa)
 			case Square:
-				index = buttCapStart(dst, index, p0, dx, dy, w, w-aa, aa)
+				index = buttCapStart(dst, index, &p, dx, dy, cw, cw-aa, aa)
 			case Round:
-				index = roundCapStart(dst, index, p0, dx, dy, w, nCap, aa)
+				index = roundCapStart(dst, index, &p, dx, dy, cw, nCap, aa)
 			}
 		}
 
 		for j := s; j < e; j++ {
+			lw, rw, cu := c.strokeWidths(path, p1Index, w, offset)
 			if p1.flags&(nvgPtBEVEL|nvgPrINNERBEVEL) != 0 {
 				if lineJoin == Round {
-					index = roundJoin(dst, index, p0, p1, w, w, 0, 1, nCap, aa)
+					index = roundJoin(dst, index, p0, p1, lw, rw, 0, 1, cu, nCap, aa)
 				} else {
-					index = bevelJoin(dst, index, p0, p1, w, w, 0, 1, aa)
+					index = bevelJoin(dst, index, p0, p1, lw, rw, 0, 1, cu, aa)
 				}
 			} else {
-				(&dst[index]).set(p1.x+p1.dmx*w, p1.y+p1.dmy*w, 0, 1)
-				(&dst[index+1]).set(p1.x-p1.dmx*w, p1.y-p1.dmy*w, 1, 1)
+				(&dst[index]).set(p1.x+p1.dmx*lw, p1.y+p1.dmy*lw, 0, 1)
+				(&dst[index+1]).set(p1.x-p1.dmx*rw, p1.y-p1.dmy*rw, 1, 1)
 				index += 2
 			}
 			p1Index++
@@ -370,13 +510,16 @@ func (c *nvgPathCache) expandStroke(w float32, lineCap, lineJoin LineCap, miterL
 			dx := p1.x - p0.x
 			dy := p1.y - p0.y
 			_, dx, dy = normalize(dx, dy)
+			lw, rw, _ := c.strokeWidths(path, p1Index, w, offset)
+			cw := (lw + rw) * 0.5
+			p := shiftPoint(p1, dx, dy, (lw-rw)*0.5)
 			switch lineCap {
 			case Butt:
-				index = buttCapEnd(dst, index, p1, dx, dy, w, -aa*0.5, aa)
+				index = buttCapEnd(dst, index, &p, dx, dy, cw, -aa*0.5, aa)
 			case Square:
-				index = buttCapEnd(dst, index, p1, dx, dy, w, w-aa, aa)
+				index = buttCapEnd(dst, index, &p, dx, dy, cw, cw-aa, aa)
 			case Round:
-				index = roundCapEnd(dst, index, p1, dx, dy, w, nCap, aa)
+				index = roundCapEnd(dst, index, &p, dx, dy, cw, nCap, aa)
 			}
 		}
 
@@ -385,10 +528,36 @@ func (c *nvgPathCache) expandStroke(w float32, lineCap, lineJoin LineCap, miterL
 	}
 }
 
+// strokeWidths returns the widths of the left and the right side of the stroke at the point of the path,
+// and u of the point in the stroke.
+func (c *nvgPathCache) strokeWidths(path *RenderPath, index int, w, offset float32) (lw, rw, cu float32) {
+	if c.widths != nil {
+		w = c.widths[path.first+index]
+	}
+	lw, rw = w, w
+	if path.closed || path.aligned {
+		lw, rw = w+offset, w-offset
+	}
+	if lw+rw <= 0.0 {
+		return lw, rw, 0.5
+	}
+	return lw, rw, lw / (lw + rw)
+}
+
 func (c *nvgPathCache) expandFill(w float32, lineJoin LineCap, miterLimit, fringeWidth float32) {
 	aa := fringeWidth
 	fringe := w > 0.0
 
+	// Reverse the sub-paths to build the fringe on the other side. The fills are turned back at the end
+	// to keep the winding count.
+	if fringe {
+		for i := range c.paths {
+			if c.paths[i].fringeReversed {
+				c.reversePath(&c.paths[i])
+			}
+		}
+	}
+
 	// Calculate max vertex usage.
 	c.calculateJoins(w, lineJoin, miterLimit)
 	countVertex := 0
@@ -480,10 +649,10 @@ func (c *nvgPathCache) expandFill(w float32, lineJoin LineCap, miterLimit, fring
 			// Looping
 			for j := 0; j < path.count; j++ {
 				if p1.flags&(nvgPtBEVEL|nvgPrINNERBEVEL) != 0 {
-					index = bevelJoin(dst, index, p0, p1, lw, rw, lu, ru, fringeWidth)
+					index = bevelJoin(dst, index, p0, p1, lw, rw, lu, ru, 0.5, fringeWidth)
 				} else {
 					(&dst[index]).set(p1.x+(p1.dmx*lw), p1.y+(p1.dmy*lw), lu, 1)
-					(&dst[index+1]).set(p1.x+(p1.dmx*lw), p1.y+(p1.dmy*lw), lu, 1)
+					(&dst[index+1]).set(p1.x-(p1.dmx*rw), p1.y-(p1.dmy*rw), ru, 1)
 					index += 2
 				}
 				p1Index++
@@ -502,6 +671,56 @@ func (c *nvgPathCache) expandFill(w float32, lineJoin LineCap, miterLimit, fring
 		} else {
 			path.strokes = path.strokes[:0]
 		}
+		if fringe && path.fringeReversed {
+			fills := path.fills
+			for i, j := 0, len(fills)-1; i < j; i, j = i+1, j-1 {
+				fills[i], fills[j] = fills[j], fills[i]
+			}
+			c.reversePath(path)
+		}
+	}
+}
+
+// orientFringes decides the side of the anti-aliasing fringe of each sub-path for expandFill(). The fringe is built
+// on the outside of Solid (counter-clockwise) paths and the inside of Hole paths, but the sub-paths which keep
+// their directions by EvenOdd fill rule can be filled on either side. The winding number of the other sub-paths
+// around the edge tells which side is filled.
+func (c *nvgPathCache) orientFringes() {
+	enforced := c.enforcesWinding()
+	for i := range c.paths {
+		path := &c.paths[i]
+		path.fringeReversed = false
+		if enforced || path.count < 3 {
+			continue
+		}
+		points := c.points[path.first : path.first+path.count]
+		x := (points[0].x + points[1].x) * 0.5
+		y := (points[0].y + points[1].y) * 0.5
+		outside := 0
+		for j := range c.paths {
+			if j != i {
+				other := &c.paths[j]
+				outside += windingNumber(c.points[other.first:other.first+other.count], x, y)
+			}
+		}
+		// windingNumber() is positive inside of the paths with positive area. The fill rule is EvenOdd here.
+		if polyArea(points, path.count) > 0.0 {
+			path.fringeReversed = (outside+1)%2 == 0 && outside%2 != 0
+		} else {
+			path.fringeReversed = (outside-1)%2 != 0 && outside%2 == 0
+		}
+	}
+}
+
+// reversePath reverses the direction of the sub-path and updates the directions of its line segments.
+func (c *nvgPathCache) reversePath(path *RenderPath) {
+	points := c.points[path.first : path.first+path.count]
+	polyReverse(points, path.count)
+	p0 := &points[path.count-1]
+	for i := range points {
+		p1 := &points[i]
+		p0.len, p0.dx, p0.dy = normalize(p1.x-p0.x, p1.y-p0.y)
+		p0 = p1
 	}
 }
 
@@ -0,0 +1,226 @@
+package svgexport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+
+	"github.com/shibukawa/nanovgo"
+)
+
+type paintType int
+
+const (
+	solidPaint paintType = iota
+	linearGradientPaint
+	radialGradientPaint
+	boxGradientPaint
+	imagePaint
+)
+
+// paintKind guesses the kind of the paint from the parameters made by nanovgo.LinearGradient(),
+// nanovgo.RadialGradient(), nanovgo.BoxGradient() and nanovgo.ImagePattern().
+func paintKind(paint *nanovgo.Paint) paintType {
+	if paint.Image() != 0 {
+		return imagePaint
+	}
+	if paint.InnerColor() == paint.OuterColor() {
+		return solidPaint
+	}
+	extent := paint.Extent()
+	if extent[0] > 1e4 {
+		// LinearGradient uses a large box
+		return linearGradientPaint
+	}
+	if extent[0] == extent[1] && paint.Radius() == extent[0] {
+		return radialGradientPaint
+	}
+	return boxGradientPaint
+}
+
+// paintAttrs returns attributes for fill or stroke. elemInv is the inverse transform of the element if it has.
+func (r *Renderer) paintAttrs(prop string, paint *nanovgo.Paint, elemInv *nanovgo.TransformMatrix) string {
+	xform := paint.Xform()
+	if elemInv != nil {
+		xform = xform.Multiply(*elemInv)
+	}
+	extent := paint.Extent()
+	switch paintKind(paint) {
+	case linearGradientPaint:
+		// Gradient runs along y axis of the paint space from extent-feather/2 to extent+feather/2.
+		feather := paint.Feather()
+		id := r.newID("grad")
+		fmt.Fprintf(&r.defs, `<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="0" y1="%s" x2="0" y2="%s" gradientTransform="%s">`,
+			id, ftoa(extent[1]-feather*0.5), ftoa(extent[1]+feather*0.5), matrix(xform))
+		writeStops(&r.defs, paint, 0.0)
+		r.defs.WriteString("</linearGradient>\n")
+		return fmt.Sprintf(`%s="url(#%s)"`, prop, id)
+	case radialGradientPaint:
+		feather := paint.Feather()
+		inner := extent[0] - feather*0.5
+		outer := extent[0] + feather*0.5
+		id := r.newID("grad")
+		fmt.Fprintf(&r.defs, `<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="0" cy="0" r="%s" gradientTransform="%s">`,
+			id, ftoa(outer), matrix(xform))
+		writeStops(&r.defs, paint, maxF(inner, 0.0)/outer)
+		r.defs.WriteString("</radialGradient>\n")
+		return fmt.Sprintf(`%s="url(#%s)"`, prop, id)
+	case imagePaint:
+		tex := r.findTexture(paint.Image())
+		if tex == nil {
+			return colorAttrs(prop, paint.InnerColor())
+		}
+		if tex.flags&nanovgo.ImageFlippy != 0 {
+			xform = nanovgo.ScaleMatrix(1.0, -1.0).Multiply(xform)
+		}
+		id := r.newID("pattern")
+		fmt.Fprintf(&r.defs, `<pattern id="%s" patternUnits="userSpaceOnUse" width="%s" height="%s" patternTransform="%s">`,
+			id, ftoa(extent[0]), ftoa(extent[1]), matrix(xform))
+		fmt.Fprintf(&r.defs, `<image width="%s" height="%s" preserveAspectRatio="none" xlink:href="data:image/png;base64,%s"/>`,
+			ftoa(extent[0]), ftoa(extent[1]), tex.encode())
+		r.defs.WriteString("</pattern>\n")
+		return fmt.Sprintf(`%s="url(#%s)" %s-opacity="%s"`, prop, id, prop, ftoa(paint.InnerColor().A))
+	}
+	return colorAttrs(prop, paint.InnerColor())
+}
+
+// writeBoxGradient approximates box gradient by the rounded rectangle of the inner color blurred by the feather
+// over the outer color, masked by the shape.
+func (r *Renderer) writeBoxGradient(paint *nanovgo.Paint, clip, shape string) {
+	extent := paint.Extent()
+	maskID := r.newID("mask")
+	fmt.Fprintf(&r.defs, `<mask id="%s" maskUnits="userSpaceOnUse" x="0" y="0" width="%d" height="%d">%s</mask>`+"\n",
+		maskID, r.width, r.height, shape)
+	// The transition of the gradient is linear and its width is feather. Use the gaussian blur with the same slope.
+	deviation := paint.Feather() / float32(math.Sqrt(2.0*math.Pi))
+	filterID := r.newID("blur")
+	fmt.Fprintf(&r.defs, `<filter id="%s" x="-50%%" y="-50%%" width="200%%" height="200%%"><feGaussianBlur stdDeviation="%s"/></filter>`+"\n",
+		filterID, ftoa(deviation))
+
+	fmt.Fprintf(&r.body, `<g mask="url(#%s)"%s>`+"\n", maskID, clip)
+	if paint.OuterColor().A > 0 {
+		fmt.Fprintf(&r.body, `<rect x="0" y="0" width="%d" height="%d" %s/>`+"\n", r.width, r.height, colorAttrs("fill", paint.OuterColor()))
+	}
+	fmt.Fprintf(&r.body, `<rect x="%s" y="%s" width="%s" height="%s" rx="%s" transform="%s" %s filter="url(#%s)"/>`+"\n",
+		ftoa(-extent[0]), ftoa(-extent[1]), ftoa(extent[0]*2), ftoa(extent[1]*2), ftoa(paint.Radius()),
+		matrix(paint.Xform()), colorAttrs("fill", paint.InnerColor()), filterID)
+	r.body.WriteString("</g>\n")
+}
+
+func writeStops(buf *bytes.Buffer, paint *nanovgo.Paint, offset float32) {
+	inner := paint.InnerColor()
+	outer := paint.OuterColor()
+	fmt.Fprintf(buf, `<stop offset="%s" stop-color="%s" stop-opacity="%s"/>`, ftoa(offset), hexColor(inner), ftoa(inner.A))
+	fmt.Fprintf(buf, `<stop offset="1" stop-color="%s" stop-opacity="%s"/>`, hexColor(outer), ftoa(outer.A))
+}
+
+func colorAttrs(prop string, c nanovgo.Color) string {
+	if c.A >= 1.0 {
+		return fmt.Sprintf(`%s="%s"`, prop, hexColor(c))
+	}
+	return fmt.Sprintf(`%s="%s" %s-opacity="%s"`, prop, hexColor(c), prop, ftoa(c.A))
+}
+
+func hexColor(c nanovgo.Color) string {
+	return fmt.Sprintf("#%02x%02x%02x", toByte(c.R), toByte(c.G), toByte(c.B))
+}
+
+func toByte(v float32) uint8 {
+	if v <= 0.0 {
+		return 0
+	}
+	if v >= 1.0 {
+		return 255
+	}
+	return uint8(v*255.0 + 0.5)
+}
+
+type texture struct {
+	id            int
+	width, height int
+	texType       nanovgo.TextureType
+	flags         nanovgo.ImageFlags
+	data          []byte
+	encoded       string
+}
+
+func (r *Renderer) findTexture(id int) *texture {
+	for _, tex := range r.textures {
+		if tex.id == id {
+			return tex
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) allocTexture() *texture {
+	var tex *texture
+	for _, t := range r.textures {
+		if t.id == 0 {
+			tex = t
+			break
+		}
+	}
+	if tex == nil {
+		tex = &texture{}
+		r.textures = append(r.textures, tex)
+	}
+	r.textureID++
+	tex.id = r.textureID
+	tex.encoded = ""
+	return tex
+}
+
+// update copies whole rows of the image like GL backend.
+func (t *texture) update(y, h int, data []byte) {
+	stride := t.width
+	if t.texType == nanovgo.TextureRGBA {
+		stride *= 4
+	}
+	start := y * stride
+	end := (y + h) * stride
+	if end > len(data) {
+		end = len(data)
+	}
+	if start < end {
+		copy(t.data[start:end], data[start:end])
+	}
+	t.encoded = ""
+}
+
+// encode returns base64 encoded PNG of the texture.
+func (t *texture) encode() string {
+	if t.encoded != "" {
+		return t.encoded
+	}
+	rect := image.Rect(0, 0, t.width, t.height)
+	var img image.Image
+	switch {
+	case t.texType != nanovgo.TextureRGBA:
+		alpha := image.NewNRGBA(rect)
+		for i, l := range t.data {
+			alpha.Pix[i*4] = 255
+			alpha.Pix[i*4+1] = 255
+			alpha.Pix[i*4+2] = 255
+			alpha.Pix[i*4+3] = l
+		}
+		img = alpha
+	case t.flags&nanovgo.ImagePreMultiplied != 0:
+		img = &image.RGBA{Pix: t.data, Stride: t.width * 4, Rect: rect}
+	default:
+		img = &image.NRGBA{Pix: t.data, Stride: t.width * 4, Rect: rect}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	t.encoded = encodeBase64(buf.Bytes())
+	return t.encoded
+}
+
+func encodeBase64(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,393 @@
+// Package svgexport provides NanoVGo backend that writes SVG documents.
+//
+// Paths and text are written as <path> and <text> elements without tessellation. Linear and radial gradients
+// become SVG gradients, image patterns become <pattern> elements with embedded PNG images, and scissors and
+// clip paths become <clipPath> elements. SVG doesn't have box gradients, so they are approximated by a blurred
+// rounded rectangle.
+package svgexport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/shibukawa/nanovgo"
+)
+
+// NewContext makes new NanoVGo context that writes SVG document. After Context.EndFrame(), the document of the frame
+// is available via Renderer.Bytes() or Renderer.WriteTo().
+func NewContext(flags nanovgo.CreateFlags) (*nanovgo.Context, *Renderer, error) {
+	r := NewRenderer()
+	ctx, err := nanovgo.NewContextWithRenderer(r, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, r, nil
+}
+
+// Renderer is nanovgo.VectorRenderer which builds SVG document.
+type Renderer struct {
+	// EmbedFonts embeds TrueType fonts used by text as @font-face rules. Otherwise text refers the font names only.
+	EmbedFonts bool
+
+	width, height int
+	textures      []*texture
+	textureID     int
+	lastID        int
+	defs          bytes.Buffer
+	body          bytes.Buffer
+	clipIDs       map[string]string
+	fonts         map[string]bool
+	document      []byte
+}
+
+// NewRenderer creates Renderer. Pass it to nanovgo.NewContextWithRenderer().
+func NewRenderer() *Renderer {
+	return &Renderer{
+		EmbedFonts: true,
+	}
+}
+
+// Bytes returns the SVG document of the last frame.
+func (r *Renderer) Bytes() []byte {
+	return r.document
+}
+
+// WriteTo writes the SVG document of the last frame.
+func (r *Renderer) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(r.document)
+	return int64(n), err
+}
+
+func (r *Renderer) newID(prefix string) string {
+	r.lastID++
+	return prefix + strconv.Itoa(r.lastID)
+}
+
+func (r *Renderer) reset() {
+	r.lastID = 0
+	r.defs.Reset()
+	r.body.Reset()
+	r.clipIDs = make(map[string]string)
+	r.fonts = make(map[string]bool)
+}
+
+// EdgeAntiAlias returns false. SVG viewers anti-alias paths by themselves.
+func (r *Renderer) EdgeAntiAlias() bool {
+	return false
+}
+
+// RenderCreate initializes the renderer.
+func (r *Renderer) RenderCreate() error {
+	r.reset()
+	return nil
+}
+
+// RenderCreateTexture keeps the image data to embed it into the document.
+func (r *Renderer) RenderCreateTexture(texType nanovgo.TextureType, w, h int, flags nanovgo.ImageFlags, data []byte) int {
+	tex := r.allocTexture()
+	tex.width = w
+	tex.height = h
+	tex.texType = texType
+	tex.flags = flags
+	bpp := 1
+	if texType == nanovgo.TextureRGBA {
+		bpp = 4
+	}
+	tex.data = make([]byte, w*h*bpp)
+	copy(tex.data, data)
+	return tex.id
+}
+
+// RenderDeleteTexture deletes the image data.
+func (r *Renderer) RenderDeleteTexture(image int) error {
+	tex := r.findTexture(image)
+	if tex == nil || tex.flags&nanovgo.ImageNoDelete != 0 {
+		return errors.New("invalid texture in svgexport.RenderDeleteTexture")
+	}
+	tex.id = 0
+	tex.data = nil
+	tex.encoded = ""
+	return nil
+}
+
+// RenderUpdateTexture updates rows of the image data.
+func (r *Renderer) RenderUpdateTexture(image, x, y, w, h int, data []byte) error {
+	tex := r.findTexture(image)
+	if tex == nil {
+		return errors.New("invalid texture in svgexport.RenderUpdateTexture")
+	}
+	tex.update(y, h, data)
+	return nil
+}
+
+// RenderGetTextureSize returns the size of the image.
+func (r *Renderer) RenderGetTextureSize(image int) (int, int, error) {
+	tex := r.findTexture(image)
+	if tex == nil {
+		return -1, -1, errors.New("invalid texture in svgexport.RenderGetTextureSize")
+	}
+	return tex.width, tex.height, nil
+}
+
+// RenderViewport starts new document.
+func (r *Renderer) RenderViewport(width, height int) {
+	r.width = width
+	r.height = height
+	r.reset()
+}
+
+// RenderCancel discards the current document.
+func (r *Renderer) RenderCancel() {
+	r.reset()
+}
+
+// RenderFlush finishes the current document.
+func (r *Renderer) RenderFlush() {
+	var doc bytes.Buffer
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&doc, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		r.width, r.height, r.width, r.height)
+	if r.defs.Len() > 0 {
+		doc.WriteString("<defs>\n")
+		doc.Write(r.defs.Bytes())
+		doc.WriteString("</defs>\n")
+	}
+	doc.Write(r.body.Bytes())
+	doc.WriteString("</svg>\n")
+	r.document = doc.Bytes()
+	r.reset()
+}
+
+// RenderFill is not used. Context calls RenderFillPath() instead.
+func (r *Renderer) RenderFill(paint *nanovgo.Paint, scissor *nanovgo.Scissor, fringe float32, bounds [4]float32, paths []nanovgo.RenderPath) {
+}
+
+// RenderStroke is not used. Context calls RenderStrokePath() instead.
+func (r *Renderer) RenderStroke(paint *nanovgo.Paint, scissor *nanovgo.Scissor, fringe float32, strokeWidth float32, paths []nanovgo.RenderPath) {
+}
+
+// RenderTriangles writes triangles as a path.
+func (r *Renderer) RenderTriangles(paint *nanovgo.Paint, scissor *nanovgo.Scissor, vertexes []nanovgo.Vertex) {
+	var d bytes.Buffer
+	for i := 0; i+2 < len(vertexes); i += 3 {
+		writeTriangle(&d, &vertexes[i], &vertexes[i+1], &vertexes[i+2])
+	}
+	r.fillShape(paint, scissor, d.String(), nanovgo.NonZero)
+}
+
+// RenderTriangleStrip writes triangle strip as a path.
+func (r *Renderer) RenderTriangleStrip(paint *nanovgo.Paint, scissor *nanovgo.Scissor, vertexes []nanovgo.Vertex) {
+	var d bytes.Buffer
+	for i := 0; i+2 < len(vertexes); i++ {
+		if i%2 == 0 {
+			writeTriangle(&d, &vertexes[i], &vertexes[i+1], &vertexes[i+2])
+		} else {
+			writeTriangle(&d, &vertexes[i+1], &vertexes[i], &vertexes[i+2])
+		}
+	}
+	r.fillShape(paint, scissor, d.String(), nanovgo.NonZero)
+}
+
+// RenderDelete releases all images.
+func (r *Renderer) RenderDelete() {
+	r.textures = nil
+	r.reset()
+}
+
+// RenderFillPath writes the path as <path> element.
+func (r *Renderer) RenderFillPath(paint *nanovgo.Paint, scissor *nanovgo.Scissor, commands []nanovgo.PathCommand, fillRule nanovgo.FillRule) {
+	r.fillShape(paint, scissor, pathData(commands), fillRule)
+}
+
+// RenderStrokePath writes the path as <path> element with stroke.
+func (r *Renderer) RenderStrokePath(paint *nanovgo.Paint, scissor *nanovgo.Scissor, commands []nanovgo.PathCommand, style *nanovgo.StrokeStyle) {
+	d := pathData(commands)
+	if d == "" {
+		return
+	}
+	strokeAttrs := fmt.Sprintf(`stroke-width="%s" stroke-linecap="%s" stroke-linejoin="%s" stroke-miterlimit="%s"`,
+		ftoa(style.Width), lineCapName(style.LineCap), lineJoinName(style.LineJoin), ftoa(maxF(style.MiterLimit, 1.0)))
+	if len(style.Dash) > 0 {
+		dash := make([]string, len(style.Dash))
+		for i, length := range style.Dash {
+			dash[i] = ftoa(length)
+		}
+		strokeAttrs += fmt.Sprintf(` stroke-dasharray="%s" stroke-dashoffset="%s"`, strings.Join(dash, " "), ftoa(style.DashOffset))
+	}
+	clip := r.clipAttr(scissor)
+	if kind := paintKind(paint); kind == boxGradientPaint {
+		shape := fmt.Sprintf(`<path d="%s" fill="none" stroke="#ffffff" %s/>`, d, strokeAttrs)
+		r.writeBoxGradient(paint, clip, shape)
+		return
+	}
+	fmt.Fprintf(&r.body, `<path d="%s" fill="none" %s %s%s/>`+"\n", d, r.paintAttrs("stroke", paint, nil), strokeAttrs, clip)
+}
+
+// RenderText writes the text as <text> element.
+func (r *Renderer) RenderText(paint *nanovgo.Paint, scissor *nanovgo.Scissor, text *nanovgo.TextRun) {
+	if r.EmbedFonts && len(text.FontData) > 0 && !r.fonts[text.FontName] {
+		r.fonts[text.FontName] = true
+		fmt.Fprintf(&r.defs, "<style type=\"text/css\"><![CDATA[@font-face { font-family: %s; src: url(data:font/ttf;base64,%s); }]]></style>\n",
+			quoteFontName(text.FontName), encodeBase64(text.FontData))
+	}
+	xs := make([]string, len(text.X))
+	for i, x := range text.X {
+		xs[i] = ftoa(x)
+	}
+	inv := text.Xform.Inverse()
+	var paintAttrs string
+	if paintKind(paint) == boxGradientPaint {
+		// Box gradient can't be applied to text, use the inner color instead.
+		paintAttrs = colorAttrs("fill", paint.InnerColor())
+	} else {
+		paintAttrs = r.paintAttrs("fill", paint, &inv)
+	}
+	fmt.Fprintf(&r.body, `<text xml:space="preserve" x="%s" y="%s" font-family="%s" font-size="%s" transform="%s" %s%s>%s</text>`+"\n",
+		strings.Join(xs, " "), ftoa(text.Y), escape(quoteFontName(text.FontName)), ftoa(text.FontSize),
+		matrix(text.Xform), paintAttrs, r.clipAttr(scissor), escape(string(text.Runes)))
+}
+
+func (r *Renderer) fillShape(paint *nanovgo.Paint, scissor *nanovgo.Scissor, d string, fillRule nanovgo.FillRule) {
+	if d == "" {
+		return
+	}
+	rule := ""
+	if fillRule == nanovgo.EvenOdd {
+		rule = ` fill-rule="evenodd"`
+	}
+	clip := r.clipAttr(scissor)
+	if paintKind(paint) == boxGradientPaint {
+		r.writeBoxGradient(paint, clip, fmt.Sprintf(`<path d="%s" fill="#ffffff"%s/>`, d, rule))
+		return
+	}
+	fmt.Fprintf(&r.body, `<path d="%s" %s%s%s/>`+"\n", d, r.paintAttrs("fill", paint, nil), rule, clip)
+}
+
+// clipAttr returns clip-path attribute for the scissor and the clip paths.
+func (r *Renderer) clipAttr(scissor *nanovgo.Scissor) string {
+	var id string
+	extent := scissor.Extent()
+	if extent[0] >= -0.5 && extent[1] >= -0.5 {
+		id = r.clipID(fmt.Sprintf(`<rect x="%s" y="%s" width="%s" height="%s" transform="%s"/>`,
+			ftoa(-extent[0]), ftoa(-extent[1]), ftoa(extent[0]*2), ftoa(extent[1]*2), matrix(scissor.Xform())), id)
+	}
+	// Each clip path is clipped by its parent.
+	var clips []*nanovgo.ClipPath
+	for clip := scissor.Clip(); clip != nil; clip = clip.Parent() {
+		clips = append(clips, clip)
+	}
+	for i := len(clips) - 1; i >= 0; i-- {
+		rule := ""
+		if clips[i].FillRule() == nanovgo.EvenOdd {
+			rule = ` clip-rule="evenodd"`
+		}
+		id = r.clipID(fmt.Sprintf(`<path d="%s"%s/>`, pathData(clips[i].Commands()), rule), id)
+	}
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf(` clip-path="url(#%s)"`, id)
+}
+
+// clipID returns the id of <clipPath> element of the shape clipped by the parent <clipPath>.
+func (r *Renderer) clipID(shape, parent string) string {
+	var attr string
+	if parent != "" {
+		attr = fmt.Sprintf(` clip-path="url(#%s)"`, parent)
+	}
+	key := shape + attr
+	id, ok := r.clipIDs[key]
+	if !ok {
+		id = r.newID("clip")
+		r.clipIDs[key] = id
+		fmt.Fprintf(&r.defs, `<clipPath id="%s" clipPathUnits="userSpaceOnUse"%s>%s</clipPath>`+"\n", id, attr, shape)
+	}
+	return id
+}
+
+func writeTriangle(d *bytes.Buffer, v0, v1, v2 *nanovgo.Vertex) {
+	x0, y0 := v0.Pos()
+	x1, y1 := v1.Pos()
+	x2, y2 := v2.Pos()
+	fmt.Fprintf(d, "M%s %sL%s %sL%s %sZ", ftoa(x0), ftoa(y0), ftoa(x1), ftoa(y1), ftoa(x2), ftoa(y2))
+}
+
+func pathData(commands []nanovgo.PathCommand) string {
+	var d bytes.Buffer
+	for i := range commands {
+		p := &commands[i].Points
+		switch commands[i].Type {
+		case nanovgo.PathMoveTo:
+			fmt.Fprintf(&d, "M%s %s", ftoa(p[0]), ftoa(p[1]))
+		case nanovgo.PathLineTo:
+			fmt.Fprintf(&d, "L%s %s", ftoa(p[0]), ftoa(p[1]))
+		case nanovgo.PathBezierTo:
+			fmt.Fprintf(&d, "C%s %s %s %s %s %s", ftoa(p[0]), ftoa(p[1]), ftoa(p[2]), ftoa(p[3]), ftoa(p[4]), ftoa(p[5]))
+		case nanovgo.PathClose:
+			d.WriteString("Z")
+		}
+	}
+	return d.String()
+}
+
+func lineCapName(lineCap nanovgo.LineCap) string {
+	switch lineCap {
+	case nanovgo.Round:
+		return "round"
+	case nanovgo.Square:
+		return "square"
+	}
+	return "butt"
+}
+
+func lineJoinName(lineJoin nanovgo.LineCap) string {
+	switch lineJoin {
+	case nanovgo.Round:
+		return "round"
+	case nanovgo.Bevel:
+		return "bevel"
+	}
+	return "miter"
+}
+
+func quoteFontName(name string) string {
+	return "'" + strings.Replace(name, "'", "", -1) + "'"
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '"':
+			buf.WriteString("&quot;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+func ftoa(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+func matrix(t nanovgo.TransformMatrix) string {
+	return fmt.Sprintf("matrix(%s %s %s %s %s %s)", ftoa(t[0]), ftoa(t[1]), ftoa(t[2]), ftoa(t[3]), ftoa(t[4]), ftoa(t[5]))
+}
+
+func maxF(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
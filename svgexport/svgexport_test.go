@@ -0,0 +1,205 @@
+package svgexport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shibukawa/nanovgo"
+)
+
+func checkWellFormed(t *testing.T, doc []byte) {
+	decoder := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("SVG document should be well-formed, but %v\n%s", err, doc)
+		}
+	}
+}
+
+func TestExportShapes(t *testing.T) {
+	ctx, r, err := NewContext(0)
+	if err != nil {
+		t.Fatalf("NewContext() should succeed, but %v", err)
+	}
+	defer ctx.Delete()
+
+	ctx.BeginFrame(200, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 80, 40)
+	ctx.SetFillPaint(nanovgo.LinearGradient(10, 10, 10, 50, nanovgo.RGBA(255, 0, 0, 255), nanovgo.RGBA(0, 0, 255, 255)))
+	ctx.Fill()
+
+	ctx.Scissor(100, 0, 50, 100)
+	ctx.BeginPath()
+	ctx.Circle(120, 50, 30)
+	ctx.SetStrokeColor(nanovgo.RGBA(0, 128, 0, 128))
+	ctx.SetStrokeWidth(4)
+	ctx.SetLineDash([]float32{6, 2})
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	doc := r.Bytes()
+	checkWellFormed(t, doc)
+	svg := string(doc)
+	for _, expected := range []string{
+		`width="200" height="100"`,
+		`<path d="M10 10L10 50L90 50L90 10Z" fill="url(#grad1)"/>`,
+		`<linearGradient id="grad1"`,
+		`stroke="#008000" stroke-opacity="0.5019608"`,
+		`stroke-width="4"`,
+		`stroke-dasharray="6 2" stroke-dashoffset="0"`,
+		`clip-path="url(#clip`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("SVG document should contain %s, but\n%s", expected, svg)
+		}
+	}
+}
+
+func TestExportHole(t *testing.T) {
+	ctx, r, _ := NewContext(0)
+	defer ctx.Delete()
+
+	ctx.BeginFrame(100, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 100, 100)
+	ctx.Rect(25, 25, 50, 50)
+	ctx.PathWinding(nanovgo.Hole)
+	ctx.SetFillColor(nanovgo.RGBA(0, 0, 0, 255))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	svg := string(r.Bytes())
+	// Solid path is counter-clockwise on screen and the hole is clockwise.
+	expected := `<path d="M0 0L0 100L100 100L100 0ZM75 25L75 75L25 75L25 25Z" fill="#000000"/>`
+	if !strings.Contains(svg, expected) {
+		t.Errorf("SVG document should contain %s, but\n%s", expected, svg)
+	}
+}
+
+func TestExportFillRule(t *testing.T) {
+	ctx, r, _ := NewContext(0)
+	defer ctx.Delete()
+
+	ctx.BeginFrame(100, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 60, 60)
+	ctx.Rect(40, 40, 60, 60)
+	ctx.SetFillColor(nanovgo.RGBA(0, 0, 0, 255))
+	ctx.SetFillRule(nanovgo.EvenOdd)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	svg := string(r.Bytes())
+	expected := `fill="#000000" fill-rule="evenodd"/>`
+	if !strings.Contains(svg, expected) {
+		t.Errorf("SVG document should contain %s, but\n%s", expected, svg)
+	}
+}
+
+func TestExportTextOnPath(t *testing.T) {
+	data, err := ioutil.ReadFile("../sample/Roboto-Regular.ttf")
+	if err != nil {
+		t.Skip("sample font is not available")
+	}
+	ctx, r, _ := NewContext(0)
+	defer ctx.Delete()
+	ctx.CreateFontFromMemory("sans", data, 0)
+
+	ctx.BeginFrame(200, 200, 1.0)
+	ctx.SetFontFace("sans")
+	ctx.SetFontSize(20)
+	ctx.SetFillColor(nanovgo.RGBA(0, 0, 0, 255))
+	ctx.BeginPath()
+	ctx.MoveTo(0, 50)
+	ctx.LineTo(50, 50)
+	ctx.LineTo(50, 100)
+	ctx.TextOnPath(0, "AB")
+	ctx.SetTextAlign(nanovgo.AlignCenter | nanovgo.AlignBaseline)
+	ctx.TextOnPath(75, "C")
+	// Glyphs out of the path are not drawn.
+	ctx.TextOnPath(95, "DEF")
+	ctx.EndFrame()
+
+	texts := regexp.MustCompile(`<text [^>]*transform="matrix\(([^)]*)\)"[^>]*>(.)</text>`).FindAllStringSubmatch(string(r.Bytes()), -1)
+	if len(texts) != 5 {
+		t.Fatalf("each glyph on the path should be drawn as a text, but %d texts", len(texts))
+	}
+	for i, expected := range []struct {
+		glyph, matrix string
+	}{
+		{"A", `^1 0 0 1 \d+(\.\d+)? 50$`},
+		{"B", `^1 0 0 1 \d+(\.\d+)? 50$`},
+		{"C", `^\S+ 1 -1 \S+ 50 75$`},
+		{"D", `^\S+ 1 -1 \S+ 50 \d+(\.\d+)?$`},
+		{"E", `^\S+ 1 -1 \S+ 50 \d+(\.\d+)?$`},
+	} {
+		if texts[i][2] != expected.glyph || !regexp.MustCompile(expected.matrix).MatchString(texts[i][1]) {
+			t.Errorf("glyph %d should be %s with matrix %s, but %s with %s", i, expected.glyph, expected.matrix, texts[i][2], texts[i][1])
+		}
+	}
+}
+
+func TestExportClip(t *testing.T) {
+	ctx, r, _ := NewContext(0)
+	defer ctx.Delete()
+
+	ctx.BeginFrame(100, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 50, 50)
+	ctx.Clip()
+	ctx.BeginPath()
+	ctx.Rect(25, 25, 50, 50)
+	ctx.Clip()
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 100, 100)
+	ctx.SetFillColor(nanovgo.RGBA(0, 0, 0, 255))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	doc := r.Bytes()
+	checkWellFormed(t, doc)
+	svg := string(doc)
+	for _, expected := range []string{
+		`<clipPath id="clip1" clipPathUnits="userSpaceOnUse"><path d="M0 0L0 50L50 50L50 0Z"/></clipPath>`,
+		`<clipPath id="clip2" clipPathUnits="userSpaceOnUse" clip-path="url(#clip1)"><path d="M25 25L25 75L75 75L75 25Z"/></clipPath>`,
+		`fill="#000000" clip-path="url(#clip2)"/>`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("SVG document should contain %s, but\n%s", expected, svg)
+		}
+	}
+}
+
+func TestExportStrokeAlign(t *testing.T) {
+	ctx, r, _ := NewContext(0)
+	defer ctx.Delete()
+
+	ctx.BeginFrame(100, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 80, 80)
+	ctx.SetStrokeColor(nanovgo.RGBA(0, 0, 255, 255))
+	ctx.SetStrokeWidth(4)
+	ctx.SetStrokeAlign(nanovgo.Inside)
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	svg := string(r.Bytes())
+	// SVG doesn't have the stroke align, so the outline of the stroke is filled.
+	if strings.Contains(svg, "stroke=") {
+		t.Errorf("aligned stroke should be written as a filled outline, but\n%s", svg)
+	}
+	expected := `<path d="M10 90L90 90L90 10L10 10ZM14 14L86 14L86 86L14 86Z" fill="#0000ff"/>`
+	if !strings.Contains(svg, expected) {
+		t.Errorf("SVG document should contain %s, but\n%s", expected, svg)
+	}
+}
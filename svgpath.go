@@ -0,0 +1,361 @@
+package nanovgo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// pathBuilder is the path API shared by Context and Path.
+type pathBuilder interface {
+	MoveTo(x, y float32)
+	LineTo(x, y float32)
+	BezierTo(c1x, c1y, c2x, c2y, x, y float32)
+	QuadTo(cx, cy, x, y float32)
+	ClosePath()
+}
+
+// SVGPathError is returned when the SVG path data is malformed. The sub-paths before the error are already added.
+type SVGPathError struct {
+	Offset  int // Byte offset of the path data where the error is found
+	Message string
+}
+
+func (e *SVGPathError) Error() string {
+	return fmt.Sprintf("nanovgo: invalid SVG path data at %d: %s", e.Offset, e.Message)
+}
+
+// AppendSVGPath parses the path data of SVG (the d attribute of the path element) and adds it to the current path.
+// All commands (M, L, H, V, C, S, Q, T, A and Z, absolute and relative) are supported. The coordinates are
+// transformed by the current transform as same as MoveTo() and others.
+//
+//	vg.BeginPath()
+//	vg.AppendSVGPath("M10 10 h 80 v 80 h -80 Z")
+//	vg.Fill()
+func (c *Context) AppendSVGPath(d string) error {
+	return parseSVGPath(d, c)
+}
+
+// AppendSVGPath parses the path data of SVG (the d attribute of the path element) and adds it to the path.
+func (p *Path) AppendSVGPath(d string) error {
+	return parseSVGPath(d, p)
+}
+
+type svgPathScanner struct {
+	data string
+	pos  int
+}
+
+func (s *svgPathScanner) skipSeparators() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n', '\f', ',':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *svgPathScanner) number() (float32, error) {
+	s.skipSeparators()
+	start := s.pos
+	i := s.pos
+	if i < len(s.data) && (s.data[i] == '+' || s.data[i] == '-') {
+		i++
+	}
+	digits := 0
+	for i < len(s.data) && s.data[i] >= '0' && s.data[i] <= '9' {
+		i++
+		digits++
+	}
+	if i < len(s.data) && s.data[i] == '.' {
+		i++
+		for i < len(s.data) && s.data[i] >= '0' && s.data[i] <= '9' {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, &SVGPathError{Offset: start, Message: "number is expected"}
+	}
+	if i < len(s.data) && (s.data[i] == 'e' || s.data[i] == 'E') {
+		j := i + 1
+		if j < len(s.data) && (s.data[j] == '+' || s.data[j] == '-') {
+			j++
+		}
+		if j < len(s.data) && s.data[j] >= '0' && s.data[j] <= '9' {
+			for j < len(s.data) && s.data[j] >= '0' && s.data[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	value, err := strconv.ParseFloat(s.data[start:i], 32)
+	if err != nil {
+		return 0, &SVGPathError{Offset: start, Message: err.Error()}
+	}
+	s.pos = i
+	return float32(value), nil
+}
+
+// flag reads the flag of the arc command. It can be written without separators like "a1 1 0 011 1".
+func (s *svgPathScanner) flag() (bool, error) {
+	s.skipSeparators()
+	if s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '0':
+			s.pos++
+			return false, nil
+		case '1':
+			s.pos++
+			return true, nil
+		}
+	}
+	return false, &SVGPathError{Offset: s.pos, Message: "flag is expected"}
+}
+
+func (s *svgPathScanner) numbers(values []float32) error {
+	for i := range values {
+		value, err := s.number()
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	return nil
+}
+
+func parseSVGPath(d string, path pathBuilder) error {
+	s := &svgPathScanner{data: d}
+	// current point, start point of the sub-path and the last control point for S and T
+	var x, y, startX, startY, ctrlX, ctrlY float32
+	var lastCommand byte
+	closed := false
+	var values [7]float32
+
+	for {
+		s.skipSeparators()
+		if s.pos >= len(s.data) {
+			return nil
+		}
+		command := s.data[s.pos]
+		if command >= '0' && command <= '9' || command == '-' || command == '+' || command == '.' {
+			// Implicit command repeats the last command. Implicit command after moveto is lineto.
+			switch lastCommand {
+			case 0, 'Z', 'z':
+				return &SVGPathError{Offset: s.pos, Message: "command is expected"}
+			case 'M':
+				command = 'L'
+			case 'm':
+				command = 'l'
+			default:
+				command = lastCommand
+			}
+		} else {
+			s.pos++
+		}
+		if lastCommand == 0 && command != 'M' && command != 'm' {
+			return &SVGPathError{Offset: s.pos - 1, Message: "path data should start with moveto"}
+		}
+		relative := command >= 'a' && command <= 'z'
+		var dx, dy float32
+		if relative {
+			dx, dy = x, y
+		}
+		if closed && command != 'M' && command != 'm' && command != 'Z' && command != 'z' {
+			// Drawing after closepath starts new sub-path at the start point of the closed one.
+			path.MoveTo(x, y)
+		}
+		closed = false
+
+		switch command {
+		case 'M', 'm':
+			if err := s.numbers(values[:2]); err != nil {
+				return err
+			}
+			x, y = values[0]+dx, values[1]+dy
+			startX, startY = x, y
+			path.MoveTo(x, y)
+		case 'L', 'l':
+			if err := s.numbers(values[:2]); err != nil {
+				return err
+			}
+			x, y = values[0]+dx, values[1]+dy
+			path.LineTo(x, y)
+		case 'H', 'h':
+			if err := s.numbers(values[:1]); err != nil {
+				return err
+			}
+			x = values[0] + dx
+			path.LineTo(x, y)
+		case 'V', 'v':
+			if err := s.numbers(values[:1]); err != nil {
+				return err
+			}
+			y = values[0] + dy
+			path.LineTo(x, y)
+		case 'C', 'c', 'S', 's':
+			var c1x, c1y float32
+			if command == 'C' || command == 'c' {
+				if err := s.numbers(values[:6]); err != nil {
+					return err
+				}
+				c1x, c1y = values[0]+dx, values[1]+dy
+				copy(values[:4], values[2:6])
+			} else {
+				if err := s.numbers(values[:4]); err != nil {
+					return err
+				}
+				c1x, c1y = x, y
+				switch lastCommand {
+				case 'C', 'c', 'S', 's':
+					c1x, c1y = 2*x-ctrlX, 2*y-ctrlY
+				}
+			}
+			ctrlX, ctrlY = values[0]+dx, values[1]+dy
+			x, y = values[2]+dx, values[3]+dy
+			path.BezierTo(c1x, c1y, ctrlX, ctrlY, x, y)
+		case 'Q', 'q', 'T', 't':
+			if command == 'Q' || command == 'q' {
+				if err := s.numbers(values[:4]); err != nil {
+					return err
+				}
+				ctrlX, ctrlY = values[0]+dx, values[1]+dy
+				copy(values[:2], values[2:4])
+			} else {
+				if err := s.numbers(values[:2]); err != nil {
+					return err
+				}
+				switch lastCommand {
+				case 'Q', 'q', 'T', 't':
+					ctrlX, ctrlY = 2*x-ctrlX, 2*y-ctrlY
+				default:
+					ctrlX, ctrlY = x, y
+				}
+			}
+			x, y = values[0]+dx, values[1]+dy
+			path.QuadTo(ctrlX, ctrlY, x, y)
+		case 'A', 'a':
+			if err := s.numbers(values[:3]); err != nil {
+				return err
+			}
+			largeArc, err := s.flag()
+			if err != nil {
+				return err
+			}
+			sweep, err := s.flag()
+			if err != nil {
+				return err
+			}
+			if err := s.numbers(values[3:5]); err != nil {
+				return err
+			}
+			x0, y0 := x, y
+			x, y = values[3]+dx, values[4]+dy
+			appendCommands(path, ellipticalArcCommands(x0, y0, values[0], values[1], values[2]*PI/180, largeArc, sweep, x, y))
+		case 'Z', 'z':
+			path.ClosePath()
+			x, y = startX, startY
+			closed = true
+		default:
+			return &SVGPathError{Offset: s.pos - 1, Message: fmt.Sprintf("unknown command '%c'", command)}
+		}
+		lastCommand = command
+	}
+}
+
+// appendCommands adds LINETO and BEZIERTO commands to the path.
+func appendCommands(path pathBuilder, commands []float32) {
+	for i := 0; i < len(commands); {
+		switch nvgCommands(commands[i]) {
+		case nvgLINETO:
+			path.LineTo(commands[i+1], commands[i+2])
+			i += 3
+		case nvgBEZIERTO:
+			path.BezierTo(commands[i+1], commands[i+2], commands[i+3], commands[i+4], commands[i+5], commands[i+6])
+			i += 7
+		default:
+			i++
+		}
+	}
+}
+
+// ellipticalArcCommands converts the elliptical arc of SVG (endpoint parameterization) into bezier segments
+// from (x0, y0) to (x, y). The rotation is specified in radians.
+// See https://www.w3.org/TR/SVG/implnote.html#ArcImplementationNotes
+func ellipticalArcCommands(x0, y0, rx, ry, rotation float32, largeArc, sweep bool, x, y float32) []float32 {
+	if x0 == x && y0 == y {
+		return nil
+	}
+	if rx == 0 || ry == 0 {
+		return []float32{float32(nvgLINETO), x, y}
+	}
+	frx := math.Abs(float64(rx))
+	fry := math.Abs(float64(ry))
+	sin, cos := math.Sincos(float64(rotation))
+
+	// Compute (x1', y1')
+	hx := float64(x0-x) / 2
+	hy := float64(y0-y) / 2
+	x1 := cos*hx + sin*hy
+	y1 := -sin*hx + cos*hy
+
+	// Scale up radii if they are too small to reach the end point.
+	lambda := x1*x1/(frx*frx) + y1*y1/(fry*fry)
+	if lambda > 1 {
+		frx *= math.Sqrt(lambda)
+		fry *= math.Sqrt(lambda)
+	}
+
+	// Compute center (cx', cy') and (cx, cy)
+	num := frx*frx*fry*fry - frx*frx*y1*y1 - fry*fry*x1*x1
+	den := frx*frx*y1*y1 + fry*fry*x1*x1
+	coef := 0.0
+	if num > 0 && den > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cx1 := coef * frx * y1 / fry
+	cy1 := -coef * fry * x1 / frx
+	cx := cos*cx1 - sin*cy1 + float64(x0+x)/2
+	cy := sin*cx1 + cos*cy1 + float64(y0+y)/2
+
+	// Compute start angle and sweep angle
+	theta := math.Atan2((y1-cy1)/fry, (x1-cx1)/frx)
+	delta := math.Atan2((-y1-cy1)/fry, (-x1-cx1)/frx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	// Split arc into max 90 degree segments.
+	nDivs := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-6))
+	if nDivs < 1 {
+		nDivs = 1
+	}
+	da := delta / float64(nDivs)
+	kappa := 4.0 / 3.0 * math.Tan(da/4)
+	point := func(ux, uy float64) (float32, float32) {
+		return float32(cx + cos*frx*ux - sin*fry*uy), float32(cy + sin*frx*ux + cos*fry*uy)
+	}
+
+	values := make([]float32, 0, nDivs*7)
+	for i := 0; i < nDivs; i++ {
+		a0 := theta + da*float64(i)
+		a1 := a0 + da
+		sin0, cos0 := math.Sincos(a0)
+		sin1, cos1 := math.Sincos(a1)
+		c1x, c1y := point(cos0-kappa*sin0, sin0+kappa*cos0)
+		c2x, c2y := point(cos1+kappa*sin1, sin1-kappa*cos1)
+		px, py := point(cos1, sin1)
+		if i == nDivs-1 {
+			px, py = x, y
+		}
+		values = append(values, float32(nvgBEZIERTO), c1x, c1y, c2x, c2y, px, py)
+	}
+	return values
+}
@@ -0,0 +1,132 @@
+package nanovgo
+
+import (
+	"image"
+	"testing"
+)
+
+type recordedCommand struct {
+	command string
+	values  []float32
+}
+
+type pathRecorder struct {
+	commands []recordedCommand
+}
+
+func (r *pathRecorder) add(command string, values ...float32) {
+	r.commands = append(r.commands, recordedCommand{command, values})
+}
+func (r *pathRecorder) MoveTo(x, y float32)         { r.add("M", x, y) }
+func (r *pathRecorder) LineTo(x, y float32)         { r.add("L", x, y) }
+func (r *pathRecorder) QuadTo(cx, cy, x, y float32) { r.add("Q", cx, cy, x, y) }
+func (r *pathRecorder) ClosePath()                  { r.add("Z") }
+func (r *pathRecorder) BezierTo(c1x, c1y, c2x, c2y, x, y float32) {
+	r.add("C", c1x, c1y, c2x, c2y, x, y)
+}
+
+func TestParseSVGPath(t *testing.T) {
+	testCases := []struct {
+		d        string
+		expected []recordedCommand
+	}{
+		{
+			d: "M10,20L30 40h5v-5H0V0z",
+			expected: []recordedCommand{
+				{"M", []float32{10, 20}}, {"L", []float32{30, 40}}, {"L", []float32{35, 40}}, {"L", []float32{35, 35}},
+				{"L", []float32{0, 35}}, {"L", []float32{0, 0}}, {"Z", nil},
+			},
+		},
+		{
+			d: "m1 1 2 2l-1.5.5e1",
+			expected: []recordedCommand{
+				{"M", []float32{1, 1}}, {"L", []float32{3, 3}}, {"L", []float32{1.5, 8}},
+			},
+		},
+		{
+			d: "M0 0C1 2 3 4 5 6s1 1 2 2",
+			expected: []recordedCommand{
+				{"M", []float32{0, 0}}, {"C", []float32{1, 2, 3, 4, 5, 6}}, {"C", []float32{7, 8, 6, 7, 7, 8}},
+			},
+		},
+		{
+			d: "M0 0Q1 1 2 0t2 0",
+			expected: []recordedCommand{
+				{"M", []float32{0, 0}}, {"Q", []float32{1, 1, 2, 0}}, {"Q", []float32{3, -1, 4, 0}},
+			},
+		},
+		{
+			d: "M1 1h2zl1 1",
+			expected: []recordedCommand{
+				{"M", []float32{1, 1}}, {"L", []float32{3, 1}}, {"Z", nil}, {"M", []float32{1, 1}}, {"L", []float32{2, 2}},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		recorder := &pathRecorder{}
+		if err := parseSVGPath(testCase.d, recorder); err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.d, err)
+			continue
+		}
+		if len(recorder.commands) != len(testCase.expected) {
+			t.Errorf("%s: expected %v, but %v", testCase.d, testCase.expected, recorder.commands)
+			continue
+		}
+		for i, command := range recorder.commands {
+			expected := testCase.expected[i]
+			if command.command != expected.command || len(command.values) != len(expected.values) {
+				t.Errorf("%s: command %d should be %v, but %v", testCase.d, i, expected, command)
+				continue
+			}
+			for j, value := range command.values {
+				if absF(value-expected.values[j]) > 1e-4 {
+					t.Errorf("%s: command %d should be %v, but %v", testCase.d, i, expected, command)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestParseSVGPathArc(t *testing.T) {
+	recorder := &pathRecorder{}
+	if err := parseSVGPath("M0 0A10 10 0 0110 0a10 10 0 1 1 0.001 0", recorder); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.commands[1].command != "C" {
+		t.Fatalf("arc should be converted into bezier, but %v", recorder.commands)
+	}
+	last := recorder.commands[len(recorder.commands)-1].values
+	if absF(last[4]-10.001) > 1e-4 || absF(last[5]) > 1e-4 {
+		t.Errorf("arc should end at the specified point, but %v", last)
+	}
+	// Positive sweep is clockwise on screen, so the small arc from (0, 0) to (10, 0) goes above the chord.
+	first := recorder.commands[1].values
+	if first[1] >= 0 || first[3] >= 0 {
+		t.Errorf("small arc with sweep flag should go through negative y, but %v", first)
+	}
+}
+
+func TestParseSVGPathError(t *testing.T) {
+	for _, d := range []string{"L1 1", "M1", "M0 0 X", "M0 0A1 1 0 2 0 1 1", "M0 0z 1 1"} {
+		err := parseSVGPath(d, &pathRecorder{})
+		if _, ok := err.(*SVGPathError); !ok {
+			t.Errorf("%q should be error, but %v", d, err)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	c, _ := NewSoftwareContext(img, 0)
+	defer c.Delete()
+	c.BeginFrame(16, 16, 1.0)
+	c.BeginPath()
+	if err := c.AppendSVGPath("M2 2h12v12h-12z"); err != nil {
+		t.Error(err)
+	}
+	c.SetFillColor(RGBA(255, 0, 0, 255))
+	c.Fill()
+	c.EndFrame()
+	if p := img.RGBAAt(8, 8); p.R != 255 {
+		t.Errorf("SVG path should be filled, but %v", p)
+	}
+}
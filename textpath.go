@@ -0,0 +1,123 @@
+package nanovgo
+
+import (
+	"github.com/shibukawa/nanovgo/fontstashmini"
+)
+
+// TextOnPath draws text string along the current path. Each glyph is placed at the distance along the path
+// and rotated to the tangent of the path there. The text starts at offset from the start of the path, and
+// the horizontal text align places the text around the offset (e.g. AlignCenter centers it at offset).
+// The vertical text align places the text against the path as the baseline.
+// Glyphs which are out of the path are not drawn. Returns the distance at the end of the text.
+func (c *Context) TextOnPath(offset float32, str string) float32 {
+	return c.TextOnPathRune(offset, []rune(str))
+}
+
+// TextOnPathRune is an alternate version of TextOnPath that accepts rune slice.
+func (c *Context) TextOnPathRune(offset float32, runes []rune) float32 {
+	state := c.getState()
+	scale := state.getFontScale() * c.devicePxRatio
+	invScale := 1.0 / scale
+	if state.fontID == fontstashmini.INVALID {
+		return 0
+	}
+	measure := c.MeasurePath()
+
+	c.fs.SetSize(state.fontSize * scale)
+	c.fs.SetSpacing(state.letterSpacing * scale)
+	c.fs.SetBlur(state.fontBlur * scale)
+	c.fs.SetAlign(fontstashmini.FONSAlign(state.textAlign))
+	c.fs.SetFont(state.fontID)
+	fontName := c.fs.GetFontName()
+
+	vertexCount := maxI(2, len(runes)) * 4 // conservative estimate.
+	vertexes := c.cache.allocVertexes(vertexCount)
+
+	vectorRenderer, isVector := c.params.(VectorRenderer)
+
+	iter := c.fs.TextIterForRunes(offset*scale, 0, runes)
+	// The iterator is a pointer. Keep the copy to retry the glyph.
+	prevIter := *iter
+	index := 0
+
+	for {
+		quad, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if iter.PrevGlyph == nil || iter.PrevGlyph.Index == -1 {
+			// The glyphs so far are in the current atlas.
+			if index != 0 {
+				c.renderText(vertexes[:index])
+				index = 0
+			}
+			if !c.allocTextAtlas() {
+				break // no memory :(
+			}
+			*iter = prevIter
+			quad, _ = iter.Next() // try again
+			if iter.PrevGlyph == nil || iter.PrevGlyph.Index == -1 {
+				// still can not find glyph?
+				break
+			}
+		}
+		prevIter = *iter
+		if iter.CodePoint < 0x20 {
+			continue // skip control characters like new line
+		}
+		// The center of the glyph advance is put on the path.
+		center := (iter.X + iter.NextX) * 0.5 * invScale
+		if center < 0 || center > measure.Length() {
+			continue
+		}
+		px, py, angle := measure.PointAt(center)
+		xform := RotateMatrix(angle).Multiply(TranslateMatrix(px, py)).Multiply(state.xform)
+		x := iter.X*invScale - center
+		y := iter.Y * invScale
+		if c.recording != nil {
+			// The glyph is replayed alone. Record it at the pen position with the kerning, and keep the fraction
+			// of the position to round the glyph quad as same as here.
+			kerning := (iter.NextX - iter.X) - c.glyphAdvance(iter.CodePoint)
+			c.recording.recordGlyph(c, TranslateMatrix(-center, 0).Multiply(xform), (iter.X+kerning)*invScale, iter.CodePoint, fontName)
+		}
+		if isVector {
+			c.renderVectorText(vectorRenderer, &TextRun{
+				Runes:    []rune{iter.CodePoint},
+				X:        []float32{x},
+				Y:        y,
+				Xform:    xform,
+				FontName: fontName,
+				FontSize: state.fontSize,
+				FontData: c.fs.GetFontData(),
+			})
+			continue
+		}
+		// Transform corners.
+		x0 := quad.X0*invScale - center
+		x1 := quad.X1*invScale - center
+		c0, c1 := xform.TransformPoint(x0, quad.Y0*invScale)
+		c2, c3 := xform.TransformPoint(x1, quad.Y0*invScale)
+		c4, c5 := xform.TransformPoint(x1, quad.Y1*invScale)
+		c6, c7 := xform.TransformPoint(x0, quad.Y1*invScale)
+		// Create triangles
+		if index+4 <= vertexCount {
+			(&vertexes[index]).set(c2, c3, quad.S1, quad.T0)
+			(&vertexes[index+1]).set(c0, c1, quad.S0, quad.T0)
+			(&vertexes[index+2]).set(c4, c5, quad.S1, quad.T1)
+			(&vertexes[index+3]).set(c6, c7, quad.S0, quad.T1)
+			index += 4
+		}
+	}
+	c.flushTextTexture()
+	if !isVector {
+		c.renderText(vertexes[:index])
+	}
+	return iter.NextX * invScale
+}
+
+// glyphAdvance returns the advance of the glyph without the kerning and the letter spacing in the font scale.
+func (c *Context) glyphAdvance(r rune) float32 {
+	iter := c.fs.TextIterForRunes(0, 0, []rune{r})
+	iter.Next()
+	return iter.NextX - iter.X
+}
@@ -0,0 +1,132 @@
+package nanovgo
+
+import (
+	"image"
+	"io/ioutil"
+	"testing"
+)
+
+func newTextTestContext(t *testing.T, img *image.RGBA) *Context {
+	data, err := ioutil.ReadFile("sample/Roboto-Regular.ttf")
+	if err != nil {
+		t.Skip("sample font is not available")
+	}
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	c.CreateFontFromMemory("sans", data, 0)
+	return c
+}
+
+// textCalls returns the triangle strips of the text drawn after the calls.
+func textCalls(c *Context, calls int) []softCall {
+	var strips []softCall
+	for _, call := range c.params.(*softParams).context.calls[calls:] {
+		if call.callType == glnvgTRIANGLESTRIP {
+			strips = append(strips, call)
+		}
+	}
+	return strips
+}
+
+func TestTextOnPathRune(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	c := newTextTestContext(t, img)
+	defer c.Delete()
+	softContext := c.params.(*softParams).context
+
+	c.BeginFrame(200, 100, 1.0)
+	c.SetFontFace("sans")
+	c.SetFontSize(24)
+	c.Text(20, 30, "Hello")
+	text := textCalls(c, 0)
+	calls := len(softContext.calls)
+
+	// Glyphs along the straight path are same as Text() at the start of the path.
+	c.BeginPath()
+	c.MoveTo(20, 30)
+	c.LineTo(180, 30)
+	end := c.TextOnPathRune(0, []rune("Hello"))
+	straight := textCalls(c, calls)
+	if len(straight) != 1 || len(straight[0].triangles) != 20 {
+		t.Fatalf("5 glyph quads should be drawn, but %v", straight)
+	}
+	for i, v := range straight[0].triangles {
+		x0, y0 := v.Pos()
+		x1, y1 := text[0].triangles[i].Pos()
+		if absF(x0-x1) > 0.01 || absF(y0-y1) > 0.01 || v.u != text[0].triangles[i].u || v.v != text[0].triangles[i].v {
+			t.Errorf("vertex %d should be same as Text(), but %v and %v", i, v, text[0].triangles[i])
+		}
+	}
+	if advance, _ := c.TextBounds(0, 0, "Hello"); absF(end-advance) > 0.01 {
+		t.Errorf("TextOnPathRune() should return the end of the text %v, but %v", advance, end)
+	}
+
+	// Glyphs along the vertical path are rotated around the path.
+	calls = len(softContext.calls)
+	c.BeginPath()
+	c.MoveTo(100, 40)
+	c.LineTo(100, 100)
+	c.TextOnPathRune(0, []rune("Hello"))
+	vertical := textCalls(c, calls)
+	if len(vertical) != 1 || len(vertical[0].triangles) != 20 {
+		t.Fatalf("5 glyph quads should be drawn, but %v", vertical)
+	}
+	for _, v := range vertical[0].triangles {
+		if x, y := v.Pos(); x < 100-24 || x > 100+24 || y < 40 || y > 100 {
+			t.Errorf("rotated glyph should be around the path, but %v", v)
+		}
+	}
+
+	// Glyphs out of the path are not drawn.
+	calls = len(softContext.calls)
+	c.BeginPath()
+	c.MoveTo(20, 80)
+	c.LineTo(50, 80)
+	c.TextOnPathRune(0, []rune("Hello"))
+	if short := textCalls(c, calls); len(short) != 1 || len(short[0].triangles) >= 20 {
+		t.Errorf("glyphs out of the path should be skipped, but %v", short)
+	}
+	c.EndFrame()
+
+	inked := false
+	for x := 20; x < 80; x++ {
+		for y := 10; y < 35; y++ {
+			inked = inked || img.RGBAAt(x, y).A > 128
+		}
+	}
+	if !inked {
+		t.Error("text along the path should be drawn")
+	}
+}
+
+func TestTextOnPathAtlasFull(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	c := newTextTestContext(t, img)
+	defer c.Delete()
+
+	// Large glyphs can't be in the first atlas together.
+	runes := []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+	c.BeginFrame(64, 64, 1.0)
+	c.SetFontFace("sans")
+	c.SetFontSize(160)
+	c.BeginPath()
+	c.MoveTo(0, 100)
+	c.LineTo(10000, 100)
+	c.TextOnPathRune(0, runes)
+	strips := textCalls(c, 0)
+	fontImages := append([]int(nil), c.fontImages[:c.fontImageIdx+1]...)
+	c.EndFrame()
+
+	if len(fontImages) < 2 || len(strips) < 2 {
+		t.Fatalf("glyphs should be drawn with the new atlas, but %d atlases and %d calls", len(fontImages), len(strips))
+	}
+	quads := 0
+	for _, strip := range strips {
+		quads += len(strip.triangles) / 4
+	}
+	if quads != len(runes) {
+		t.Errorf("all %d glyphs should be drawn, but %d", len(runes), quads)
+	}
+	if strips[0].image != fontImages[0] || strips[len(strips)-1].image != fontImages[len(fontImages)-1] {
+		t.Errorf("glyphs before the retry should use the previous atlas, but %d and %d", strips[0].image, strips[len(strips)-1].image)
+	}
+}
@@ -78,6 +78,13 @@ func maxI(a, b int) int {
 	return b
 }
 
+func minI(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func maxFs(v float32, values ...float32) float32 {
 	max := v
 	for _, value := range values {
@@ -226,7 +233,9 @@ func chooseBevel(bevel bool, p0, p1 *nvgPoint, w float32) (x0, y0, x1, y1 float3
 	return
 }
 
-func roundJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru float32, nCap int, fringe float32) int {
+// roundJoin adds the round join of the strip whose left and right edges are at lw and rw from the path.
+// lu, ru and cu are the u of the left edge, the right edge and the path point.
+func roundJoin(dst []Vertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, cu float32, nCap int, fringe float32) int {
 	dlx0 := p0.dy
 	dly0 := -p0.dx
 	dlx1 := p1.dy
@@ -249,7 +258,7 @@ func roundJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru floa
 			s, c := sinCosF(a)
 			rx := p1.x + c*rw
 			ry := p1.y + s*rw
-			(&dst[index]).set(p1.x, p1.y, 0.5, 1)
+			(&dst[index]).set(p1.x, p1.y, cu, 1)
 			(&dst[index+1]).set(rx, ry, ru, 1)
 			index += 2
 		}
@@ -263,7 +272,7 @@ func roundJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru floa
 		if a1 < a0 {
 			a1 += PI * 2
 		}
-		(&dst[index]).set(p1.x+dlx0*rw, p1.y+dly0*rw, lu, 1)
+		(&dst[index]).set(p1.x+dlx0*lw, p1.y+dly0*lw, lu, 1)
 		(&dst[index+1]).set(rx0, ry0, ru, 1)
 		index += 2
 		n := clampI(ceilF(((a1-a0)/PI)*float32(nCap)), 2, nCap)
@@ -274,17 +283,18 @@ func roundJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru floa
 			lx := p1.x + c*lw
 			ly := p1.y + s*lw
 			(&dst[index]).set(lx, ly, lu, 1)
-			(&dst[index+1]).set(p1.x, p1.y, 0.5, 1)
+			(&dst[index+1]).set(p1.x, p1.y, cu, 1)
 			index += 2
 		}
-		(&dst[index]).set(p1.x+dlx1*rw, p1.y+dly1*rw, lu, 1)
+		(&dst[index]).set(p1.x+dlx1*lw, p1.y+dly1*lw, lu, 1)
 		(&dst[index+1]).set(rx1, ry1, ru, 1)
 		index += 2
 	}
 	return index
 }
 
-func bevelJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, fringe float32) int {
+// bevelJoin adds the bevel or miter join of the strip like roundJoin().
+func bevelJoin(dst []Vertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, cu, fringe float32) int {
 	dlx0 := p0.dy
 	dly0 := -p0.dx
 	dlx1 := p1.dy
@@ -310,13 +320,13 @@ func bevelJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, fri
 			rx0 := p1.x - p1.dmx*rw
 			ry0 := p1.y - p1.dmy*rw
 
-			(&dst[index]).set(p1.x, p1.y, 0.5, 1)
+			(&dst[index]).set(p1.x, p1.y, cu, 1)
 			(&dst[index+1]).set(p1.x-dlx0*rw, p1.y-dly0*rw, ru, 1)
 
 			(&dst[index+2]).set(rx0, ry0, ru, 1)
 			(&dst[index+3]).set(rx0, ry0, ru, 1)
 
-			(&dst[index+4]).set(p1.x, p1.y, 0.5, 1)
+			(&dst[index+4]).set(p1.x, p1.y, cu, 1)
 			(&dst[index+5]).set(p1.x-dlx1*rw, p1.y-dly1*rw, ru, 1)
 
 			index += 6
@@ -335,22 +345,22 @@ func bevelJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, fri
 			(&dst[index]).set(p1.x+dlx0*lw, p1.y+dly0*lw, lu, 1)
 			(&dst[index+1]).set(rx0, ry0, ru, 1)
 
-			(&dst[index+2]).set(p1.x+dlx1*rw, p1.y+dly1*rw, lu, 1)
+			(&dst[index+2]).set(p1.x+dlx1*lw, p1.y+dly1*lw, lu, 1)
 			(&dst[index+3]).set(rx1, ry1, ru, 1)
 
 			index += 4
 		} else {
-			lx0 := p1.x + p1.dmx*rw
-			ly0 := p1.y + p1.dmy*rw
+			lx0 := p1.x + p1.dmx*lw
+			ly0 := p1.y + p1.dmy*lw
 
 			(&dst[index]).set(p1.x+dlx0*lw, p1.y+dly0*lw, lu, 1)
-			(&dst[index+1]).set(p1.x, p1.y, 0.5, 1)
+			(&dst[index+1]).set(p1.x, p1.y, cu, 1)
 
 			(&dst[index+2]).set(lx0, ly0, lu, 1)
 			(&dst[index+3]).set(lx0, ly0, lu, 1)
 
 			(&dst[index+4]).set(p1.x+dlx1*lw, p1.y+dly1*lw, lu, 1)
-			(&dst[index+5]).set(p1.x, p1.y, 0.5, 1)
+			(&dst[index+5]).set(p1.x, p1.y, cu, 1)
 
 			index += 6
 		}
@@ -361,7 +371,15 @@ func bevelJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, fri
 	return index
 }
 
-func buttCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
+// shiftPoint returns the copy of the point moved by d to the left of the direction (dx, dy).
+func shiftPoint(p *nvgPoint, dx, dy, d float32) nvgPoint {
+	shifted := *p
+	shifted.x += dy * d
+	shifted.y -= dx * d
+	return shifted
+}
+
+func buttCapStart(dst []Vertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
 	px := p.x - dx*d
 	py := p.y - dy*d
 	dlx := dy
@@ -373,7 +391,7 @@ func buttCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa floa
 	return index + 4
 }
 
-func buttCapEnd(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
+func buttCapEnd(dst []Vertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
 	px := p.x + dx*d
 	py := p.y + dy*d
 	dlx := dy
@@ -385,7 +403,7 @@ func buttCapEnd(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa float3
 	return index + 4
 }
 
-func roundCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
+func roundCapStart(dst []Vertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
 	px := p.x
 	py := p.y
 	dlx := dy
@@ -404,7 +422,7 @@ func roundCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w float32, n
 	return index + 2
 }
 
-func roundCapEnd(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
+func roundCapEnd(dst []Vertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
 	px := p.x
 	py := p.y
 	dlx := dy
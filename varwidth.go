@@ -0,0 +1,180 @@
+package nanovgo
+
+// StrokeVariable draws the current path with the current stroke style, but the stroke width changes along the path.
+// width returns the stroke width at t, the distance along each sub-path normalized from 0 (start) to 1 (end),
+// in the current coordinate system as same as SetStrokeWidth(). The start point of closed sub-paths uses the width
+// at t=0. Dash pattern and stroke align are not used, and the anti-aliasing fringe is made for the widest part.
+//
+//	// Tapered stroke
+//	vg.StrokeVariable(func(t float32) float32 {
+//		return 8 * (1 - t)
+//	})
+func (c *Context) StrokeVariable(width func(t float32) float32) {
+	if c.recording != nil {
+		c.recording.recordStrokeWidths(c, nil, width)
+	}
+	cache := &c.cache
+	cache.clearPathCache()
+	cache.flattenCommands(c.commands, c.tessTol, c.distTol)
+	cache.calculateSegments(c.distTol, false)
+
+	widths := make([]float32, len(cache.points))
+	for i := range cache.paths {
+		path := &cache.paths[i]
+		points := cache.points[path.first : path.first+path.count]
+		segments := len(points) - 1
+		if path.closed {
+			segments++
+		}
+		var length float32
+		for j := 0; j < segments; j++ {
+			length += points[j].len
+		}
+		var d float32
+		for j := range points {
+			var t float32
+			if length > 0 {
+				t = minF(d/length, 1.0)
+			}
+			widths[path.first+j] = width(t)
+			d += points[j].len
+		}
+	}
+	c.strokeVariable(widths)
+}
+
+// StrokeVertexWidths draws the current path with the current stroke style, but with the stroke widths at the vertexes.
+// widths are the widths at the end points of MoveTo(), LineTo() and BezierTo() (including the ones made by the shapes
+// like Rect()) in the order of the path, and the width between the vertexes changes linearly along the path.
+// If widths is shorter than the vertexes, the last width (or the current stroke width for empty widths) is used
+// for the rest. The widths are in the current coordinate system. Dash pattern and stroke align are not used as same
+// as StrokeVariable().
+//
+//	vg.MoveTo(10, 10)
+//	vg.LineTo(50, 20)
+//	vg.LineTo(90, 10)
+//	vg.StrokeVertexWidths([]float32{1, 6, 1})
+func (c *Context) StrokeVertexWidths(widths []float32) {
+	if c.recording != nil {
+		c.recording.recordStrokeWidths(c, widths, nil)
+	}
+	cache := &c.cache
+	cache.clearPathCache()
+	var pointWidths []float32
+	width := c.getState().strokeWidth
+	vertex := 0
+	i := 0
+	for i < len(c.commands) {
+		command := nvgCommands(c.commands[i])
+		size := 1
+		switch command {
+		case nvgMOVETO, nvgLINETO:
+			size = 3
+		case nvgBEZIERTO:
+			size = 7
+		case nvgWINDING:
+			size = 2
+		}
+		first := len(cache.points)
+		cache.flattenCommands(c.commands[i:i+size], c.tessTol, c.distTol)
+		i += size
+		if command != nvgMOVETO && command != nvgLINETO && command != nvgBEZIERTO {
+			continue
+		}
+		if vertex < len(widths) {
+			width = widths[vertex]
+		}
+		vertex++
+		points := cache.points
+		switch {
+		case len(points) == first:
+			// The vertex is merged into the last point.
+			if first > 0 {
+				pointWidths[first-1] = width
+			}
+		case command == nvgMOVETO:
+			pointWidths = append(pointWidths, width)
+		default:
+			// Interpolate the widths of the new points by the distance from the previous vertex.
+			distances := make([]float32, len(points)-first)
+			var length float32
+			for j := first; j < len(points); j++ {
+				d, _, _ := normalize(points[j].x-points[j-1].x, points[j].y-points[j-1].y)
+				length += d
+				distances[j-first] = length
+			}
+			start := pointWidths[first-1]
+			for _, d := range distances {
+				pointWidths = append(pointWidths, start+(width-start)*d/length)
+			}
+		}
+	}
+	cache.calculateSegments(c.distTol, false)
+	c.strokeVariable(pointWidths)
+}
+
+// strokeVariable strokes the paths flattened without enforcing the winding with the widths of the points
+// in the current coordinate system.
+func (c *Context) strokeVariable(widths []float32) {
+	state := c.getState()
+	cache := &c.cache
+	// A lone point has no direction to stroke.
+	paths := cache.paths[:0]
+	for _, path := range cache.paths {
+		if path.count > 1 {
+			paths = append(paths, path)
+		}
+	}
+	cache.paths = paths
+
+	scale := state.xform.getAverageScale()
+	var maxWidth float32
+	for i := range widths {
+		widths[i] = clampF(widths[i]*scale, 0.0, 200.0)
+		maxWidth = maxF(maxWidth, widths[i])
+	}
+	if maxWidth > 0.0 && len(cache.paths) > 0 {
+		if r, ok := c.params.(VectorRenderer); ok {
+			c.strokeVariableVectorPath(r, widths, maxWidth)
+		} else {
+			strokePaint, strokeWidth := c.strokeParamsWithWidth(maxWidth)
+			// Thin strokes are widened to the fringe width and made transparent by strokeParamsWithWidth().
+			ratio := strokeWidth / maxWidth
+			var fringe float32
+			if c.edgeAntiAlias() {
+				fringe = c.fringeWidth * 0.5
+			}
+			for i := range widths {
+				widths[i] = widths[i]*ratio*0.5 + fringe
+			}
+			cache.widths = widths
+			cache.expandStroke(strokeWidth*0.5+fringe, 0.0, state.lineCap, state.lineJoin, state.miterLimit, c.fringeWidth, c.tessTol)
+			c.renderStroke(&strokePaint, strokeWidth, cache.paths)
+		}
+	}
+	// The cache keeps the paths without the winding. Flatten the path again for the next Fill() or Stroke().
+	cache.clearPathCache()
+}
+
+// strokeVariableVectorPath fills the outline of the variable width stroke because vector formats don't have it.
+func (c *Context) strokeVariableVectorPath(r VectorRenderer, widths []float32, maxWidth float32) {
+	state := c.getState()
+	cache := &c.cache
+	strokePaint := state.stroke
+
+	// Apply global alpha
+	strokePaint.innerColor.A *= state.alpha
+	strokePaint.outerColor.A *= state.alpha
+
+	for i := range widths {
+		widths[i] *= 0.5
+	}
+	cache.widths = widths
+	cache.expandStroke(maxWidth*0.5, 0.0, state.lineCap, state.lineJoin, state.miterLimit, 0.0, c.tessTol)
+	savedCommands := c.commands
+	c.commands = mergeStrokes(cache, c.distTol)
+	commands := c.vectorPath()
+	c.commands = savedCommands
+	r.RenderFillPath(&strokePaint, &state.scissor, commands, NonZero)
+	c.drawCallCount++
+}
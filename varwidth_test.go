@@ -0,0 +1,92 @@
+package nanovgo
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestStrokeVariable(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	c, _ := NewSoftwareContext(img, AntiAlias)
+	defer c.Delete()
+
+	c.BeginFrame(64, 32, 1.0)
+	c.SetStrokeColor(RGBA(0, 0, 255, 255))
+	c.BeginPath()
+	c.MoveTo(4, 16)
+	c.LineTo(36, 16)
+	c.LineTo(60, 16)
+	c.StrokeVariable(func(t float32) float32 {
+		return 14 * (1 - t)
+	})
+	c.EndFrame()
+
+	for _, testCase := range []struct {
+		x, y int
+		a    uint8
+	}{
+		{5, 10, 255}, // width 14 at the start
+		{5, 4, 0},
+		{32, 13, 255}, // width about 7 at the middle
+		{32, 10, 0},
+		{58, 14, 0}, // thin at the end
+		{58, 16, 255},
+	} {
+		if pixel := img.RGBAAt(testCase.x, testCase.y); pixel.A != testCase.a {
+			t.Errorf("alpha of pixel (%d, %d) should be %v, but %v", testCase.x, testCase.y, testCase.a, pixel)
+		}
+	}
+	if len(c.cache.paths) != 0 {
+		t.Error("the path cache should be cleared after StrokeVariable()")
+	}
+}
+
+func drawVertexWidthsSample(c *Context) {
+	c.SetStrokeColor(RGBA(0, 0, 255, 255))
+	c.SetLineJoin(Round)
+	c.BeginPath()
+	c.MoveTo(4, 4)
+	c.LineTo(28, 4)
+	c.BezierTo(40, 4, 40, 28, 28, 28)
+	c.LineTo(4, 28)
+	c.StrokeVertexWidths([]float32{2, 6, 6})
+}
+
+func TestStrokeVertexWidths(t *testing.T) {
+	direct := image.NewRGBA(image.Rect(0, 0, 40, 32))
+	c1, _ := NewSoftwareContext(direct, AntiAlias)
+	defer c1.Delete()
+	c1.BeginFrame(40, 32, 1.0)
+	c1.BeginRecording()
+	drawVertexWidthsSample(c1)
+	list := c1.EndRecording()
+	c1.EndFrame()
+
+	for _, testCase := range []struct {
+		x, y int
+		a    uint8
+	}{
+		{5, 1, 0}, // width 2 at the first vertex
+		{26, 2, 255},
+		{35, 16, 255}, // width 6 on the curve
+		{6, 30, 255},  // the last width is used for the rest
+	} {
+		if pixel := direct.RGBAAt(testCase.x, testCase.y); pixel.A != testCase.a {
+			t.Errorf("alpha of pixel (%d, %d) should be %v, but %v", testCase.x, testCase.y, testCase.a, pixel)
+		}
+	}
+
+	if list.Len() != 1 {
+		t.Fatalf("display list should have 1 call, but %d", list.Len())
+	}
+	replayed := image.NewRGBA(image.Rect(0, 0, 40, 32))
+	c2, _ := NewSoftwareContext(replayed, AntiAlias)
+	defer c2.Delete()
+	c2.BeginFrame(40, 32, 1.0)
+	list.Replay(c2)
+	c2.EndFrame()
+	if !bytes.Equal(direct.Pix, replayed.Pix) {
+		t.Error("replayed image should be same as the original drawing")
+	}
+}
@@ -0,0 +1,259 @@
+package nanovgo
+
+// PathCommandType is the type of PathCommand
+type PathCommandType int
+
+const (
+	// PathMoveTo starts new sub-path at (Points[0], Points[1])
+	PathMoveTo PathCommandType = iota
+	// PathLineTo adds line segment to (Points[0], Points[1])
+	PathLineTo
+	// PathBezierTo adds cubic bezier segment via control points (Points[0], Points[1]) and (Points[2], Points[3]) to (Points[4], Points[5])
+	PathBezierTo
+	// PathClose closes the current sub-path
+	PathClose
+)
+
+// PathCommand is one command of the path passed to VectorRenderer. Coordinates are in the window coordinates.
+type PathCommand struct {
+	Type   PathCommandType
+	Points [6]float32
+}
+
+// StrokeStyle is stroke parameters passed to VectorRenderer.RenderStrokePath(). Width is in the window coordinates.
+// It is also used by Path.StrokeOutline() in the local coordinates of the path.
+type StrokeStyle struct {
+	Width      float32
+	Align      StrokeAlign // Always Center for VectorRenderer. Aligned strokes are passed as filled outlines.
+	LineCap    LineCap
+	LineJoin   LineCap
+	MiterLimit float32
+	Dash       []float32 // Dash pattern in the window coordinates. It is nil for solid lines.
+	DashOffset float32
+}
+
+// TextRun is a text string passed to VectorRenderer.RenderText().
+// X, Y and FontSize are in the text space and Xform converts them into the window coordinates.
+type TextRun struct {
+	Runes    []rune
+	X        []float32 // The x-coordinate of each glyph origin.
+	Y        float32   // The y-coordinate of the baseline.
+	Xform    TransformMatrix
+	FontName string
+	FontSize float32
+	FontData []byte // TrueType font file data.
+}
+
+// VectorRenderer is an optional interface of Renderer for vector output backends like SVG or PDF.
+// If the renderer implements it, Context passes paths and text without tessellation and
+// RenderFill(), RenderStroke() and RenderTriangleStrip() are not called for them.
+//
+// Each sub-path's direction is already adjusted by its Winding (Solid paths are counter-clockwise on screen
+// and Hole paths are clockwise) as same as Fill(), so the paths can be filled with the passed fill rule.
+type VectorRenderer interface {
+	Renderer
+	RenderFillPath(paint *Paint, scissor *Scissor, commands []PathCommand, fillRule FillRule)
+	RenderStrokePath(paint *Paint, scissor *Scissor, commands []PathCommand, style *StrokeStyle)
+	RenderText(paint *Paint, scissor *Scissor, text *TextRun)
+}
+
+type vectorSubPath struct {
+	startX, startY float32
+	segments       []PathCommand
+	closed         bool
+	winding        Winding
+}
+
+// area returns the signed area of the sub-path with the same sign as polyArea().
+func (p *vectorSubPath) area() float32 {
+	var area float32
+	x0, y0 := p.startX, p.startY
+	for i := range p.segments {
+		s := &p.segments[i]
+		if s.Type == PathBezierTo {
+			x1, y1 := s.Points[0], s.Points[1]
+			x2, y2 := s.Points[2], s.Points[3]
+			x3, y3 := s.Points[4], s.Points[5]
+			area += 3.0 * ((y3-y0)*(x1+x2) - (x3-x0)*(y1+y2) + y1*(x0-x2) - x1*(y0-y2) + y3*(x2+x0/3.0) - x3*(y2+y0/3.0)) / 20.0
+			x0, y0 = x3, y3
+		} else {
+			x1, y1 := s.Points[0], s.Points[1]
+			area += (x0*y1 - x1*y0) * 0.5
+			x0, y0 = x1, y1
+		}
+	}
+	area += (x0*p.startY - p.startX*y0) * 0.5
+	return -area
+}
+
+func (p *vectorSubPath) reverse() {
+	segments := make([]PathCommand, len(p.segments))
+	endX, endY := p.startX, p.startY
+	for i, s := range p.segments {
+		j := len(p.segments) - 1 - i
+		segments[j].Type = s.Type
+		if s.Type == PathBezierTo {
+			segments[j].Points = [6]float32{s.Points[2], s.Points[3], s.Points[0], s.Points[1], endX, endY}
+			endX, endY = s.Points[4], s.Points[5]
+		} else {
+			segments[j].Points[0] = endX
+			segments[j].Points[1] = endY
+			endX, endY = s.Points[0], s.Points[1]
+		}
+	}
+	p.startX, p.startY = endX, endY
+	p.segments = segments
+}
+
+// vectorPath converts the current path into path commands for VectorRenderer.
+func (c *Context) vectorPath() []PathCommand {
+	var subPaths []vectorSubPath
+	var last *vectorSubPath
+	hasWinding := false
+	i := 0
+	for i < len(c.commands) {
+		switch nvgCommands(c.commands[i]) {
+		case nvgMOVETO:
+			subPaths = append(subPaths, vectorSubPath{startX: c.commands[i+1], startY: c.commands[i+2], winding: Solid})
+			last = &subPaths[len(subPaths)-1]
+			i += 3
+		case nvgLINETO:
+			if last != nil {
+				last.segments = append(last.segments, PathCommand{
+					Type:   PathLineTo,
+					Points: [6]float32{c.commands[i+1], c.commands[i+2]},
+				})
+			}
+			i += 3
+		case nvgBEZIERTO:
+			if last != nil {
+				command := PathCommand{Type: PathBezierTo}
+				copy(command.Points[:], c.commands[i+1:i+7])
+				last.segments = append(last.segments, command)
+			}
+			i += 7
+		case nvgCLOSE:
+			if last != nil {
+				last.closed = true
+			}
+			i++
+		case nvgWINDING:
+			if last != nil {
+				last.winding = Winding(c.commands[i+1])
+				hasWinding = true
+			}
+			i += 2
+		default:
+			i++
+		}
+	}
+
+	var commands []PathCommand
+	for i := range subPaths {
+		path := &subPaths[i]
+		if len(path.segments) == 0 {
+			continue
+		}
+		// Same as flattenPaths(), the path that ends at the start point is closed.
+		end := &path.segments[len(path.segments)-1]
+		endIndex := 0
+		if end.Type == PathBezierTo {
+			endIndex = 4
+		}
+		if ptEquals(end.Points[endIndex], end.Points[endIndex+1], path.startX, path.startY, c.distTol) {
+			if end.Type == PathLineTo && len(path.segments) > 1 {
+				path.segments = path.segments[:len(path.segments)-1]
+			}
+			path.closed = true
+		}
+		// Enforce winding as same as flattenPaths().
+		if hasWinding || c.renderFillRule() != EvenOdd {
+			area := path.area()
+			if path.winding == Solid && area < 0.0 {
+				path.reverse()
+			} else if path.winding == Hole && area > 0.0 {
+				path.reverse()
+			}
+		}
+		commands = append(commands, PathCommand{Type: PathMoveTo, Points: [6]float32{path.startX, path.startY}})
+		commands = append(commands, path.segments...)
+		if path.closed {
+			commands = append(commands, PathCommand{Type: PathClose})
+		}
+	}
+	return commands
+}
+
+func (c *Context) fillVectorPath(r VectorRenderer) {
+	state := c.getState()
+	fillPaint := state.fill
+
+	// Apply global alpha
+	fillPaint.innerColor.A *= state.alpha
+	fillPaint.outerColor.A *= state.alpha
+
+	r.RenderFillPath(&fillPaint, &state.scissor, c.vectorPath(), state.fillRule)
+	c.drawCallCount++
+}
+
+func (c *Context) strokeVectorPath(r VectorRenderer) {
+	state := c.getState()
+	scale := state.xform.getAverageScale()
+	strokePaint := state.stroke
+
+	// Apply global alpha
+	strokePaint.innerColor.A *= state.alpha
+	strokePaint.outerColor.A *= state.alpha
+
+	if state.strokeAlign != Center && c.hasClosedPath() {
+		// Vector formats don't have the stroke align. Fill the outline of the stroke instead.
+		savedCommands := c.commands
+		c.commands = strokeOutlineCommands(savedCommands, clampF(state.strokeWidth*scale, 0.0, 200.0), state.strokeAlign,
+			state.lineCap, state.lineJoin, state.miterLimit, state.dash[:state.dashCount], state.dashOffset, scale,
+			c.renderFillRule(), c.tessTol, c.distTol)
+		commands := c.vectorPath()
+		c.commands = savedCommands
+		r.RenderFillPath(&strokePaint, &state.scissor, commands, NonZero)
+		c.drawCallCount++
+		return
+	}
+
+	style := StrokeStyle{
+		Width:      clampF(state.strokeWidth*scale, 0.0, 200.0),
+		LineCap:    state.lineCap,
+		LineJoin:   state.lineJoin,
+		MiterLimit: state.miterLimit,
+	}
+	if state.dashCount > 0 {
+		style.Dash = make([]float32, state.dashCount)
+		for i, length := range state.dash[:state.dashCount] {
+			style.Dash[i] = length * scale
+		}
+		style.DashOffset = state.dashOffset * scale
+	}
+	r.RenderStrokePath(&strokePaint, &state.scissor, c.vectorPath(), &style)
+	c.drawCallCount++
+}
+
+// hasClosedPath returns true if the current path has closed sub-paths.
+func (c *Context) hasClosedPath() bool {
+	c.flattenPaths()
+	for i := range c.cache.paths {
+		if c.cache.paths[i].closed {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Context) renderVectorText(r VectorRenderer, text *TextRun) {
+	state := c.getState()
+	paint := state.fill
+
+	// Apply global alpha
+	paint.innerColor.A *= state.alpha
+	paint.outerColor.A *= state.alpha
+
+	r.RenderText(&paint, &state.scissor, text)
+	c.drawCallCount++
+}